@@ -0,0 +1,9 @@
+package conversion
+
+import "github.com/giantswarm/flannel-operator/flag/service/conversion/tls"
+
+type Conversion struct {
+	Enabled       string
+	ListenAddress string
+	TLS           tls.TLS
+}
@@ -0,0 +1,6 @@
+package tls
+
+type TLS struct {
+	CrtFile string
+	KeyFile string
+}
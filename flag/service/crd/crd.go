@@ -1,5 +1,8 @@
 package crd
 
+import "github.com/giantswarm/flannel-operator/flag/service/crd/shard"
+
 type CRD struct {
 	LabelSelector string
+	Shard         shard.Shard
 }
@@ -0,0 +1,7 @@
+package shard
+
+type Shard struct {
+	Count             string
+	Index             string
+	IndexFromHostname string
+}
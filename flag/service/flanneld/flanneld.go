@@ -0,0 +1,17 @@
+package flanneld
+
+import (
+	"github.com/giantswarm/flannel-operator/flag/service/flanneld/healthport"
+	"github.com/giantswarm/flannel-operator/flag/service/flanneld/probe"
+)
+
+type Flanneld struct {
+	HealthPort             healthport.HealthPort
+	IfaceRegex             string
+	IPMasq                 string
+	LivenessProbe          probe.Probe
+	PublicIP               string
+	ReadinessProbe         probe.Probe
+	SubnetLeaseRenewMargin string
+	Verbosity              string
+}
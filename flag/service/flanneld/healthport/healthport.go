@@ -0,0 +1,7 @@
+package healthport
+
+type HealthPort struct {
+	Max      string
+	Min      string
+	Reserved string
+}
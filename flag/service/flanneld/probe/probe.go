@@ -0,0 +1,9 @@
+package probe
+
+type Probe struct {
+	FailureThreshold    string
+	InitialDelaySeconds string
+	PeriodSeconds       string
+	SuccessThreshold    string
+	TimeoutSeconds      string
+}
@@ -0,0 +1,10 @@
+package leaderelection
+
+type LeaderElection struct {
+	Enabled       string
+	LeaseDuration string
+	LeaseName     string
+	Namespace     string
+	RenewDeadline string
+	RetryPeriod   string
+}
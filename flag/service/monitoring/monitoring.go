@@ -0,0 +1,5 @@
+package monitoring
+
+type Monitoring struct {
+	Enabled string
+}
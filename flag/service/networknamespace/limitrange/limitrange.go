@@ -0,0 +1,8 @@
+package limitrange
+
+type LimitRange struct {
+	DefaultCPU           string
+	DefaultMemory        string
+	DefaultRequestCPU    string
+	DefaultRequestMemory string
+}
@@ -0,0 +1,13 @@
+package networknamespace
+
+import (
+	"github.com/giantswarm/flannel-operator/flag/service/networknamespace/limitrange"
+	"github.com/giantswarm/flannel-operator/flag/service/networknamespace/networkpolicy"
+	"github.com/giantswarm/flannel-operator/flag/service/networknamespace/resourcequota"
+)
+
+type NetworkNamespace struct {
+	LimitRange    limitrange.LimitRange
+	NetworkPolicy networkpolicy.NetworkPolicy
+	ResourceQuota resourcequota.ResourceQuota
+}
@@ -0,0 +1,5 @@
+package networkpolicy
+
+type NetworkPolicy struct {
+	Enabled string
+}
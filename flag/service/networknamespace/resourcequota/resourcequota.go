@@ -0,0 +1,7 @@
+package resourcequota
+
+type ResourceQuota struct {
+	CPU    string
+	Memory string
+	Pods   string
+}
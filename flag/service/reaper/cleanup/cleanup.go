@@ -0,0 +1,6 @@
+package cleanup
+
+type Cleanup struct {
+	Enabled  string
+	GraceAge string
+}
@@ -0,0 +1,8 @@
+package reaper
+
+import "github.com/giantswarm/flannel-operator/flag/service/reaper/cleanup"
+
+type Reaper struct {
+	Cleanup  cleanup.Cleanup
+	Interval string
+}
@@ -3,12 +3,27 @@ package service
 import (
 	"github.com/giantswarm/operatorkit/flag/service/kubernetes"
 
+	"github.com/giantswarm/flannel-operator/flag/service/conversion"
 	"github.com/giantswarm/flannel-operator/flag/service/crd"
 	"github.com/giantswarm/flannel-operator/flag/service/etcd"
+	"github.com/giantswarm/flannel-operator/flag/service/flanneld"
+	"github.com/giantswarm/flannel-operator/flag/service/leaderelection"
+	"github.com/giantswarm/flannel-operator/flag/service/monitoring"
+	"github.com/giantswarm/flannel-operator/flag/service/networknamespace"
+	"github.com/giantswarm/flannel-operator/flag/service/reaper"
+	"github.com/giantswarm/flannel-operator/flag/service/workload"
 )
 
 type Service struct {
+	Conversion conversion.Conversion
 	CRD        crd.CRD
 	Etcd       etcd.Etcd
+	Flanneld   flanneld.Flanneld
 	Kubernetes kubernetes.Kubernetes
+	Monitoring monitoring.Monitoring
+
+	LeaderElection   leaderelection.LeaderElection
+	NetworkNamespace networknamespace.NetworkNamespace
+	Reaper           reaper.Reaper
+	Workload         workload.Workload
 }
@@ -0,0 +1,6 @@
+package workload
+
+type Workload struct {
+	LabelSelector string
+	MaxWait       string
+}
@@ -13,9 +13,11 @@ require (
 	github.com/giantswarm/micrologger v0.5.0
 	github.com/giantswarm/operatorkit v0.2.1
 	github.com/giantswarm/versionbundle v0.2.0
+	github.com/go-kit/kit v0.10.0
 	github.com/prometheus/client_golang v1.9.0
 	github.com/spf13/viper v1.7.1
 	k8s.io/api v0.17.2
+	k8s.io/apiextensions-apiserver v0.17.2
 	k8s.io/apimachinery v0.17.2
 	k8s.io/client-go v0.17.2
 )
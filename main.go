@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"time"
 
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/microkit/command"
@@ -101,18 +102,64 @@ func mainE(ctx context.Context) error {
 
 	daemonCommand := newCommand.DaemonCommand().CobraCommand()
 
-	daemonCommand.PersistentFlags().String(f.Service.CRD.LabelSelector, "", "Label selector for CRD informer ListOptions.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Conversion.Enabled, false, "Whether to serve the conversion webhook of the FlannelConfig CRD.")
+	daemonCommand.PersistentFlags().String(f.Service.Conversion.ListenAddress, ":8443", "Address the conversion webhook listens on.")
+	daemonCommand.PersistentFlags().String(f.Service.Conversion.TLS.CrtFile, "", "Certificate file path the conversion webhook serves with.")
+	daemonCommand.PersistentFlags().String(f.Service.Conversion.TLS.KeyFile, "", "Key file path the conversion webhook serves with.")
+
+	daemonCommand.PersistentFlags().String(f.Service.CRD.LabelSelector, "", "Label selector of the FlannelConfigs reconciled by the operator.")
+	daemonCommand.PersistentFlags().Int(f.Service.CRD.Shard.Count, 1, "Number of shards tenant clusters are distributed across by a hash of their cluster ID. 1 disables sharding.")
+	daemonCommand.PersistentFlags().Int(f.Service.CRD.Shard.Index, 0, "Shard of tenant clusters reconciled by the operator. Must be lower than the number of shards.")
+	daemonCommand.PersistentFlags().Bool(f.Service.CRD.Shard.IndexFromHostname, false, "Whether the shard is taken from the ordinal suffix of the hostname, e.g. 2 for flannel-operator-2 of a StatefulSet, instead of the configured index.")
 
 	daemonCommand.PersistentFlags().StringSlice(f.Service.Etcd.Endpoints, []string{"http://127.0.0.1:2379"}, "Endpoints used to connect to host's etcd.")
 	daemonCommand.PersistentFlags().String(f.Service.Etcd.TLS.CAFile, "", "Certificate authority file path to use to authenticate with etcd.")
 	daemonCommand.PersistentFlags().String(f.Service.Etcd.TLS.CrtFile, "", "Certificate file path to use to authenticate with etcd.")
 	daemonCommand.PersistentFlags().String(f.Service.Etcd.TLS.KeyFile, "", "Key file path to use to authenticate with etcd.")
+	daemonCommand.PersistentFlags().Int(f.Service.Flanneld.HealthPort.Max, 32767, "Last host port of the range health ports of tenant cluster networks are allocated from.")
+	daemonCommand.PersistentFlags().Int(f.Service.Flanneld.HealthPort.Min, 21000, "First host port of the range health ports of tenant cluster networks are allocated from.")
+	daemonCommand.PersistentFlags().IntSlice(f.Service.Flanneld.HealthPort.Reserved, []int{}, "Host ports used by other host network services which must never be allocated as health ports.")
+	daemonCommand.PersistentFlags().String(f.Service.Flanneld.IfaceRegex, "", "Regular expression flanneld uses to find the interface to bind to in case the configured interface cannot be found.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Flanneld.IPMasq, false, "Whether flanneld sets up IP masquerading for traffic leaving the flannel network.")
+	daemonCommand.PersistentFlags().Int(f.Service.Flanneld.LivenessProbe.FailureThreshold, 2, "Failure threshold of the liveness probes of the network containers.")
+	daemonCommand.PersistentFlags().Int(f.Service.Flanneld.LivenessProbe.InitialDelaySeconds, 10, "Initial delay in seconds of the liveness probes of the network containers.")
+	daemonCommand.PersistentFlags().Int(f.Service.Flanneld.LivenessProbe.PeriodSeconds, 10, "Period in seconds of the liveness probes of the network containers.")
+	daemonCommand.PersistentFlags().Int(f.Service.Flanneld.LivenessProbe.SuccessThreshold, 1, "Success threshold of the liveness probes of the network containers.")
+	daemonCommand.PersistentFlags().Int(f.Service.Flanneld.LivenessProbe.TimeoutSeconds, 5, "Timeout in seconds of the liveness probes of the network containers.")
+	daemonCommand.PersistentFlags().String(f.Service.Flanneld.PublicIP, "", "IP flanneld announces to its peers. Use 'host' for the IP of the node flanneld runs on.")
+	daemonCommand.PersistentFlags().Int(f.Service.Flanneld.ReadinessProbe.FailureThreshold, 3, "Failure threshold of the readiness probes of the network containers.")
+	daemonCommand.PersistentFlags().Int(f.Service.Flanneld.ReadinessProbe.InitialDelaySeconds, 5, "Initial delay in seconds of the readiness probes of the network containers.")
+	daemonCommand.PersistentFlags().Int(f.Service.Flanneld.ReadinessProbe.PeriodSeconds, 10, "Period in seconds of the readiness probes of the network containers.")
+	daemonCommand.PersistentFlags().Int(f.Service.Flanneld.ReadinessProbe.SuccessThreshold, 1, "Success threshold of the readiness probes of the network containers.")
+	daemonCommand.PersistentFlags().Int(f.Service.Flanneld.ReadinessProbe.TimeoutSeconds, 5, "Timeout in seconds of the readiness probes of the network containers.")
+	daemonCommand.PersistentFlags().Int(f.Service.Flanneld.SubnetLeaseRenewMargin, 0, "Minutes before the subnet lease expires at which flanneld renews it. Zero uses the flanneld default.")
+	daemonCommand.PersistentFlags().Int(f.Service.Flanneld.Verbosity, 0, "Log level of flanneld.")
 	daemonCommand.PersistentFlags().String(f.Service.Kubernetes.Address, "http://127.0.0.1:6443", "Address used to connect to Kubernetes. When empty in-cluster config is created.")
 	daemonCommand.PersistentFlags().Bool(f.Service.Kubernetes.InCluster, false, "Whether to use the in-cluster config to authenticate with Kubernetes.")
 	daemonCommand.PersistentFlags().String(f.Service.Kubernetes.KubeConfig, "", "KubeConfig used to connect to Kubernetes. When empty other settings are used.")
 	daemonCommand.PersistentFlags().String(f.Service.Kubernetes.TLS.CAFile, "", "Certificate authority file path to use to authenticate with Kubernetes.")
 	daemonCommand.PersistentFlags().String(f.Service.Kubernetes.TLS.CrtFile, "", "Certificate file path to use to authenticate with Kubernetes.")
 	daemonCommand.PersistentFlags().String(f.Service.Kubernetes.TLS.KeyFile, "", "Key file path to use to authenticate with Kubernetes.")
+	daemonCommand.PersistentFlags().Bool(f.Service.LeaderElection.Enabled, false, "Whether operator replicas elect a leader via a Lease. Only the leader reconciles tenant clusters.")
+	daemonCommand.PersistentFlags().Duration(f.Service.LeaderElection.LeaseDuration, 15*time.Second, "Time standby replicas wait before they take over a leader Lease which was not renewed.")
+	daemonCommand.PersistentFlags().String(f.Service.LeaderElection.LeaseName, "flannel-operator", "Name of the Lease operator replicas compete for.")
+	daemonCommand.PersistentFlags().String(f.Service.LeaderElection.Namespace, "", "Namespace of the Lease operator replicas compete for.")
+	daemonCommand.PersistentFlags().Duration(f.Service.LeaderElection.RenewDeadline, 10*time.Second, "Time the leader retries renewing its Lease before it gives up leadership.")
+	daemonCommand.PersistentFlags().Duration(f.Service.LeaderElection.RetryPeriod, 2*time.Second, "Time between two attempts to acquire or renew the leader Lease.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Monitoring.Enabled, false, "Whether the health endpoints of tenant cluster networks are exposed for Prometheus via a headless service and a ServiceMonitor.")
+	daemonCommand.PersistentFlags().String(f.Service.NetworkNamespace.LimitRange.DefaultCPU, "", "Default CPU limit of containers in tenant cluster network namespaces. Empty means no default.")
+	daemonCommand.PersistentFlags().String(f.Service.NetworkNamespace.LimitRange.DefaultMemory, "", "Default memory limit of containers in tenant cluster network namespaces. Empty means no default.")
+	daemonCommand.PersistentFlags().String(f.Service.NetworkNamespace.LimitRange.DefaultRequestCPU, "", "Default CPU request of containers in tenant cluster network namespaces. Empty means no default.")
+	daemonCommand.PersistentFlags().String(f.Service.NetworkNamespace.LimitRange.DefaultRequestMemory, "", "Default memory request of containers in tenant cluster network namespaces. Empty means no default.")
+	daemonCommand.PersistentFlags().Bool(f.Service.NetworkNamespace.NetworkPolicy.Enabled, true, "Whether a default deny network policy is managed in tenant cluster network namespaces.")
+	daemonCommand.PersistentFlags().String(f.Service.NetworkNamespace.ResourceQuota.CPU, "", "CPU limit quota of tenant cluster network namespaces. Empty means no quota.")
+	daemonCommand.PersistentFlags().String(f.Service.NetworkNamespace.ResourceQuota.Memory, "", "Memory limit quota of tenant cluster network namespaces. Empty means no quota.")
+	daemonCommand.PersistentFlags().String(f.Service.NetworkNamespace.ResourceQuota.Pods, "", "Pod quota of tenant cluster network namespaces. It must be greater than the number of nodes. Empty means no quota.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Reaper.Cleanup.Enabled, false, "Whether orphaned network namespaces, cluster role bindings and etcd network state without matching FlannelConfig are deleted. They are only reported otherwise.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Reaper.Cleanup.GraceAge, 24*time.Hour, "Minimum time network artifacts have to be orphaned before they are deleted.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Reaper.Interval, 10*time.Minute, "Time between two inventories of orphaned network artifacts.")
+	daemonCommand.PersistentFlags().String(f.Service.Workload.LabelSelector, "", "Label selector of the pods in tenant cluster namespaces the network teardown waits for. Completed and failed pods are never waited for.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Workload.MaxWait, 2*time.Hour, "Maximum time the network teardown waits for workloads, counted from the first time it was held for them. Zero means to wait forever.")
 
 	err = newCommand.CobraCommand().Execute()
 	if err != nil {
@@ -0,0 +1,184 @@
+package v1alpha2
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+)
+
+const (
+	// AnnotationConversion holds the v1alpha2 settings v1alpha1 has no fields
+	// for, so that converting a FlannelConfig to v1alpha1 and back is
+	// lossless.
+	AnnotationConversion = "flannel-operator.giantswarm.io/v1alpha2-conversion"
+)
+
+// The operator records status information of v1alpha1 FlannelConfigs in these
+// annotations. They are mapped to the status of v1alpha2 FlannelConfigs. The
+// key packages of the resource sets refer to them, so that the resource sets
+// and the conversion always agree on the annotations.
+const (
+	AnnotationDeletionPhase = "flannel-operator.giantswarm.io/deletion-phase"
+	AnnotationHealthPort    = "flannel-operator.giantswarm.io/health-port"
+	AnnotationNetworkStatus = "flannel-operator.giantswarm.io/network-status"
+)
+
+// conversionData holds the v1alpha2 settings v1alpha1 has no fields for.
+type conversionData struct {
+	BackendType string                       `json:"backendType,omitempty"`
+	Scheduling  *FlannelConfigSpecScheduling `json:"scheduling,omitempty"`
+}
+
+// ConvertFromV1alpha1 converts the given v1alpha1 FlannelConfig to v1alpha2.
+// The backend type defaults to vxlan, which is the only backend v1alpha1
+// FlannelConfigs support.
+func ConvertFromV1alpha1(in *v1alpha1.FlannelConfig) (*FlannelConfig, error) {
+	out := &FlannelConfig{}
+
+	out.APIVersion = SchemeGroupVersion.String()
+	out.Kind = kindFlannelConfig
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+
+	out.Spec = FlannelConfigSpec{
+		Backend: FlannelConfigSpecBackend{
+			Type: BackendTypeVXLAN,
+			VNI:  in.Spec.Flannel.Spec.VNI,
+		},
+		Bridge: FlannelConfigSpecBridge{
+			Interface:      in.Spec.Bridge.Spec.Interface,
+			PrivateNetwork: in.Spec.Bridge.Spec.PrivateNetwork,
+			DNSServers:     copyStrings(in.Spec.Bridge.Spec.DNS.Servers),
+			NTPServers:     copyStrings(in.Spec.Bridge.Spec.NTP.Servers),
+		},
+		Cluster: FlannelConfigSpecCluster{
+			ID:        in.Spec.Cluster.ID,
+			Customer:  in.Spec.Cluster.Customer,
+			Namespace: in.Spec.Cluster.Namespace,
+		},
+		Images: FlannelConfigSpecImages{
+			Bridge: in.Spec.Bridge.Docker.Image,
+			Health: in.Spec.Health.Docker.Image,
+		},
+		Network: FlannelConfigSpecNetwork{
+			CIDR:      in.Spec.Flannel.Spec.Network,
+			RunDir:    in.Spec.Flannel.Spec.RunDir,
+			SubnetLen: in.Spec.Flannel.Spec.SubnetLen,
+		},
+		VersionBundle: FlannelConfigSpecVersionBundle{
+			Version: in.Spec.VersionBundle.Version,
+		},
+	}
+
+	annotations := out.GetAnnotations()
+
+	if v, ok := annotations[AnnotationConversion]; ok {
+		var data conversionData
+		err := json.Unmarshal([]byte(v), &data)
+		if err != nil {
+			return nil, microerror.Maskf(invalidObjectError, "annotation %#q must be valid JSON: %s", AnnotationConversion, err)
+		}
+
+		if data.BackendType != "" {
+			out.Spec.Backend.Type = data.BackendType
+		}
+		if data.Scheduling != nil {
+			out.Spec.Scheduling = *data.Scheduling
+		}
+
+		delete(annotations, AnnotationConversion)
+		if len(annotations) == 0 {
+			out.SetAnnotations(nil)
+		}
+	}
+
+	out.Status.DeletionPhase = annotations[AnnotationDeletionPhase]
+	out.Status.NetworkStatus = annotations[AnnotationNetworkStatus]
+	if v, ok := annotations[AnnotationHealthPort]; ok {
+		// Ports which cannot be parsed are not reported. The annotation is kept
+		// as is anyway.
+		port, err := strconv.Atoi(v)
+		if err == nil {
+			out.Status.HealthPort = port
+		}
+	}
+
+	return out, nil
+}
+
+// ConvertToV1alpha1 converts the given v1alpha2 FlannelConfig to v1alpha1.
+// Settings v1alpha1 has no fields for are kept in the AnnotationConversion
+// annotation. The status is kept in the annotations the operator records it in
+// for v1alpha1 FlannelConfigs.
+func ConvertToV1alpha1(in *FlannelConfig) (*v1alpha1.FlannelConfig, error) {
+	out := &v1alpha1.FlannelConfig{}
+
+	out.APIVersion = v1alpha1.SchemeGroupVersion.String()
+	out.Kind = kindFlannelConfig
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+
+	out.Spec.Bridge.Docker.Image = in.Spec.Images.Bridge
+	out.Spec.Bridge.Spec.Interface = in.Spec.Bridge.Interface
+	out.Spec.Bridge.Spec.PrivateNetwork = in.Spec.Bridge.PrivateNetwork
+	out.Spec.Bridge.Spec.DNS.Servers = copyStrings(in.Spec.Bridge.DNSServers)
+	out.Spec.Bridge.Spec.NTP.Servers = copyStrings(in.Spec.Bridge.NTPServers)
+	out.Spec.Cluster.ID = in.Spec.Cluster.ID
+	out.Spec.Cluster.Customer = in.Spec.Cluster.Customer
+	out.Spec.Cluster.Namespace = in.Spec.Cluster.Namespace
+	out.Spec.Flannel.Spec.Network = in.Spec.Network.CIDR
+	out.Spec.Flannel.Spec.RunDir = in.Spec.Network.RunDir
+	out.Spec.Flannel.Spec.SubnetLen = in.Spec.Network.SubnetLen
+	out.Spec.Flannel.Spec.VNI = in.Spec.Backend.VNI
+	out.Spec.Health.Docker.Image = in.Spec.Images.Health
+	out.Spec.VersionBundle.Version = in.Spec.VersionBundle.Version
+
+	annotations := map[string]string{}
+	for k, v := range out.GetAnnotations() {
+		annotations[k] = v
+	}
+	delete(annotations, AnnotationConversion)
+
+	var data conversionData
+	if in.Spec.Backend.Type != "" && in.Spec.Backend.Type != BackendTypeVXLAN {
+		data.BackendType = in.Spec.Backend.Type
+	}
+	if len(in.Spec.Scheduling.NodeSelector) != 0 || len(in.Spec.Scheduling.Tolerations) != 0 {
+		data.Scheduling = in.Spec.Scheduling.DeepCopy()
+	}
+	if data.BackendType != "" || data.Scheduling != nil {
+		b, err := json.Marshal(data)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		annotations[AnnotationConversion] = string(b)
+	}
+
+	if in.Status.DeletionPhase != "" {
+		annotations[AnnotationDeletionPhase] = in.Status.DeletionPhase
+	}
+	if in.Status.HealthPort != 0 {
+		annotations[AnnotationHealthPort] = strconv.Itoa(in.Status.HealthPort)
+	}
+	if in.Status.NetworkStatus != "" {
+		annotations[AnnotationNetworkStatus] = in.Status.NetworkStatus
+	}
+
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	out.SetAnnotations(annotations)
+
+	return out, nil
+}
+
+func copyStrings(in []string) []string {
+	if in == nil {
+		return nil
+	}
+
+	out := make([]string, len(in))
+	copy(out, in)
+
+	return out
+}
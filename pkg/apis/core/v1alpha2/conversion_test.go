@@ -0,0 +1,232 @@
+package v1alpha2
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newV1alpha1CustomObject(annotations map[string]string) *v1alpha1.FlannelConfig {
+	customObject := &v1alpha1.FlannelConfig{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "core.giantswarm.io/v1alpha1",
+			Kind:       "FlannelConfig",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "al9qy",
+			Namespace:   "default",
+			Annotations: annotations,
+		},
+	}
+
+	customObject.Spec.Bridge.Docker.Image = "quay.io/giantswarm/k8s-network-bridge:v1"
+	customObject.Spec.Bridge.Spec.Interface = "bond0.3"
+	customObject.Spec.Bridge.Spec.PrivateNetwork = "10.0.4.0/24"
+	customObject.Spec.Bridge.Spec.DNS.Servers = []string{"8.8.8.8"}
+	customObject.Spec.Bridge.Spec.NTP.Servers = []string{"0.coreos.pool.ntp.org"}
+	customObject.Spec.Cluster.ID = "al9qy"
+	customObject.Spec.Cluster.Customer = "acme"
+	customObject.Spec.Cluster.Namespace = "al9qy"
+	customObject.Spec.Flannel.Spec.Network = "10.1.0.0/16"
+	customObject.Spec.Flannel.Spec.RunDir = "/run/flannel"
+	customObject.Spec.Flannel.Spec.SubnetLen = 30
+	customObject.Spec.Flannel.Spec.VNI = 26
+	customObject.Spec.Health.Docker.Image = "quay.io/giantswarm/k8s-network-health:v1"
+	customObject.Spec.VersionBundle.Version = "0.3.0"
+
+	return customObject
+}
+
+func Test_ConvertFromV1alpha1(t *testing.T) {
+	testCases := []struct {
+		Name           string
+		CustomObject   *v1alpha1.FlannelConfig
+		ExpectedSpec   func(spec *FlannelConfigSpec)
+		ExpectedStatus FlannelConfigStatus
+		ErrorMatcher   func(err error) bool
+	}{
+		{
+			Name:         "case 0: the backend type defaults to vxlan",
+			CustomObject: newV1alpha1CustomObject(nil),
+			ExpectedSpec: func(spec *FlannelConfigSpec) {},
+			ErrorMatcher: nil,
+		},
+		{
+			Name: "case 1: status annotations are mapped to the status",
+			CustomObject: newV1alpha1CustomObject(map[string]string{
+				AnnotationDeletionPhase: "bridge-removed",
+				AnnotationHealthPort:    "21397",
+				AnnotationNetworkStatus: "ready",
+			}),
+			ExpectedSpec: func(spec *FlannelConfigSpec) {},
+			ExpectedStatus: FlannelConfigStatus{
+				DeletionPhase: "bridge-removed",
+				HealthPort:    21397,
+				NetworkStatus: "ready",
+			},
+			ErrorMatcher: nil,
+		},
+		{
+			Name: "case 2: conversion data is restored",
+			CustomObject: newV1alpha1CustomObject(map[string]string{
+				AnnotationConversion: `{"backendType":"host-gw","scheduling":{"nodeSelector":{"role":"worker"}}}`,
+			}),
+			ExpectedSpec: func(spec *FlannelConfigSpec) {
+				spec.Backend.Type = "host-gw"
+				spec.Scheduling.NodeSelector = map[string]string{"role": "worker"}
+			},
+			ErrorMatcher: nil,
+		},
+		{
+			Name: "case 3: invalid conversion data is rejected",
+			CustomObject: newV1alpha1CustomObject(map[string]string{
+				AnnotationConversion: `{`,
+			}),
+			ErrorMatcher: IsInvalidObject,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			customObject, err := ConvertFromV1alpha1(tc.CustomObject)
+
+			switch {
+			case err == nil && tc.ErrorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.ErrorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.ErrorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.ErrorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if tc.ErrorMatcher != nil {
+				return
+			}
+
+			expectedSpec := FlannelConfigSpec{
+				Backend: FlannelConfigSpecBackend{
+					Type: BackendTypeVXLAN,
+					VNI:  26,
+				},
+				Bridge: FlannelConfigSpecBridge{
+					Interface:      "bond0.3",
+					PrivateNetwork: "10.0.4.0/24",
+					DNSServers:     []string{"8.8.8.8"},
+					NTPServers:     []string{"0.coreos.pool.ntp.org"},
+				},
+				Cluster: FlannelConfigSpecCluster{
+					ID:        "al9qy",
+					Customer:  "acme",
+					Namespace: "al9qy",
+				},
+				Images: FlannelConfigSpecImages{
+					Bridge: "quay.io/giantswarm/k8s-network-bridge:v1",
+					Health: "quay.io/giantswarm/k8s-network-health:v1",
+				},
+				Network: FlannelConfigSpecNetwork{
+					CIDR:      "10.1.0.0/16",
+					RunDir:    "/run/flannel",
+					SubnetLen: 30,
+				},
+				VersionBundle: FlannelConfigSpecVersionBundle{
+					Version: "0.3.0",
+				},
+			}
+			tc.ExpectedSpec(&expectedSpec)
+
+			if !reflect.DeepEqual(customObject.Spec, expectedSpec) {
+				t.Fatalf("expected spec %#v got %#v", expectedSpec, customObject.Spec)
+			}
+			if !reflect.DeepEqual(customObject.Status, tc.ExpectedStatus) {
+				t.Fatalf("expected status %#v got %#v", tc.ExpectedStatus, customObject.Status)
+			}
+			if _, ok := customObject.Annotations[AnnotationConversion]; ok {
+				t.Fatalf("expected annotation %#q to be removed", AnnotationConversion)
+			}
+			if customObject.APIVersion != SchemeGroupVersion.String() {
+				t.Fatalf("expected api version %#q got %#q", SchemeGroupVersion.String(), customObject.APIVersion)
+			}
+		})
+	}
+}
+
+func Test_Convert_RoundTrip(t *testing.T) {
+	testCases := []struct {
+		Name         string
+		CustomObject *v1alpha1.FlannelConfig
+	}{
+		{
+			Name:         "case 0: FlannelConfigs without annotations",
+			CustomObject: newV1alpha1CustomObject(nil),
+		},
+		{
+			Name: "case 1: FlannelConfigs with status annotations",
+			CustomObject: newV1alpha1CustomObject(map[string]string{
+				AnnotationDeletionPhase: "bridge-removed",
+				AnnotationHealthPort:    "21397",
+				AnnotationNetworkStatus: "ready",
+			}),
+		},
+		{
+			Name: "case 2: FlannelConfigs with conversion data",
+			CustomObject: newV1alpha1CustomObject(map[string]string{
+				AnnotationConversion: `{"backendType":"host-gw","scheduling":{"nodeSelector":{"role":"worker"},"tolerations":[{"key":"dedicated","operator":"Exists"}]}}`,
+			}),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			converted, err := ConvertFromV1alpha1(tc.CustomObject)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			customObject, err := ConvertToV1alpha1(converted)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			if !reflect.DeepEqual(customObject, tc.CustomObject) {
+				t.Fatalf("expected %#v got %#v", tc.CustomObject, customObject)
+			}
+
+			// Converting back to v1alpha2 must restore the settings v1alpha1
+			// has no fields for as well.
+			again, err := ConvertFromV1alpha1(customObject)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			if !reflect.DeepEqual(again, converted) {
+				t.Fatalf("expected %#v got %#v", converted, again)
+			}
+		})
+	}
+}
+
+func Test_ConvertToV1alpha1_Scheduling(t *testing.T) {
+	customObject, err := ConvertFromV1alpha1(newV1alpha1CustomObject(nil))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	customObject.Spec.Scheduling.Tolerations = []corev1.Toleration{
+		{Key: "dedicated", Operator: corev1.TolerationOpExists},
+	}
+
+	converted, err := ConvertToV1alpha1(customObject)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	expected := `{"scheduling":{"tolerations":[{"key":"dedicated","operator":"Exists"}]}}`
+	if converted.Annotations[AnnotationConversion] != expected {
+		t.Fatalf("expected annotation %#q got %#q", expected, converted.Annotations[AnnotationConversion])
+	}
+}
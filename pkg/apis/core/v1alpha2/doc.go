@@ -0,0 +1,12 @@
+// Package v1alpha2 contains the v1alpha2 version of the FlannelConfig API. Other
+// than v1alpha1 it separates the network, backend, bridge, image and scheduling
+// settings and reports the state of the network in a status. The v1alpha1
+// version remains the storage version. Objects are converted between both
+// versions by the conversion webhook of the operator, see ConvertFromV1alpha1
+// and ConvertToV1alpha1. The status is stored in annotations of the v1alpha1
+// object, which is why v1alpha2 has no status subresource. The status is
+// written together with the rest of the object.
+//
+// +k8s:deepcopy-gen=package,register
+// +groupName=core.giantswarm.io
+package v1alpha2
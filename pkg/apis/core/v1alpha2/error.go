@@ -0,0 +1,12 @@
+package v1alpha2
+
+import "github.com/giantswarm/microerror"
+
+var invalidObjectError = &microerror.Error{
+	Kind: "invalidObjectError",
+}
+
+// IsInvalidObject asserts invalidObjectError.
+func IsInvalidObject(err error) bool {
+	return microerror.Cause(err) == invalidObjectError
+}
@@ -0,0 +1,102 @@
+package v1alpha2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	kindFlannelConfig = "FlannelConfig"
+
+	// BackendTypeVXLAN is the flannel backend all v1alpha1 FlannelConfigs
+	// use.
+	BackendTypeVXLAN = "vxlan"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:categories=giantswarm;kvm
+type FlannelConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              FlannelConfigSpec   `json:"spec"`
+	Status            FlannelConfigStatus `json:"status,omitempty"`
+}
+
+type FlannelConfigSpec struct {
+	Backend       FlannelConfigSpecBackend       `json:"backend"`
+	Bridge        FlannelConfigSpecBridge        `json:"bridge"`
+	Cluster       FlannelConfigSpecCluster       `json:"cluster"`
+	Images        FlannelConfigSpecImages        `json:"images"`
+	Network       FlannelConfigSpecNetwork       `json:"network"`
+	Scheduling    FlannelConfigSpecScheduling    `json:"scheduling,omitempty"`
+	VersionBundle FlannelConfigSpecVersionBundle `json:"versionBundle"`
+}
+
+// FlannelConfigSpecBackend configures the flannel backend carrying the
+// traffic between the nodes of a tenant cluster.
+type FlannelConfigSpecBackend struct {
+	// Type is the flannel backend type, e.g. "vxlan".
+	Type string `json:"type"`
+	// VNI is the VXLAN network identifier of the vxlan backend.
+	VNI int `json:"vni,omitempty"`
+}
+
+// FlannelConfigSpecBridge configures the network bridge of a tenant cluster on
+// the host.
+type FlannelConfigSpecBridge struct {
+	Interface      string   `json:"interface"`
+	PrivateNetwork string   `json:"privateNetwork"`
+	DNSServers     []string `json:"dnsServers,omitempty"`
+	NTPServers     []string `json:"ntpServers,omitempty"`
+}
+
+type FlannelConfigSpecCluster struct {
+	ID        string `json:"id"`
+	Customer  string `json:"customer"`
+	Namespace string `json:"namespace"`
+}
+
+// FlannelConfigSpecImages holds the container images of the network pods.
+type FlannelConfigSpecImages struct {
+	Bridge string `json:"bridge"`
+	Health string `json:"health"`
+}
+
+// FlannelConfigSpecNetwork configures the flannel network of a tenant cluster.
+type FlannelConfigSpecNetwork struct {
+	CIDR      string `json:"cidr"`
+	RunDir    string `json:"runDir"`
+	SubnetLen int    `json:"subnetLen"`
+}
+
+// FlannelConfigSpecScheduling constrains the nodes the network pods of a
+// tenant cluster run on.
+type FlannelConfigSpecScheduling struct {
+	NodeSelector map[string]string   `json:"nodeSelector,omitempty"`
+	Tolerations  []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
+type FlannelConfigSpecVersionBundle struct {
+	Version string `json:"version"`
+}
+
+// FlannelConfigStatus reports the state of the network of a tenant cluster. It
+// is managed by the operator and stored in annotations of the v1alpha1 object.
+type FlannelConfigStatus struct {
+	// DeletionPhase is the phase of the network teardown of a deleted
+	// FlannelConfig.
+	DeletionPhase string `json:"deletionPhase,omitempty"`
+	// HealthPort is the host port the health endpoints of the network pods
+	// listen on.
+	HealthPort int `json:"healthPort,omitempty"`
+	// NetworkStatus summarizes the readiness of the network pods per node.
+	NetworkStatus string `json:"networkStatus,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type FlannelConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []FlannelConfig `json:"items"`
+}
@@ -0,0 +1,103 @@
+// Package apply manages Kubernetes objects using server side apply. All
+// objects are applied under the same field manager, which makes the API server
+// track the fields the operator owns. Drift of these fields is corrected with
+// the next apply while fields set by other managers are left alone.
+package apply
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// FieldManager is the name of the field manager owning the fields applied
+	// by the operator.
+	FieldManager = "flannel-operator"
+)
+
+type Config struct {
+	DynClient dynamic.Interface
+	Logger    micrologger.Logger
+}
+
+type Applier struct {
+	dynClient dynamic.Interface
+	logger    micrologger.Logger
+}
+
+func New(config Config) (*Applier, error) {
+	if config.DynClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.DynClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	a := &Applier{
+		dynClient: config.DynClient,
+		logger:    config.Logger,
+	}
+
+	return a, nil
+}
+
+func (a *Applier) Apply(ctx context.Context, obj runtime.Object) error {
+	m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	u := &unstructured.Unstructured{Object: m}
+
+	gvk := u.GroupVersionKind()
+	if gvk.Version == "" || gvk.Kind == "" {
+		return microerror.Maskf(invalidObjectError, "%T must have API version and kind set", obj)
+	}
+	if u.GetName() == "" {
+		return microerror.Maskf(invalidObjectError, "%T must have a name", obj)
+	}
+
+	// The typed objects always carry a status and a creation timestamp. We do
+	// not want to claim ownership of any of them.
+	unstructured.RemoveNestedField(u.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(u.Object, "status")
+
+	b, err := u.MarshalJSON()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+
+	var client dynamic.ResourceInterface
+	if u.GetNamespace() == "" {
+		client = a.dynClient.Resource(gvr)
+	} else {
+		client = a.dynClient.Resource(gvr).Namespace(u.GetNamespace())
+	}
+
+	a.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("applying %s %#q", gvk.Kind, u.GetName()))
+
+	force := true
+	o := metav1.PatchOptions{
+		FieldManager: FieldManager,
+		Force:        &force,
+	}
+
+	_, err = client.Patch(u.GetName(), types.ApplyPatchType, b, o)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	a.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("applied %s %#q", gvk.Kind, u.GetName()))
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func Test_Applier_Apply(t *testing.T) {
+	testCases := []struct {
+		Name              string
+		Obj               runtime.Object
+		ExpectedResource  schema.GroupVersionResource
+		ExpectedNamespace string
+		ErrorMatcher      func(error) bool
+	}{
+		{
+			Name: "case 0: cluster scoped objects are applied",
+			Obj: &corev1.Namespace{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "Namespace",
+					APIVersion: "v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "flannel-network-al9qy",
+				},
+			},
+			ExpectedResource: schema.GroupVersionResource{Version: "v1", Resource: "namespaces"},
+		},
+		{
+			Name: "case 1: namespaced objects are applied",
+			Obj: &appsv1.DaemonSet{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "DaemonSet",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "flannel-network",
+					Namespace: "flannel-network-al9qy",
+				},
+			},
+			ExpectedResource:  schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "daemonsets"},
+			ExpectedNamespace: "flannel-network-al9qy",
+		},
+		{
+			Name: "case 2: objects without kind are rejected",
+			Obj: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "flannel-network-al9qy",
+				},
+			},
+			ErrorMatcher: IsInvalidObject,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var patch k8stesting.PatchAction
+			dynClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+			dynClient.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				patch = action.(k8stesting.PatchAction)
+				return true, nil, nil
+			})
+
+			a, err := New(Config{DynClient: dynClient, Logger: microloggertest.New()})
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			err = a.Apply(context.Background(), tc.Obj)
+
+			switch {
+			case err == nil && tc.ErrorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.ErrorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.ErrorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.ErrorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if tc.ErrorMatcher != nil {
+				return
+			}
+
+			if patch == nil {
+				t.Fatal("expected patch action")
+			}
+			if patch.GetPatchType() != types.ApplyPatchType {
+				t.Fatalf("expected patch type %#q got %#q", types.ApplyPatchType, patch.GetPatchType())
+			}
+			if patch.GetResource() != tc.ExpectedResource {
+				t.Fatalf("expected resource %#v got %#v", tc.ExpectedResource, patch.GetResource())
+			}
+			if patch.GetNamespace() != tc.ExpectedNamespace {
+				t.Fatalf("expected namespace %#q got %#q", tc.ExpectedNamespace, patch.GetNamespace())
+			}
+
+			var m map[string]interface{}
+			err = json.Unmarshal(patch.GetPatch(), &m)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			if _, ok := m["status"]; ok {
+				t.Fatalf("expected status to be removed, got %#v", m)
+			}
+			if _, ok := m["metadata"].(map[string]interface{})["creationTimestamp"]; ok {
+				t.Fatalf("expected creation timestamp to be removed, got %#v", m)
+			}
+		})
+	}
+}
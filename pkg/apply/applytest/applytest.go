@@ -0,0 +1,37 @@
+// Package applytest provides an apply.Interface implementation for tests. The
+// fake clients of client-go do not support server side apply.
+package applytest
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type Applier struct {
+	applied []runtime.Object
+	mutex   sync.Mutex
+}
+
+func New() *Applier {
+	return &Applier{}
+}
+
+// Apply records the given object.
+func (a *Applier) Apply(ctx context.Context, obj runtime.Object) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.applied = append(a.applied, obj)
+
+	return nil
+}
+
+// Applied returns the objects applied so far.
+func (a *Applier) Applied() []runtime.Object {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return a.applied
+}
@@ -0,0 +1,21 @@
+package apply
+
+import "github.com/giantswarm/microerror"
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var invalidObjectError = &microerror.Error{
+	Kind: "invalidObjectError",
+}
+
+// IsInvalidObject asserts invalidObjectError.
+func IsInvalidObject(err error) bool {
+	return microerror.Cause(err) == invalidObjectError
+}
@@ -0,0 +1,14 @@
+package apply
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type Interface interface {
+	// Apply server side applies the given object. The object must have its
+	// API version and kind set. Objects without namespace are treated as
+	// cluster scoped.
+	Apply(ctx context.Context, obj runtime.Object) error
+}
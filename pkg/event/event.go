@@ -0,0 +1,59 @@
+// Package event provides the recorder used to emit Kubernetes events about
+// FlannelConfigs, e.g. to explain why the deletion of a tenant network is held.
+package event
+
+import (
+	"fmt"
+
+	"github.com/giantswarm/k8sclient"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/giantswarm/flannel-operator/pkg/project"
+)
+
+// Reasons of the events emitted by the operator.
+const (
+	ReasonDeletionBlocked         = "DeletionBlocked"
+	ReasonDeletionProtected       = "DeletionProtected"
+	ReasonNetworkCleanupFailed    = "NetworkCleanupFailed"
+	ReasonNetworkCleanupRetried   = "NetworkCleanupRetried"
+	ReasonNetworkCleanupSucceeded = "NetworkCleanupSucceeded"
+	ReasonReconciliationPaused    = "ReconciliationPaused"
+	ReasonReconciliationResumed   = "ReconciliationResumed"
+	ReasonUnhandledVersion        = "UnhandledVersion"
+	ReasonWorkloadWaitExpired     = "WorkloadWaitExpired"
+)
+
+type Config struct {
+	K8sClient k8sclient.Interface
+	Logger    micrologger.Logger
+}
+
+// New returns an event recorder which writes events to the Kubernetes API. The
+// scheme of the given client must know all types events are emitted about.
+func New(config Config) (record.EventRecorder, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartLogging(func(format string, args ...interface{}) {
+		config.Logger.Log("level", "debug", "message", "recorded event", "event", fmt.Sprintf(format, args...))
+	})
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{
+		Interface: config.K8sClient.K8sClient().CoreV1().Events(""),
+	})
+
+	source := corev1.EventSource{
+		Component: project.Name(),
+	}
+
+	return broadcaster.NewRecorder(config.K8sClient.Scheme(), source), nil
+}
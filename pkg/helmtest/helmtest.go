@@ -0,0 +1,73 @@
+// Package helmtest reads objects from the templates of the operator chart in
+// tests, so that tests do not have to copy them. Templates are not rendered.
+// The default resource name is substituted and all other lines holding
+// template directives are dropped, which is good enough for the static objects
+// tests are interested in.
+package helmtest
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	// DefaultName is substituted for the default resource name of the chart.
+	DefaultName = "flannel-operator"
+)
+
+// Roles returns the ClusterRoles and Roles defined in the given chart
+// template. Roles are returned as ClusterRoles, since both share the same
+// schema. Their kind tells them apart.
+func Roles(file string) ([]rbacv1.ClusterRole, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var lines []string
+	for _, l := range strings.Split(string(b), "\n") {
+		l = strings.Replace(l, `{{ include "resource.default.name" . }}`, DefaultName, -1)
+		if strings.Contains(l, "{{") {
+			continue
+		}
+		lines = append(lines, l)
+	}
+
+	var roles []rbacv1.ClusterRole
+	for _, doc := range strings.Split(strings.Join(lines, "\n"), "\n---\n") {
+		var role rbacv1.ClusterRole
+		err := yaml.NewYAMLOrJSONDecoder(strings.NewReader(doc), 4096).Decode(&role)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		if role.Kind != "ClusterRole" && role.Kind != "Role" {
+			continue
+		}
+
+		roles = append(roles, role)
+	}
+
+	return roles, nil
+}
+
+// ClusterRole returns the ClusterRole with the given name defined in the given
+// chart template.
+func ClusterRole(file string, name string) (*rbacv1.ClusterRole, error) {
+	roles, err := Roles(file)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for _, r := range roles {
+		if r.Kind == "ClusterRole" && r.Name == name {
+			return &r, nil
+		}
+	}
+
+	return nil, microerror.Maskf(notFoundError, "cluster role %#q in %#q", name, file)
+}
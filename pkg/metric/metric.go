@@ -0,0 +1,22 @@
+// Package metric registers collectors shared by several resource sets. Every
+// versioned resource set carries its own copy of the resources, which all
+// report into the same metrics, so a collector may be registered already.
+package metric
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MustRegisterGaugeVec registers the given gauge and returns it. In case an
+// equal gauge is registered already the registered one is returned instead. It
+// panics on any other registration error.
+func MustRegisterGaugeVec(g *prometheus.GaugeVec) *prometheus.GaugeVec {
+	err := prometheus.Register(g)
+	if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+		return are.ExistingCollector.(*prometheus.GaugeVec)
+	} else if err != nil {
+		panic(err)
+	}
+
+	return g
+}
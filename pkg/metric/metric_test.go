@@ -0,0 +1,28 @@
+package metric
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func newGaugeVec() *prometheus.GaugeVec {
+	return prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "flannel_operator",
+			Subsystem: "metric_test",
+			Name:      "shared",
+			Help:      "A gauge shared between resource sets.",
+		},
+		[]string{"cluster"},
+	)
+}
+
+func Test_Metric_MustRegisterGaugeVec(t *testing.T) {
+	a := MustRegisterGaugeVec(newGaugeVec())
+	b := MustRegisterGaugeVec(newGaugeVec())
+
+	if a != b {
+		t.Fatal("expected the registered gauge to be returned")
+	}
+}
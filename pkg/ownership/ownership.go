@@ -0,0 +1,112 @@
+// Package ownership implements the ownership model of the objects the
+// operator manages for a FlannelConfig. Kubernetes only allows owner
+// references to owners in the same namespace, which is why most managed
+// objects, like the network namespace, cluster role bindings and everything
+// within the network namespace, cannot be owned by the FlannelConfig directly.
+// All managed objects are labeled with their owner instead. Owner references
+// are set in addition where Kubernetes allows it. Cluster scoped objects of
+// FlannelConfigs which do not exist anymore are removed by the reaper.
+package ownership
+
+import (
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// LabelManagedBy marks objects managed by the operator.
+	LabelManagedBy      = "giantswarm.io/managed-by"
+	LabelManagedByValue = "flannel-operator"
+
+	// LabelOwnerName, LabelOwnerNamespace and LabelOwnerUID identify the
+	// FlannelConfig owning a managed object.
+	LabelOwnerName      = "flannel-operator.giantswarm.io/owner-name"
+	LabelOwnerNamespace = "flannel-operator.giantswarm.io/owner-namespace"
+	LabelOwnerUID       = "flannel-operator.giantswarm.io/owner-uid"
+)
+
+// Labels returns the ownership labels of objects owned by the given custom
+// object.
+func Labels(customObject v1alpha1.FlannelConfig) map[string]string {
+	return map[string]string{
+		LabelManagedBy:      LabelManagedByValue,
+		LabelOwnerName:      customObject.GetName(),
+		LabelOwnerNamespace: customObject.GetNamespace(),
+		LabelOwnerUID:       string(customObject.GetUID()),
+	}
+}
+
+// ManagedSelector returns the label selector matching all objects managed by
+// the operator.
+func ManagedSelector() string {
+	return LabelManagedBy + "=" + LabelManagedByValue
+}
+
+// Set adds the ownership labels of the given custom object to the given
+// object. An owner reference is added in case the object lives in the
+// namespace of the custom object.
+func Set(obj metav1.Object, customObject v1alpha1.FlannelConfig) {
+	labels := map[string]string{}
+	for k, v := range obj.GetLabels() {
+		labels[k] = v
+	}
+	for k, v := range Labels(customObject) {
+		labels[k] = v
+	}
+	obj.SetLabels(labels)
+
+	if obj.GetNamespace() != "" && obj.GetNamespace() == customObject.GetNamespace() && customObject.GetUID() != "" {
+		obj.SetOwnerReferences([]metav1.OwnerReference{
+			newOwnerReference(customObject),
+		})
+	}
+}
+
+// IsManaged returns whether the given object is managed by the operator.
+func IsManaged(obj metav1.Object) bool {
+	return obj.GetLabels()[LabelManagedBy] == LabelManagedByValue
+}
+
+// IsForeign returns whether the given object must not be touched on behalf of
+// the given custom object. That is the case for objects managed by anyone else
+// than the operator and for objects owned by another FlannelConfig. Objects
+// without any ownership labels are not foreign. They were created before
+// ownership labels existed and are adopted.
+func IsForeign(obj metav1.Object, customObject v1alpha1.FlannelConfig) bool {
+	labels := obj.GetLabels()
+
+	managedBy, ok := labels[LabelManagedBy]
+	if !ok {
+		return false
+	}
+	if managedBy != LabelManagedByValue {
+		return true
+	}
+
+	uid, ok := labels[LabelOwnerUID]
+	if !ok || uid == "" || customObject.GetUID() == "" {
+		return false
+	}
+
+	return types.UID(uid) != customObject.GetUID()
+}
+
+// OwnerUID returns the UID of the FlannelConfig owning the given object. It
+// is empty in case the object is not owned by any FlannelConfig.
+func OwnerUID(obj metav1.Object) types.UID {
+	return types.UID(obj.GetLabels()[LabelOwnerUID])
+}
+
+func newOwnerReference(customObject v1alpha1.FlannelConfig) metav1.OwnerReference {
+	t := true
+
+	return metav1.OwnerReference{
+		APIVersion:         v1alpha1.SchemeGroupVersion.String(),
+		Kind:               "FlannelConfig",
+		Name:               customObject.GetName(),
+		UID:                customObject.GetUID(),
+		BlockOwnerDeletion: &t,
+		Controller:         &t,
+	}
+}
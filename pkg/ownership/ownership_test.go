@@ -0,0 +1,134 @@
+package ownership
+
+import (
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newCustomObject(uid string) v1alpha1.FlannelConfig {
+	return v1alpha1.FlannelConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "al9qy",
+			Namespace: "default",
+			UID:       types.UID(uid),
+		},
+	}
+}
+
+func Test_Set(t *testing.T) {
+	testCases := []struct {
+		Name                   string
+		Obj                    metav1.Object
+		ExpectedOwnerReference bool
+	}{
+		{
+			Name: "case 0: cluster scoped objects get labels only",
+			Obj: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "flannel-network-al9qy",
+				},
+			},
+			ExpectedOwnerReference: false,
+		},
+		{
+			Name: "case 1: objects in other namespaces get labels only",
+			Obj: &corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "al9qy",
+					Namespace: "flannel-network-al9qy",
+				},
+			},
+			ExpectedOwnerReference: false,
+		},
+		{
+			Name: "case 2: objects in the namespace of the custom object get owner references",
+			Obj: &corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "al9qy",
+					Namespace: "default",
+					Labels: map[string]string{
+						"app": "flannel-network",
+					},
+				},
+			},
+			ExpectedOwnerReference: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			customObject := newCustomObject("uid-1")
+
+			Set(tc.Obj, customObject)
+
+			if !IsManaged(tc.Obj) {
+				t.Fatalf("expected object to be managed, got labels %#v", tc.Obj.GetLabels())
+			}
+			if OwnerUID(tc.Obj) != "uid-1" {
+				t.Fatalf("expected owner UID %#q got %#q", "uid-1", OwnerUID(tc.Obj))
+			}
+			if tc.ExpectedOwnerReference && len(tc.Obj.GetOwnerReferences()) != 1 {
+				t.Fatalf("expected owner reference got %#v", tc.Obj.GetOwnerReferences())
+			}
+			if !tc.ExpectedOwnerReference && len(tc.Obj.GetOwnerReferences()) != 0 {
+				t.Fatalf("expected no owner reference got %#v", tc.Obj.GetOwnerReferences())
+			}
+		})
+	}
+}
+
+func Test_IsForeign(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Labels   map[string]string
+		Expected bool
+	}{
+		{
+			Name:     "case 0: unlabeled objects are adopted",
+			Labels:   nil,
+			Expected: false,
+		},
+		{
+			Name: "case 1: objects managed by someone else are foreign",
+			Labels: map[string]string{
+				LabelManagedBy: "helm",
+			},
+			Expected: true,
+		},
+		{
+			Name: "case 2: objects owned by another FlannelConfig are foreign",
+			Labels: map[string]string{
+				LabelManagedBy: LabelManagedByValue,
+				LabelOwnerUID:  "uid-2",
+			},
+			Expected: true,
+		},
+		{
+			Name: "case 3: owned objects are not foreign",
+			Labels: map[string]string{
+				LabelManagedBy: LabelManagedByValue,
+				LabelOwnerUID:  "uid-1",
+			},
+			Expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			obj := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: tc.Labels,
+				},
+			}
+
+			foreign := IsForeign(obj, newCustomObject("uid-1"))
+			if foreign != tc.Expected {
+				t.Fatalf("expected %t got %t", tc.Expected, foreign)
+			}
+		})
+	}
+}
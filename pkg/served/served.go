@@ -0,0 +1,42 @@
+// Package served detects whether the API server serves a given resource. It is
+// used to manage objects of optional or deprecated APIs only where they exist.
+package served
+
+import (
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// IsServed returns whether the API server serves the given resource.
+func IsServed(client discovery.DiscoveryInterface, gvr schema.GroupVersionResource) (bool, error) {
+	groups, err := client.ServerGroups()
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	var found bool
+	for _, g := range groups.Groups {
+		for _, v := range g.Versions {
+			if v.GroupVersion == gvr.GroupVersion().String() {
+				found = true
+			}
+		}
+	}
+	if !found {
+		return false, nil
+	}
+
+	list, err := client.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	for _, r := range list.APIResources {
+		if r.Name == gvr.Resource {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,69 @@
+package served
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_IsServed(t *testing.T) {
+	gvr := schema.GroupVersionResource{
+		Group:    "policy",
+		Version:  "v1beta1",
+		Resource: "podsecuritypolicies",
+	}
+
+	testCases := []struct {
+		Name      string
+		Resources []*metav1.APIResourceList
+		Expected  bool
+	}{
+		{
+			Name:     "case 0: group version is not served",
+			Expected: false,
+		},
+		{
+			Name: "case 1: resource is not served",
+			Resources: []*metav1.APIResourceList{
+				{
+					GroupVersion: "policy/v1beta1",
+					APIResources: []metav1.APIResource{
+						{Name: "poddisruptionbudgets"},
+					},
+				},
+			},
+			Expected: false,
+		},
+		{
+			Name: "case 2: resource is served",
+			Resources: []*metav1.APIResourceList{
+				{
+					GroupVersion: "policy/v1beta1",
+					APIResources: []metav1.APIResource{
+						{Name: "poddisruptionbudgets"},
+						{Name: "podsecuritypolicies"},
+					},
+				},
+			},
+			Expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			k8sClient := fake.NewSimpleClientset()
+			k8sClient.Discovery().(*fakediscovery.FakeDiscovery).Resources = tc.Resources
+
+			served, err := IsServed(k8sClient.Discovery(), gvr)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			if served != tc.Expected {
+				t.Fatalf("expected %t got %t", tc.Expected, served)
+			}
+		})
+	}
+}
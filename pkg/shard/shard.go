@@ -0,0 +1,69 @@
+// Package shard distributes tenant clusters across operator replicas. Every
+// cluster is owned by exactly one of Count shards, which is chosen by the FNV-1a
+// hash of its cluster ID. Replicas configured with the same count and distinct
+// indexes therefore reconcile disjoint sets of FlannelConfigs.
+package shard
+
+import (
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+)
+
+type Config struct {
+	// Count is the number of shards. A count of 1 disables sharding.
+	Count int
+	// Index is the shard owned by this replica. It must be lower than Count.
+	Index int
+}
+
+type Shard struct {
+	count int
+	index int
+}
+
+func New(config Config) (*Shard, error) {
+	if config.Count < 1 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Count must be greater than 0", config)
+	}
+	if config.Index < 0 || config.Index >= config.Count {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Index must be between 0 and %d", config, config.Count-1)
+	}
+
+	s := &Shard{
+		count: config.Count,
+		index: config.Index,
+	}
+
+	return s, nil
+}
+
+// IndexFromHostname returns the ordinal suffix of the given hostname, e.g. 2
+// for flannel-operator-2. Pods of a StatefulSet are named like this, which
+// lets every replica of a single release own a distinct shard.
+func IndexFromHostname(hostname string) (int, error) {
+	i := strings.LastIndex(hostname, "-")
+	if i < 0 {
+		return 0, microerror.Maskf(invalidConfigError, "hostname %#q must end with an ordinal suffix", hostname)
+	}
+
+	index, err := strconv.Atoi(hostname[i+1:])
+	if err != nil || index < 0 {
+		return 0, microerror.Maskf(invalidConfigError, "hostname %#q must end with an ordinal suffix", hostname)
+	}
+
+	return index, nil
+}
+
+func (s *Shard) Owns(clusterID string) bool {
+	if s.count == 1 {
+		return true
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(clusterID))
+
+	return int(h.Sum32()%uint32(s.count)) == s.index
+}
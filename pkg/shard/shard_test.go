@@ -0,0 +1,171 @@
+package shard
+
+import (
+	"fmt"
+	"testing"
+)
+
+func Test_Shard_Owns(t *testing.T) {
+	testCases := []struct {
+		Name  string
+		Count int
+	}{
+		{
+			Name:  "case 0: a single shard owns all clusters",
+			Count: 1,
+		},
+		{
+			Name:  "case 1: every cluster is owned by exactly one of two shards",
+			Count: 2,
+		},
+		{
+			Name:  "case 2: every cluster is owned by exactly one of five shards",
+			Count: 5,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var shards []*Shard
+			for i := 0; i < tc.Count; i++ {
+				s, err := New(Config{Count: tc.Count, Index: i})
+				if err != nil {
+					t.Fatal("expected", nil, "got", err)
+				}
+				shards = append(shards, s)
+			}
+
+			owned := make([]int, tc.Count)
+			for i := 0; i < 1000; i++ {
+				id := fmt.Sprintf("c%04d", i)
+
+				var owners int
+				for j, s := range shards {
+					if s.Owns(id) {
+						owners++
+						owned[j]++
+					}
+				}
+				if owners != 1 {
+					t.Fatalf("expected cluster %#q to be owned by 1 shard got %d", id, owners)
+				}
+			}
+
+			for j, n := range owned {
+				if n == 0 {
+					t.Fatalf("expected shard %d to own clusters got none", j)
+				}
+			}
+		})
+	}
+}
+
+func Test_Shard_Owns_Stable(t *testing.T) {
+	a, err := New(Config{Count: 3, Index: 1})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	b, err := New(Config{Count: 3, Index: 1})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	for _, id := range []string{"al9qy", "xa5ly", "5xchu"} {
+		if a.Owns(id) != b.Owns(id) {
+			t.Fatalf("expected ownership of cluster %#q to be stable", id)
+		}
+	}
+}
+
+func Test_Shard_New(t *testing.T) {
+	testCases := []struct {
+		Name         string
+		Config       Config
+		ErrorMatcher func(error) bool
+	}{
+		{
+			Name:         "case 0: a single shard is valid",
+			Config:       Config{Count: 1, Index: 0},
+			ErrorMatcher: nil,
+		},
+		{
+			Name:         "case 1: the count must be greater than 0",
+			Config:       Config{Count: 0, Index: 0},
+			ErrorMatcher: IsInvalidConfig,
+		},
+		{
+			Name:         "case 2: the index must be lower than the count",
+			Config:       Config{Count: 3, Index: 3},
+			ErrorMatcher: IsInvalidConfig,
+		},
+		{
+			Name:         "case 3: the index must not be negative",
+			Config:       Config{Count: 3, Index: -1},
+			ErrorMatcher: IsInvalidConfig,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			_, err := New(tc.Config)
+
+			switch {
+			case err == nil && tc.ErrorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.ErrorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.ErrorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.ErrorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+		})
+	}
+}
+
+func Test_IndexFromHostname(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Hostname      string
+		ExpectedIndex int
+		ErrorMatcher  func(error) bool
+	}{
+		{
+			Name:          "case 0: the ordinal of StatefulSet pods is the index",
+			Hostname:      "flannel-operator-2",
+			ExpectedIndex: 2,
+			ErrorMatcher:  nil,
+		},
+		{
+			Name:         "case 1: pods of a Deployment are rejected",
+			Hostname:     "flannel-operator-957c9d6ff-pkzgw",
+			ErrorMatcher: IsInvalidConfig,
+		},
+		{
+			Name:         "case 2: hostnames without suffix are rejected",
+			Hostname:     "localhost",
+			ErrorMatcher: IsInvalidConfig,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			index, err := IndexFromHostname(tc.Hostname)
+
+			switch {
+			case err == nil && tc.ErrorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.ErrorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.ErrorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.ErrorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if index != tc.ExpectedIndex {
+				t.Fatalf("expected index %d got %d", tc.ExpectedIndex, index)
+			}
+		})
+	}
+}
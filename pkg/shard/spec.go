@@ -0,0 +1,7 @@
+package shard
+
+type Interface interface {
+	// Owns returns whether the tenant cluster with the given ID is reconciled
+	// by this operator replica.
+	Owns(clusterID string) bool
+}
@@ -0,0 +1,13 @@
+package status
+
+import (
+	"context"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+)
+
+type Interface interface {
+	// Write records the given annotations on the given custom object. Empty
+	// values remove the annotation.
+	Write(ctx context.Context, customObject v1alpha1.FlannelConfig, annotations map[string]string) error
+}
@@ -0,0 +1,82 @@
+// Package status records status information of FlannelConfigs. The v1alpha1
+// FlannelConfig type has no status subresource, which is why status
+// information is kept in annotations.
+package status
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type Config struct {
+	G8sClient versioned.Interface
+	Logger    micrologger.Logger
+}
+
+type Writer struct {
+	g8sClient versioned.Interface
+	logger    micrologger.Logger
+}
+
+func New(config Config) (*Writer, error) {
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	w := &Writer{
+		g8sClient: config.G8sClient,
+		logger:    config.Logger,
+	}
+
+	return w, nil
+}
+
+// Write records the given annotations on the given custom object. Empty
+// values remove the annotation. The custom object is only patched in case any
+// annotation changed. A merge patch is used so that concurrent changes of other
+// fields are not overwritten.
+func (w *Writer) Write(ctx context.Context, customObject v1alpha1.FlannelConfig, annotations map[string]string) error {
+	current := customObject.GetAnnotations()
+
+	changes := map[string]interface{}{}
+	for k, v := range annotations {
+		c, ok := current[k]
+		if v == "" && ok {
+			changes[k] = nil
+		}
+		if v != "" && c != v {
+			changes[k] = v
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": changes,
+		},
+	}
+
+	b, err := json.Marshal(patch)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	_, err = w.g8sClient.CoreV1alpha1().FlannelConfigs(customObject.GetNamespace()).Patch(customObject.GetName(), types.MergePatchType, b)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
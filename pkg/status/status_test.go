@@ -0,0 +1,91 @@
+package status
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_Writer_Write(t *testing.T) {
+	testCases := []struct {
+		Name                string
+		Current             map[string]string
+		Annotations         map[string]string
+		ExpectedAnnotations map[string]string
+		ExpectedPatch       bool
+	}{
+		{
+			Name:    "case 0: new annotations are added",
+			Current: map[string]string{"foo": "bar"},
+			Annotations: map[string]string{
+				"status": "ok",
+			},
+			ExpectedAnnotations: map[string]string{"foo": "bar", "status": "ok"},
+			ExpectedPatch:       true,
+		},
+		{
+			Name:    "case 1: unchanged annotations are not patched",
+			Current: map[string]string{"status": "ok"},
+			Annotations: map[string]string{
+				"status": "ok",
+			},
+			ExpectedAnnotations: map[string]string{"status": "ok"},
+			ExpectedPatch:       false,
+		},
+		{
+			Name:    "case 2: empty values remove annotations",
+			Current: map[string]string{"foo": "bar", "status": "ok"},
+			Annotations: map[string]string{
+				"status": "",
+			},
+			ExpectedAnnotations: map[string]string{"foo": "bar"},
+			ExpectedPatch:       true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			customObject := &v1alpha1.FlannelConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "al9qy",
+					Namespace:   "default",
+					Annotations: tc.Current,
+				},
+			}
+			g8sClient := fake.NewSimpleClientset(customObject)
+
+			w, err := New(Config{G8sClient: g8sClient, Logger: microloggertest.New()})
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			err = w.Write(context.Background(), *customObject, tc.Annotations)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			var patched bool
+			for _, a := range g8sClient.Actions() {
+				if a.GetVerb() == "patch" {
+					patched = true
+				}
+			}
+			if patched != tc.ExpectedPatch {
+				t.Fatalf("expected patch %t got %t", tc.ExpectedPatch, patched)
+			}
+
+			fc, err := g8sClient.CoreV1alpha1().FlannelConfigs("default").Get("al9qy", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			if !reflect.DeepEqual(fc.GetAnnotations(), tc.ExpectedAnnotations) {
+				t.Fatalf("expected %#v got %#v", tc.ExpectedAnnotations, fc.GetAnnotations())
+			}
+		})
+	}
+}
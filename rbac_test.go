@@ -0,0 +1,206 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"github.com/giantswarm/flannel-operator/pkg/helmtest"
+)
+
+var (
+	// groupVersionAccessor matches the group version accessors of typed
+	// clientsets, e.g. BatchV1 or CoreV1alpha1.
+	groupVersionAccessor = regexp.MustCompile(`^[A-Z][A-Za-z]*V[0-9]+((alpha|beta)[0-9]+)?$`)
+
+	// apiGroups maps the group version accessors used by the operator to their
+	// API groups. Accessors missing here fail the test, so that new clients
+	// cannot slip past the RBAC check.
+	apiGroups = map[string]string{
+		"ApiextensionsV1beta1": "apiextensions.k8s.io",
+		"AppsV1":               "apps",
+		"BatchV1":              "batch",
+		"CoordinationV1":       "coordination.k8s.io",
+		"CoreV1":               "",
+		"CoreV1alpha1":         "core.giantswarm.io",
+		"ExtensionsV1beta1":    "extensions",
+		"MonitoringV1":         "monitoring.coreos.com",
+		"NetworkingV1":         "networking.k8s.io",
+		"PolicyV1beta1":        "policy",
+		"RbacV1":               "rbac.authorization.k8s.io",
+	}
+
+	verbs = map[string]string{
+		"Create":           "create",
+		"Delete":           "delete",
+		"DeleteCollection": "deletecollection",
+		"Get":              "get",
+		"List":             "list",
+		"Patch":            "patch",
+		"Update":           "update",
+		"Watch":            "watch",
+	}
+)
+
+type clientCall struct {
+	APIGroup string
+	Position string
+	Resource string
+	Verb     string
+}
+
+// Test_RBAC_CoversClientCalls ensures the RBAC rules of the operator grant
+// every verb the operator uses via typed clientsets, e.g.
+// BatchV1().Jobs(namespace).Delete. Objects applied via the dynamic client are
+// not covered.
+func Test_RBAC_CoversClientCalls(t *testing.T) {
+	rules := operatorRules(t, "helm/flannel-operator/templates/rbac.yaml")
+
+	calls := clientCalls(t, ".")
+	if len(calls) == 0 {
+		t.Fatal("expected client calls got none")
+	}
+
+	for _, c := range calls {
+		if !allowed(rules, c) {
+			t.Errorf("%s: RBAC does not allow %#q on %#q in API group %#q", c.Position, c.Verb, c.Resource, c.APIGroup)
+		}
+	}
+}
+
+func allowed(rules []rbacv1.PolicyRule, c clientCall) bool {
+	for _, r := range rules {
+		if len(r.ResourceNames) != 0 {
+			continue
+		}
+		if contains(r.APIGroups, c.APIGroup) && contains(r.Resources, c.Resource) && contains(r.Verbs, c.Verb) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s || l == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// clientCalls finds calls of the form <GroupVersion>().<Resource>(...).<Verb>(...)
+// in all non test files below dir.
+func clientCalls(t *testing.T, dir string) []clientCall {
+	var calls []clientCall
+
+	fset := token.NewFileSet()
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == "vendor" || info.Name() == "fake" || strings.HasPrefix(info.Name(), ".") && path != dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+
+		ast.Inspect(f, func(n ast.Node) bool {
+			verbCall, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			verbSel, ok := verbCall.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			resourceCall, ok := verbSel.X.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			resourceSel, ok := resourceCall.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			groupVersionCall, ok := resourceSel.X.(*ast.CallExpr)
+			if !ok || len(groupVersionCall.Args) != 0 {
+				return true
+			}
+			groupVersionSel, ok := groupVersionCall.Fun.(*ast.SelectorExpr)
+			if !ok || !groupVersionAccessor.MatchString(groupVersionSel.Sel.Name) {
+				return true
+			}
+
+			position := fset.Position(verbCall.Pos()).String()
+
+			verb, ok := verbs[verbSel.Sel.Name]
+			if !ok {
+				return true
+			}
+			apiGroup, ok := apiGroups[groupVersionSel.Sel.Name]
+			if !ok {
+				t.Errorf("%s: unknown group version accessor %#q", position, groupVersionSel.Sel.Name)
+				return true
+			}
+
+			calls = append(calls, clientCall{
+				APIGroup: apiGroup,
+				Position: position,
+				Resource: strings.ToLower(resourceSel.Sel.Name),
+				Verb:     verb,
+			})
+
+			return true
+		})
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	return calls
+}
+
+// operatorRules returns the rules of all roles bound to the operator service
+// account. Template directives are dropped, which leaves the roles granted to
+// other subjects without the operator name.
+func operatorRules(t *testing.T, file string) []rbacv1.PolicyRule {
+	roles, err := helmtest.Roles(file)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	var rules []rbacv1.PolicyRule
+	for _, role := range roles {
+		if !strings.HasPrefix(role.Name, helmtest.DefaultName) {
+			continue
+		}
+
+		rules = append(rules, role.Rules...)
+	}
+
+	if len(rules) == 0 {
+		t.Fatalf("expected rules in %#q got none", file)
+	}
+
+	return rules
+}
@@ -1,34 +1,90 @@
 package endpoint
 
 import (
-	"github.com/giantswarm/microendpoint/endpoint/healthz"
+	"time"
+
 	"github.com/giantswarm/microendpoint/endpoint/version"
+	healthzservice "github.com/giantswarm/microendpoint/service/healthz"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 
+	"github.com/giantswarm/flannel-operator/server/endpoint/probe"
 	"github.com/giantswarm/flannel-operator/service"
 )
 
+const (
+	// probeTimeout is the time every single health check is given. It has to
+	// stay below the timeout of the probes in the operator deployment.
+	probeTimeout = 5 * time.Second
+)
+
 type Config struct {
 	Logger  micrologger.Logger
 	Service *service.Service
 }
 
 type Endpoint struct {
-	Healthz *healthz.Endpoint
-	Version *version.Endpoint
+	Healthz   *probe.Endpoint
+	Liveness  *probe.Endpoint
+	Readiness *probe.Endpoint
+	Version   *version.Endpoint
 }
 
 func New(config Config) (*Endpoint, error) {
 	var err error
 
-	var healthzEndpoint *healthz.Endpoint
+	var livenessEndpoint *probe.Endpoint
+	{
+		c := probe.Config{
+			Logger:   config.Logger,
+			Services: config.Service.LivenessChecks,
+
+			Name:    "liveness",
+			Path:    "/healthz/liveness",
+			Timeout: probeTimeout,
+		}
+
+		livenessEndpoint, err = probe.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var readinessEndpoint *probe.Endpoint
 	{
-		c := healthz.Config{
-			Logger: config.Logger,
+		c := probe.Config{
+			Logger:   config.Logger,
+			Services: config.Service.ReadinessChecks,
+
+			Name:    "readiness",
+			Path:    "/healthz/readiness",
+			Timeout: probeTimeout,
+		}
+
+		readinessEndpoint, err = probe.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	// healthz reports all checks for humans and existing monitoring. The
+	// deployment probes use the liveness and readiness endpoints.
+	var healthzEndpoint *probe.Endpoint
+	{
+		var services []healthzservice.Service
+		services = append(services, config.Service.LivenessChecks...)
+		services = append(services, config.Service.ReadinessChecks...)
+
+		c := probe.Config{
+			Logger:   config.Logger,
+			Services: services,
+
+			Name:    "healthz",
+			Path:    "/healthz",
+			Timeout: probeTimeout,
 		}
 
-		healthzEndpoint, err = healthz.New(c)
+		healthzEndpoint, err = probe.New(c)
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
@@ -48,8 +104,10 @@ func New(config Config) (*Endpoint, error) {
 	}
 
 	e := &Endpoint{
-		Healthz: healthzEndpoint,
-		Version: versionEndpoint,
+		Healthz:   healthzEndpoint,
+		Liveness:  livenessEndpoint,
+		Readiness: readinessEndpoint,
+		Version:   versionEndpoint,
 	}
 
 	return e, nil
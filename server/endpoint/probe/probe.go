@@ -0,0 +1,191 @@
+// Package probe implements health check endpoints which report every check
+// separately, so that the liveness and readiness probes of the operator
+// deployment can be pointed at the checks they care about.
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microendpoint/service/healthz"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	kithttp "github.com/go-kit/kit/transport/http"
+)
+
+const (
+	// Method is the HTTP method probe endpoints are registered for.
+	Method = "GET"
+)
+
+type Config struct {
+	Logger   micrologger.Logger
+	Services []healthz.Service
+
+	// Name identifies the endpoint, e.g. readiness.
+	Name string
+	// Path is the HTTP request path the endpoint is registered for, e.g.
+	// /healthz/readiness.
+	Path string
+	// Timeout is the time every single check is given before it is reported
+	// as failed.
+	Timeout time.Duration
+}
+
+// Response is the JSON document served by probe endpoints.
+type Response struct {
+	Failed bool               `json:"failed"`
+	Checks []healthz.Response `json:"checks"`
+}
+
+type Endpoint struct {
+	logger   micrologger.Logger
+	services []healthz.Service
+
+	name    string
+	path    string
+	timeout time.Duration
+}
+
+func New(config Config) (*Endpoint, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if len(config.Services) == 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Services must not be empty", config)
+	}
+
+	if config.Name == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Name must not be empty", config)
+	}
+	if config.Path == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Path must not be empty", config)
+	}
+	if config.Timeout <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Timeout must be greater than zero", config)
+	}
+
+	e := &Endpoint{
+		logger:   config.Logger,
+		services: config.Services,
+
+		name:    config.Name,
+		path:    config.Path,
+		timeout: config.Timeout,
+	}
+
+	return e, nil
+}
+
+func (e *Endpoint) Decoder() kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		return nil, nil
+	}
+}
+
+func (e *Endpoint) Encoder() kithttp.EncodeResponseFunc {
+	return func(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+		r, ok := response.(Response)
+		if !ok {
+			return microerror.Maskf(wrongTypeError, "expected '%T' got '%T'", Response{}, response)
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		if r.Failed {
+			for _, c := range r.Checks {
+				if c.Failed {
+					e.logger.LogCtx(ctx, "level", "error", "message", "health check failed", "endpoint", e.name, "healthCheck", c.Name, "healthCheckMessage", c.Message)
+				}
+			}
+
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		return json.NewEncoder(w).Encode(r)
+	}
+}
+
+// Endpoint runs all checks concurrently. Checks which do not return in time or
+// return an error are reported as failed, so that a single hanging dependency
+// does not block the whole probe.
+func (e *Endpoint) Endpoint() kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		checks := make([]healthz.Response, len(e.services))
+
+		var wg sync.WaitGroup
+		for i, s := range e.services {
+			wg.Add(1)
+			go func(i int, s healthz.Service) {
+				defer wg.Done()
+				checks[i] = e.check(ctx, s)
+			}(i, s)
+		}
+		wg.Wait()
+
+		r := Response{
+			Checks: checks,
+		}
+		for _, c := range checks {
+			if c.Failed {
+				r.Failed = true
+			}
+		}
+
+		return r, nil
+	}
+}
+
+func (e *Endpoint) Method() string {
+	return Method
+}
+
+func (e *Endpoint) Middlewares() []kitendpoint.Middleware {
+	return []kitendpoint.Middleware{}
+}
+
+func (e *Endpoint) Name() string {
+	return e.name
+}
+
+func (e *Endpoint) Path() string {
+	return e.path
+}
+
+func (e *Endpoint) check(ctx context.Context, s healthz.Service) healthz.Response {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	type result struct {
+		Response healthz.Response
+		Err      error
+	}
+
+	// The channel is buffered so that checks returning after the timeout do
+	// not leak their goroutine.
+	done := make(chan result, 1)
+	go func() {
+		r, err := s.GetHealthz(ctx)
+		done <- result{Response: r, Err: err}
+	}()
+
+	select {
+	case res := <-done:
+		if res.Err != nil {
+			res.Response.Failed = true
+			res.Response.Message = fmt.Sprintf("health check returned an error: %s", res.Err)
+		}
+		return res.Response
+	case <-ctx.Done():
+		return healthz.Response{
+			Failed:  true,
+			Message: fmt.Sprintf("health check did not return within %s", e.timeout),
+			Name:    fmt.Sprintf("%T", s),
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/microendpoint/service/healthz"
+	"github.com/giantswarm/micrologger/microloggertest"
+)
+
+type testService struct {
+	Err    error
+	Failed bool
+	Name   string
+	Sleep  time.Duration
+}
+
+func (s testService) GetHealthz(ctx context.Context) (healthz.Response, error) {
+	if s.Sleep != 0 {
+		select {
+		case <-time.After(s.Sleep):
+		case <-ctx.Done():
+		}
+	}
+
+	return healthz.Response{Failed: s.Failed, Name: s.Name}, s.Err
+}
+
+func Test_Endpoint(t *testing.T) {
+	testCases := []struct {
+		Name               string
+		Services           []healthz.Service
+		ExpectedFailed     []bool
+		ExpectedStatusCode int
+	}{
+		{
+			Name: "case 0: healthy checks are reported",
+			Services: []healthz.Service{
+				testService{Name: "etcd"},
+				testService{Name: "kubernetes"},
+			},
+			ExpectedFailed:     []bool{false, false},
+			ExpectedStatusCode: http.StatusOK,
+		},
+		{
+			Name: "case 1: a single failed check fails the probe",
+			Services: []healthz.Service{
+				testService{Name: "etcd", Failed: true},
+				testService{Name: "kubernetes"},
+			},
+			ExpectedFailed:     []bool{true, false},
+			ExpectedStatusCode: http.StatusServiceUnavailable,
+		},
+		{
+			Name: "case 2: checks returning errors are reported as failed",
+			Services: []healthz.Service{
+				testService{Name: "etcd"},
+				testService{Name: "kubernetes", Err: errors.New("connection refused")},
+			},
+			ExpectedFailed:     []bool{false, true},
+			ExpectedStatusCode: http.StatusServiceUnavailable,
+		},
+		{
+			Name: "case 3: checks exceeding the timeout are reported as failed",
+			Services: []healthz.Service{
+				testService{Name: "etcd", Sleep: time.Minute},
+				testService{Name: "kubernetes"},
+			},
+			ExpectedFailed:     []bool{true, false},
+			ExpectedStatusCode: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			c := Config{
+				Logger:   microloggertest.New(),
+				Services: tc.Services,
+
+				Name:    "readiness",
+				Path:    "/healthz/readiness",
+				Timeout: 100 * time.Millisecond,
+			}
+
+			e, err := New(c)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			response, err := e.Endpoint()(context.Background(), nil)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			w := httptest.NewRecorder()
+			err = e.Encoder()(context.Background(), w, response)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			if w.Code != tc.ExpectedStatusCode {
+				t.Fatalf("expected status code %d got %d", tc.ExpectedStatusCode, w.Code)
+			}
+
+			var r Response
+			err = json.Unmarshal(w.Body.Bytes(), &r)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			if len(r.Checks) != len(tc.ExpectedFailed) {
+				t.Fatalf("expected %d checks got %d", len(tc.ExpectedFailed), len(r.Checks))
+			}
+			for i, f := range tc.ExpectedFailed {
+				if r.Checks[i].Failed != f {
+					t.Fatalf("expected check %d failed %t got %t", i, f, r.Checks[i].Failed)
+				}
+			}
+		})
+	}
+}
@@ -80,6 +80,8 @@ func New(config Config) (*Server, error) {
 
 			Endpoints: []microserver.Endpoint{
 				endpointCollection.Healthz,
+				endpointCollection.Liveness,
+				endpointCollection.Readiness,
 				endpointCollection.Version,
 			},
 			ErrorEncoder: errorEncoder,
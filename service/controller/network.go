@@ -1,30 +1,97 @@
 package controller
 
 import (
+	"context"
+	"time"
+
 	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
 	"github.com/giantswarm/k8sclient"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	"github.com/giantswarm/operatorkit/controller"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 
+	"github.com/giantswarm/flannel-operator/pkg/event"
 	"github.com/giantswarm/flannel-operator/pkg/project"
+	"github.com/giantswarm/flannel-operator/pkg/shard"
+	"github.com/giantswarm/flannel-operator/service/controller/unhandled"
 	v3 "github.com/giantswarm/flannel-operator/service/controller/v3"
+	v3etcd "github.com/giantswarm/flannel-operator/service/controller/v3/etcd"
+	v3flanneld "github.com/giantswarm/flannel-operator/service/controller/v3/resource/flanneld"
+	v3namespace "github.com/giantswarm/flannel-operator/service/controller/v3/resource/namespace"
+	v4 "github.com/giantswarm/flannel-operator/service/controller/v4"
+	v4flanneld "github.com/giantswarm/flannel-operator/service/controller/v4/resource/flanneld"
+	v4namespace "github.com/giantswarm/flannel-operator/service/controller/v4/resource/namespace"
 )
 
 type NetworkConfig struct {
 	K8sClient k8sclient.Interface
 	Logger    micrologger.Logger
+	// Store is the operator wide etcd store. It is shared with the v3 resource
+	// set. The v4 resource set creates its own, since the errors of its etcd
+	// package are matched by version.
+	Store v3etcd.Store
 
 	CAFile           string
 	CrtFile          string
 	CRDLabelSelector string
+	CRDShardCount    int
+	CRDShardIndex    int
 	EtcdEndpoints    []string
 	KeyFile          string
+
+	FlanneldIfaceRegex             string
+	FlanneldIPMasq                 bool
+	FlanneldPublicIP               string
+	FlanneldSubnetLeaseRenewMargin int
+	FlanneldVerbosity              int
+
+	HealthPortMax      int
+	HealthPortMin      int
+	HealthPortReserved []int
+
+	LivenessProbeFailureThreshold    int32
+	LivenessProbeInitialDelaySeconds int32
+	LivenessProbePeriodSeconds       int32
+	LivenessProbeSuccessThreshold    int32
+	LivenessProbeTimeoutSeconds      int32
+
+	ReadinessProbeFailureThreshold    int32
+	ReadinessProbeInitialDelaySeconds int32
+	ReadinessProbePeriodSeconds       int32
+	ReadinessProbeSuccessThreshold    int32
+	ReadinessProbeTimeoutSeconds      int32
+
+	MonitoringEnabled bool
+
+	NetworkNamespaceLimitRangeDefaultCPU           string
+	NetworkNamespaceLimitRangeDefaultMemory        string
+	NetworkNamespaceLimitRangeDefaultRequestCPU    string
+	NetworkNamespaceLimitRangeDefaultRequestMemory string
+	NetworkNamespaceNetworkPolicyEnabled           bool
+	NetworkNamespaceResourceQuotaCPU               string
+	NetworkNamespaceResourceQuotaMemory            string
+	NetworkNamespaceResourceQuotaPods              string
+
+	WorkloadLabelSelector string
+	WorkloadMaxWait       time.Duration
 }
 
 type Network struct {
 	*controller.Controller
+
+	// informer mirrors the FlannelConfigs watched by the controller.
+	// operatorkit does not expose the sync state of its own cache, which is
+	// why the health checks rely on this one, see HasSynced. It is a second,
+	// independent informer with its own list and watch, so its sync state
+	// only approximates the one of the controller cache.
+	informer          cache.SharedIndexInformer
+	unhandledReporter *unhandled.Reporter
 }
 
 func NewNetwork(config NetworkConfig) (*Network, error) {
@@ -34,17 +101,92 @@ func NewNetwork(config NetworkConfig) (*Network, error) {
 
 	var err error
 
-	resourceSets, err := newResourceSets(config)
+	// The label selector is applied to the FlannelConfigs watched by the
+	// controller, so that tenant clusters can be split across operator
+	// deployments, e.g. canary and stable.
+	selector, err := labels.Parse(config.CRDLabelSelector)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.CRDLabelSelector must be a valid label selector: %s", err)
+	}
+
+	var eventRecorder record.EventRecorder
+	{
+		c := event.Config{
+			K8sClient: config.K8sClient,
+			Logger:    config.Logger,
+		}
+
+		eventRecorder, err = event.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var clusterShard shard.Interface
+	{
+		c := shard.Config{
+			Count: config.CRDShardCount,
+			Index: config.CRDShardIndex,
+		}
+
+		clusterShard, err = shard.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	resourceSets, err := newResourceSets(config, eventRecorder, clusterShard)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 
+	var unhandledReporter *unhandled.Reporter
+	{
+		c := unhandled.Config{
+			EventRecorder: eventRecorder,
+			G8sClient:     config.K8sClient.G8sClient(),
+			Logger:        config.Logger,
+			Shard:         clusterShard,
+
+			Interval: 5 * time.Minute,
+			Selector: selector,
+			Versions: []string{
+				v3.VersionBundle().Version,
+				v4.VersionBundle().Version,
+			},
+		}
+
+		unhandledReporter, err = unhandled.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var informer cache.SharedIndexInformer
+	{
+		g8sClient := config.K8sClient.G8sClient()
+
+		lw := &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				options.LabelSelector = selector.String()
+				return g8sClient.CoreV1alpha1().FlannelConfigs(metav1.NamespaceAll).List(options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				options.LabelSelector = selector.String()
+				return g8sClient.CoreV1alpha1().FlannelConfigs(metav1.NamespaceAll).Watch(options)
+			},
+		}
+
+		informer = cache.NewSharedIndexInformer(lw, &v1alpha1.FlannelConfig{}, 0, cache.Indexers{})
+	}
+
 	var operatorkitController *controller.Controller
 	{
 		c := controller.Config{
 			K8sClient:    config.K8sClient,
 			Logger:       config.Logger,
 			ResourceSets: resourceSets,
+			Selector:     selector,
 			NewRuntimeObjectFunc: func() runtime.Object {
 				return new(v1alpha1.FlannelConfig)
 			},
@@ -60,24 +202,95 @@ func NewNetwork(config NetworkConfig) (*Network, error) {
 
 	n := &Network{
 		Controller: operatorkitController,
+
+		informer:          informer,
+		unhandledReporter: unhandledReporter,
 	}
 
 	return n, nil
 }
 
-func newResourceSets(config NetworkConfig) ([]*controller.ResourceSet, error) {
+// Boot starts reporting FlannelConfigs no resource set handles, runs the
+// informer backing HasSynced and boots the controller.
+func (n *Network) Boot(ctx context.Context) {
+	go n.informer.Run(ctx.Done())
+	go n.unhandledReporter.Boot(ctx)
+
+	n.Controller.Boot(ctx)
+}
+
+// HasSynced returns whether the controller booted and the FlannelConfigs it
+// watches have been listed once by the separate informer. The controller cache
+// is not consulted, since operatorkit does not expose it.
+func (n *Network) HasSynced() bool {
+	select {
+	case <-n.Controller.Booted():
+	default:
+		return false
+	}
+
+	return n.informer.HasSynced()
+}
+
+func newResourceSets(config NetworkConfig, eventRecorder record.EventRecorder, clusterShard shard.Interface) ([]*controller.ResourceSet, error) {
 	var err error
 
 	var v3ResourceSet *controller.ResourceSet
 	{
 		c := v3.ResourceSetConfig{
-			K8sClient: config.K8sClient,
-			Logger:    config.Logger,
+			EventRecorder: eventRecorder,
+			K8sClient:     config.K8sClient,
+			Logger:        config.Logger,
+			Shard:         clusterShard,
+			Store:         config.Store,
 
 			CAFile:        config.CAFile,
 			CrtFile:       config.CrtFile,
 			EtcdEndpoints: config.EtcdEndpoints,
 			KeyFile:       config.KeyFile,
+
+			FlanneldOptions: v3flanneld.Options{
+				IfaceRegex:             config.FlanneldIfaceRegex,
+				IPMasq:                 config.FlanneldIPMasq,
+				PublicIP:               config.FlanneldPublicIP,
+				SubnetLeaseRenewMargin: config.FlanneldSubnetLeaseRenewMargin,
+				Verbosity:              config.FlanneldVerbosity,
+			},
+			HealthPortMax:      config.HealthPortMax,
+			HealthPortMin:      config.HealthPortMin,
+			HealthPortReserved: config.HealthPortReserved,
+			LivenessProbe: v3flanneld.Probe{
+				FailureThreshold:    config.LivenessProbeFailureThreshold,
+				InitialDelaySeconds: config.LivenessProbeInitialDelaySeconds,
+				PeriodSeconds:       config.LivenessProbePeriodSeconds,
+				SuccessThreshold:    config.LivenessProbeSuccessThreshold,
+				TimeoutSeconds:      config.LivenessProbeTimeoutSeconds,
+			},
+			ReadinessProbe: v3flanneld.Probe{
+				FailureThreshold:    config.ReadinessProbeFailureThreshold,
+				InitialDelaySeconds: config.ReadinessProbeInitialDelaySeconds,
+				PeriodSeconds:       config.ReadinessProbePeriodSeconds,
+				SuccessThreshold:    config.ReadinessProbeSuccessThreshold,
+				TimeoutSeconds:      config.ReadinessProbeTimeoutSeconds,
+			},
+
+			MonitoringEnabled: config.MonitoringEnabled,
+			NetworkNamespaceBaseline: v3namespace.Baseline{
+				NetworkPolicy: config.NetworkNamespaceNetworkPolicyEnabled,
+				ResourceQuota: v3namespace.ResourceQuota{
+					CPU:    config.NetworkNamespaceResourceQuotaCPU,
+					Memory: config.NetworkNamespaceResourceQuotaMemory,
+					Pods:   config.NetworkNamespaceResourceQuotaPods,
+				},
+				LimitRange: v3namespace.LimitRange{
+					DefaultCPU:           config.NetworkNamespaceLimitRangeDefaultCPU,
+					DefaultMemory:        config.NetworkNamespaceLimitRangeDefaultMemory,
+					DefaultRequestCPU:    config.NetworkNamespaceLimitRangeDefaultRequestCPU,
+					DefaultRequestMemory: config.NetworkNamespaceLimitRangeDefaultRequestMemory,
+				},
+			},
+			WorkloadLabelSelector: config.WorkloadLabelSelector,
+			WorkloadMaxWait:       config.WorkloadMaxWait,
 		}
 
 		v3ResourceSet, err = v3.NewResourceSet(c)
@@ -86,8 +299,72 @@ func newResourceSets(config NetworkConfig) ([]*controller.ResourceSet, error) {
 		}
 	}
 
+	var v4ResourceSet *controller.ResourceSet
+	{
+		c := v4.ResourceSetConfig{
+			EventRecorder: eventRecorder,
+			K8sClient:     config.K8sClient,
+			Logger:        config.Logger,
+			Shard:         clusterShard,
+
+			CAFile:        config.CAFile,
+			CrtFile:       config.CrtFile,
+			EtcdEndpoints: config.EtcdEndpoints,
+			KeyFile:       config.KeyFile,
+
+			FlanneldOptions: v4flanneld.Options{
+				IfaceRegex:             config.FlanneldIfaceRegex,
+				IPMasq:                 config.FlanneldIPMasq,
+				PublicIP:               config.FlanneldPublicIP,
+				SubnetLeaseRenewMargin: config.FlanneldSubnetLeaseRenewMargin,
+				Verbosity:              config.FlanneldVerbosity,
+			},
+			HealthPortMax:      config.HealthPortMax,
+			HealthPortMin:      config.HealthPortMin,
+			HealthPortReserved: config.HealthPortReserved,
+			LivenessProbe: v4flanneld.Probe{
+				FailureThreshold:    config.LivenessProbeFailureThreshold,
+				InitialDelaySeconds: config.LivenessProbeInitialDelaySeconds,
+				PeriodSeconds:       config.LivenessProbePeriodSeconds,
+				SuccessThreshold:    config.LivenessProbeSuccessThreshold,
+				TimeoutSeconds:      config.LivenessProbeTimeoutSeconds,
+			},
+			ReadinessProbe: v4flanneld.Probe{
+				FailureThreshold:    config.ReadinessProbeFailureThreshold,
+				InitialDelaySeconds: config.ReadinessProbeInitialDelaySeconds,
+				PeriodSeconds:       config.ReadinessProbePeriodSeconds,
+				SuccessThreshold:    config.ReadinessProbeSuccessThreshold,
+				TimeoutSeconds:      config.ReadinessProbeTimeoutSeconds,
+			},
+
+			MonitoringEnabled: config.MonitoringEnabled,
+			NetworkNamespaceBaseline: v4namespace.Baseline{
+				NetworkPolicy: config.NetworkNamespaceNetworkPolicyEnabled,
+				ResourceQuota: v4namespace.ResourceQuota{
+					CPU:    config.NetworkNamespaceResourceQuotaCPU,
+					Memory: config.NetworkNamespaceResourceQuotaMemory,
+					Pods:   config.NetworkNamespaceResourceQuotaPods,
+				},
+				LimitRange: v4namespace.LimitRange{
+					DefaultCPU:           config.NetworkNamespaceLimitRangeDefaultCPU,
+					DefaultMemory:        config.NetworkNamespaceLimitRangeDefaultMemory,
+					DefaultRequestCPU:    config.NetworkNamespaceLimitRangeDefaultRequestCPU,
+					DefaultRequestMemory: config.NetworkNamespaceLimitRangeDefaultRequestMemory,
+				},
+			},
+			WorkloadLabelSelector: config.WorkloadLabelSelector,
+			WorkloadMaxWait:       config.WorkloadMaxWait,
+		}
+
+		v4ResourceSet, err = v4.NewResourceSet(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	resourceSets := []*controller.ResourceSet{
 		v3ResourceSet,
+		v4ResourceSet,
 	}
 
 	return resourceSets, nil
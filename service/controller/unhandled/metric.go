@@ -0,0 +1,24 @@
+package unhandled
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	PrometheusNamespace = "flannel_operator"
+	PrometheusSubsystem = "unhandled"
+)
+
+var flannelConfigsGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: PrometheusNamespace,
+		Subsystem: PrometheusSubsystem,
+		Name:      "flannelconfigs",
+		Help:      "Number of FlannelConfigs whose version bundle version is not handled by any resource set, labeled by version.",
+	},
+	[]string{"version"},
+)
+
+func init() {
+	prometheus.MustRegister(flannelConfigsGauge)
+}
@@ -0,0 +1,180 @@
+// Package unhandled reports FlannelConfigs whose version bundle version is not
+// handled by any resource set of the operator. The controller ignores such
+// FlannelConfigs, which means their networks are neither reconciled nor torn
+// down. They are counted in a metric and a warning event is emitted on each of
+// them, so that stale objects do not go unnoticed.
+package unhandled
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/giantswarm/flannel-operator/pkg/event"
+	"github.com/giantswarm/flannel-operator/pkg/shard"
+)
+
+type Config struct {
+	EventRecorder record.EventRecorder
+	G8sClient     versioned.Interface
+	Logger        micrologger.Logger
+	Shard         shard.Interface
+
+	// Interval is the time between two reports.
+	Interval time.Duration
+	// Selector selects the FlannelConfigs watched by the controller.
+	// FlannelConfigs not matching it are handled by other operators.
+	Selector labels.Selector
+	// Versions are the version bundle versions handled by the resource sets.
+	Versions []string
+}
+
+type Reporter struct {
+	eventRecorder record.EventRecorder
+	g8sClient     versioned.Interface
+	logger        micrologger.Logger
+	shard         shard.Interface
+
+	interval time.Duration
+	selector labels.Selector
+	versions map[string]bool
+
+	// reported holds the version every FlannelConfig was last reported with,
+	// so that events are only emitted once per FlannelConfig and version.
+	reported map[types.UID]string
+}
+
+func New(config Config) (*Reporter, error) {
+	if config.EventRecorder == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.EventRecorder must not be empty", config)
+	}
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.Shard == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Shard must not be empty", config)
+	}
+
+	if config.Interval <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Interval must be greater than 0", config)
+	}
+	if config.Selector == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Selector must not be empty", config)
+	}
+	if len(config.Versions) == 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Versions must not be empty", config)
+	}
+
+	versions := map[string]bool{}
+	for _, v := range config.Versions {
+		versions[v] = true
+	}
+
+	r := &Reporter{
+		eventRecorder: config.EventRecorder,
+		g8sClient:     config.G8sClient,
+		logger:        config.Logger,
+		shard:         config.Shard,
+
+		interval: config.Interval,
+		selector: config.Selector,
+		versions: versions,
+
+		reported: map[types.UID]string{},
+	}
+
+	return r, nil
+}
+
+// Boot reports unhandled FlannelConfigs periodically until the given context is
+// done.
+func (r *Reporter) Boot(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		err := r.Report(ctx)
+		if err != nil {
+			r.logger.LogCtx(ctx, "level", "error", "message", "failed to report unhandled FlannelConfigs", "stack", fmt.Sprintf("%#v", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Report counts the FlannelConfigs of this operator whose version bundle
+// version is not handled and emits an event on the ones not reported yet.
+func (r *Reporter) Report(ctx context.Context) error {
+	r.logger.LogCtx(ctx, "level", "debug", "message", "looking for unhandled FlannelConfigs")
+
+	list, err := r.g8sClient.CoreV1alpha1().FlannelConfigs(metav1.NamespaceAll).List(metav1.ListOptions{LabelSelector: r.selector.String()})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	counts := map[string]int{}
+	reported := map[types.UID]string{}
+	for i := range list.Items {
+		c := list.Items[i]
+
+		if !r.shard.Owns(c.Spec.Cluster.ID) {
+			continue
+		}
+
+		version := c.Spec.VersionBundle.Version
+		if r.versions[version] {
+			continue
+		}
+
+		counts[version]++
+		reported[c.GetUID()] = version
+
+		v, ok := r.reported[c.GetUID()]
+		if ok && v == version {
+			continue
+		}
+
+		message := fmt.Sprintf("version bundle version %#q is not handled by any resource set, handled versions are %s", version, r.handledVersions())
+
+		r.logger.LogCtx(ctx, "level", "warning", "message", message, "object", fmt.Sprintf("%s/%s", c.GetNamespace(), c.GetName()))
+		r.eventRecorder.Event(&c, corev1.EventTypeWarning, event.ReasonUnhandledVersion, message)
+	}
+	r.reported = reported
+
+	flannelConfigsGauge.Reset()
+	for version, n := range counts {
+		flannelConfigsGauge.WithLabelValues(version).Set(float64(n))
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found %d unhandled FlannelConfigs", len(reported)))
+
+	return nil
+}
+
+func (r *Reporter) handledVersions() string {
+	var versions []string
+	for v := range r.versions {
+		versions = append(versions, fmt.Sprintf("%#q", v))
+	}
+	sort.Strings(versions)
+
+	return strings.Join(versions, ", ")
+}
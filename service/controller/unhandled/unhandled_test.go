@@ -0,0 +1,137 @@
+package unhandled
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/giantswarm/flannel-operator/pkg/shard"
+)
+
+func newCustomObject(id, version string, l map[string]string) *v1alpha1.FlannelConfig {
+	return &v1alpha1.FlannelConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      id,
+			Namespace: "default",
+			Labels:    l,
+			UID:       types.UID(id),
+		},
+		Spec: v1alpha1.FlannelConfigSpec{
+			Cluster: v1alpha1.FlannelConfigSpecCluster{
+				ID: id,
+			},
+			VersionBundle: v1alpha1.FlannelConfigSpecVersionBundle{
+				Version: version,
+			},
+		},
+	}
+}
+
+func Test_Reporter_Report(t *testing.T) {
+	testCases := []struct {
+		Name              string
+		FlannelConfigs    []runtime.Object
+		Selector          string
+		ExpectedEvents    int
+		ExpectedUnhandled map[string]float64
+	}{
+		{
+			Name: "case 0: handled FlannelConfigs are not reported",
+			FlannelConfigs: []runtime.Object{
+				newCustomObject("al9qy", "0.2.0", nil),
+				newCustomObject("xa5ly", "0.3.0", nil),
+			},
+			ExpectedEvents: 0,
+			ExpectedUnhandled: map[string]float64{
+				"0.1.0": 0,
+			},
+		},
+		{
+			Name: "case 1: unhandled FlannelConfigs are reported once",
+			FlannelConfigs: []runtime.Object{
+				newCustomObject("al9qy", "0.2.0", nil),
+				newCustomObject("xa5ly", "0.1.0", nil),
+				newCustomObject("5xchu", "0.1.0", nil),
+				newCustomObject("p1k9t", "", nil),
+			},
+			ExpectedEvents: 3,
+			ExpectedUnhandled: map[string]float64{
+				"0.1.0": 2,
+				"":      1,
+			},
+		},
+		{
+			Name: "case 2: FlannelConfigs of other operators are not reported",
+			FlannelConfigs: []runtime.Object{
+				newCustomObject("xa5ly", "0.1.0", map[string]string{"channel": "stable"}),
+				newCustomObject("5xchu", "0.1.0", map[string]string{"channel": "canary"}),
+			},
+			Selector:       "channel=canary",
+			ExpectedEvents: 1,
+			ExpectedUnhandled: map[string]float64{
+				"0.1.0": 1,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(10)
+
+			selector, err := labels.Parse(tc.Selector)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			clusterShard, err := shard.New(shard.Config{Count: 1, Index: 0})
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			c := Config{
+				EventRecorder: recorder,
+				G8sClient:     fake.NewSimpleClientset(tc.FlannelConfigs...),
+				Logger:        microloggertest.New(),
+				Shard:         clusterShard,
+
+				Interval: time.Minute,
+				Selector: selector,
+				Versions: []string{"0.2.0", "0.3.0"},
+			}
+
+			r, err := New(c)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			// Reports are repeated periodically, events must only be emitted
+			// once per FlannelConfig though.
+			for i := 0; i < 2; i++ {
+				err = r.Report(context.Background())
+				if err != nil {
+					t.Fatal("expected", nil, "got", err)
+				}
+			}
+
+			if len(recorder.Events) != tc.ExpectedEvents {
+				t.Fatalf("expected %d events got %d", tc.ExpectedEvents, len(recorder.Events))
+			}
+			for version, expected := range tc.ExpectedUnhandled {
+				n := testutil.ToFloat64(flannelConfigsGauge.WithLabelValues(version))
+				if n != expected {
+					t.Fatalf("expected %v unhandled FlannelConfigs of version %#q got %v", expected, version, n)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package etcd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"github.com/giantswarm/microerror"
+)
+
+// StoreCache holds one Store per tenant cluster whose network state lives in
+// a different etcd cluster than the operator wide one. Creating etcd clients
+// on every reconciliation loop would leak connections, which is why Stores are
+// cached and only recreated once the client configuration of a tenant cluster
+// changes, e.g. because its certificates got rotated.
+type StoreCache struct {
+	newStore func(config ClientConfig) (Store, error)
+
+	mutex  sync.Mutex
+	stores map[string]cachedStore
+}
+
+// idleConnectionsCloser is implemented by Stores holding connections which
+// have to be closed once the Store is dropped from the cache, see
+// Service.CloseIdleConnections.
+type idleConnectionsCloser interface {
+	CloseIdleConnections()
+}
+
+type cachedStore struct {
+	Fingerprint string
+	Store       Store
+}
+
+// NewStoreCache creates a StoreCache which creates Stores using the given
+// function, usually NewStore.
+func NewStoreCache(newStore func(config ClientConfig) (Store, error)) *StoreCache {
+	c := &StoreCache{
+		newStore: newStore,
+
+		stores: map[string]cachedStore{},
+	}
+
+	return c
+}
+
+// Delete drops the Store of the given tenant cluster and closes its idle
+// connections.
+func (c *StoreCache) Delete(clusterID string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	cached, ok := c.stores[clusterID]
+	if !ok {
+		return
+	}
+
+	closeIdleConnections(cached.Store)
+	delete(c.stores, clusterID)
+}
+
+// Store returns the Store of the given tenant cluster for the given client
+// configuration. The cached Store is returned as long as the configuration
+// does not change. Replaced Stores get their idle connections closed. Requests
+// still in flight on them finish regularly.
+func (c *StoreCache) Store(clusterID string, config ClientConfig) (Store, error) {
+	fingerprint, err := fingerprint(config)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	cached, ok := c.stores[clusterID]
+	if ok && cached.Fingerprint == fingerprint {
+		return cached.Store, nil
+	}
+
+	store, err := c.newStore(config)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	if ok {
+		closeIdleConnections(cached.Store)
+	}
+
+	c.stores[clusterID] = cachedStore{
+		Fingerprint: fingerprint,
+		Store:       store,
+	}
+
+	return store, nil
+}
+
+func closeIdleConnections(store Store) {
+	closer, ok := store.(idleConnectionsCloser)
+	if ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// fingerprint identifies the given client configuration without keeping the
+// certificates in memory in plain text any longer than necessary.
+func fingerprint(config ClientConfig) (string, error) {
+	b, err := json.Marshal(config)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,118 @@
+package etcd
+
+import (
+	"testing"
+)
+
+type testStore struct {
+	Store
+
+	closed *int
+	config ClientConfig
+}
+
+func (s *testStore) CloseIdleConnections() {
+	*s.closed++
+}
+
+func Test_StoreCache_Store(t *testing.T) {
+	var closed int
+	var created int
+	newStore := func(config ClientConfig) (Store, error) {
+		created++
+		return &testStore{closed: &closed, config: config}, nil
+	}
+
+	c := NewStoreCache(newStore)
+
+	config := ClientConfig{
+		Endpoints: []string{"https://etcd-1.example.com:2379"},
+		CrtData:   []byte("crt"),
+		KeyData:   []byte("key"),
+	}
+
+	steps := []struct {
+		Name            string
+		ClusterID       string
+		Config          func(config ClientConfig) ClientConfig
+		Delete          bool
+		ExpectedClosed  int
+		ExpectedCreated int
+	}{
+		{
+			Name:            "step 0: the first request creates a store",
+			ClusterID:       "al9qy",
+			Config:          func(config ClientConfig) ClientConfig { return config },
+			ExpectedClosed:  0,
+			ExpectedCreated: 1,
+		},
+		{
+			Name:            "step 1: the store is reused for the same configuration",
+			ClusterID:       "al9qy",
+			Config:          func(config ClientConfig) ClientConfig { return config },
+			ExpectedClosed:  0,
+			ExpectedCreated: 1,
+		},
+		{
+			Name:            "step 2: other tenant clusters get their own store",
+			ClusterID:       "xa5ly",
+			Config:          func(config ClientConfig) ClientConfig { return config },
+			ExpectedClosed:  0,
+			ExpectedCreated: 2,
+		},
+		{
+			Name:      "step 3: rotated certificates recreate the store",
+			ClusterID: "al9qy",
+			Config: func(config ClientConfig) ClientConfig {
+				config.CrtData = []byte("rotated")
+				return config
+			},
+			ExpectedClosed:  1,
+			ExpectedCreated: 3,
+		},
+		{
+			Name:      "step 4: changed endpoints recreate the store",
+			ClusterID: "al9qy",
+			Config: func(config ClientConfig) ClientConfig {
+				config.Endpoints = []string{"https://etcd-2.example.com:2379"}
+				return config
+			},
+			ExpectedClosed:  2,
+			ExpectedCreated: 4,
+		},
+		{
+			Name:      "step 5: deleted stores are recreated",
+			ClusterID: "al9qy",
+			Config: func(config ClientConfig) ClientConfig {
+				config.Endpoints = []string{"https://etcd-2.example.com:2379"}
+				return config
+			},
+			Delete:          true,
+			ExpectedClosed:  3,
+			ExpectedCreated: 5,
+		},
+	}
+
+	for _, s := range steps {
+		if s.Delete {
+			c.Delete(s.ClusterID)
+		}
+
+		expected := s.Config(config)
+
+		store, err := c.Store(s.ClusterID, expected)
+		if err != nil {
+			t.Fatalf("%s: expected %#v got %#v", s.Name, nil, err)
+		}
+
+		if closed != s.ExpectedClosed {
+			t.Fatalf("%s: expected %d stores closed got %d", s.Name, s.ExpectedClosed, closed)
+		}
+		if created != s.ExpectedCreated {
+			t.Fatalf("%s: expected %d stores created got %d", s.Name, s.ExpectedCreated, created)
+		}
+		if store.(*testStore).config.Endpoints[0] != expected.Endpoints[0] {
+			t.Fatalf("%s: expected store for %#q got %#q", s.Name, expected.Endpoints[0], store.(*testStore).config.Endpoints[0])
+		}
+	}
+}
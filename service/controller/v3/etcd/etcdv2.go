@@ -2,6 +2,7 @@ package etcd
 
 import (
 	"context"
+	"net/http"
 	"path/filepath"
 	"strings"
 
@@ -67,11 +68,20 @@ type Service struct {
 
 	// Internals.
 	keyClient client.KeysAPI
+	transport *http.Transport
 
 	// Settings.
 	prefix string
 }
 
+// CloseIdleConnections closes the idle connections of the etcd client in case
+// the service created its transport, see NewStore.
+func (s *Service) CloseIdleConnections() {
+	if s.transport != nil {
+		s.transport.CloseIdleConnections()
+	}
+}
+
 func (s *Service) Create(ctx context.Context, key, value string) error {
 	_, err := s.keyClient.Create(ctx, s.key(key), value)
 	if IsEtcdKeyAlreadyExists(err) {
@@ -145,6 +155,35 @@ func (s *Service) List(ctx context.Context, key string) ([]string, error) {
 	return children, nil
 }
 
+func (s *Service) ListDirectories(ctx context.Context, key string) ([]string, error) {
+	options := &client.GetOptions{
+		Quorum: true,
+	}
+	resp, err := s.keyClient.Get(ctx, s.key(key), options)
+	if client.IsKeyNotFound(err) {
+		return nil, microerror.Mask(notFoundError)
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if resp.Node == nil || !resp.Node.Dir {
+		return nil, microerror.Mask(notFoundError)
+	}
+
+	var directories []string
+
+	for _, node := range resp.Node.Nodes {
+		if !node.Dir {
+			continue
+		}
+		if !strings.HasPrefix(node.Key, s.key(key)) {
+			return nil, microerror.Mask(notFoundError)
+		}
+		directories = append(directories, node.Key[len(s.key(key))+1:])
+	}
+
+	return directories, nil
+}
+
 func (s *Service) Search(ctx context.Context, key string) (string, error) {
 	options := &client.GetOptions{
 		Quorum: true,
@@ -27,6 +27,10 @@ func (s *Fake) List(ctx context.Context, key string) ([]string, error) {
 	return nil, nil
 }
 
+func (s *Fake) ListDirectories(ctx context.Context, key string) ([]string, error) {
+	return nil, nil
+}
+
 func (s *Fake) Search(ctx context.Context, key string) (string, error) {
 	return "", nil
 }
@@ -9,5 +9,8 @@ type Store interface {
 	Delete(ctx context.Context, key string) error
 	Exists(ctx context.Context, key string) (bool, error)
 	List(ctx context.Context, key string) ([]string, error)
+	// ListDirectories returns the names of the directories directly below the
+	// given key.
+	ListDirectories(ctx context.Context, key string) ([]string, error)
 	Search(ctx context.Context, key string) (string, error)
 }
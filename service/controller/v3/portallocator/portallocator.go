@@ -0,0 +1,380 @@
+// Package portallocator allocates host ports for the health endpoints of
+// tenant cluster networks. All network daemon sets run in the host network
+// namespace of the same nodes, which is why ports must be unique across all
+// FlannelConfigs and must not collide with ports used by other host network
+// services.
+package portallocator
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
+)
+
+const (
+	// legacyPortBase is the base of the health ports derived from the VNI,
+	// which networks used before ports got allocated explicitly. It must not
+	// follow the configurable port range, otherwise changing the range would
+	// move the ports of existing networks.
+	legacyPortBase = 21000
+
+	// maxAttempts is the number of attempts to record a port on a custom
+	// object which changes concurrently.
+	maxAttempts = 5
+)
+
+type Config struct {
+	G8sClient versioned.Interface
+	Logger    micrologger.Logger
+
+	// FlanneldHealthzPort is the operator wide flanneld healthz port. It is
+	// preferred when allocating flanneld healthz ports. Zero means there is no
+	// preferred port.
+	FlanneldHealthzPort int
+	// Min and Max define the range of host ports health ports are allocated
+	// from.
+	Min int
+	Max int
+	// Reserved is a list of host ports used by other host network services.
+	// They are never allocated.
+	Reserved []int
+}
+
+type Allocator struct {
+	g8sClient versioned.Interface
+	logger    micrologger.Logger
+
+	flanneldHealthzPort int
+	min                 int
+	max                 int
+	reserved            map[int]bool
+}
+
+func New(config Config) (*Allocator, error) {
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.Min < 1 || config.Min > 65535 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Min must be between 1 and 65535", config)
+	}
+	if config.Max < config.Min || config.Max > 65535 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Max must be between %T.Min and 65535", config, config)
+	}
+
+	reserved := map[int]bool{}
+	for _, p := range config.Reserved {
+		reserved[p] = true
+	}
+
+	a := &Allocator{
+		g8sClient: config.G8sClient,
+		logger:    config.Logger,
+
+		flanneldHealthzPort: config.FlanneldHealthzPort,
+		min:                 config.Min,
+		max:                 config.Max,
+		reserved:            reserved,
+	}
+
+	return a, nil
+}
+
+func (a *Allocator) FlanneldHealthzPort(ctx context.Context, customObject v1alpha1.FlannelConfig) (int, error) {
+	port, err := a.allocate(ctx, customObject, key.AnnotationAllocatedFlanneldHealthzPort, key.AnnotationFlanneldHealthzPort, a.flanneldHealthzPort)
+	if err != nil {
+		return 0, microerror.Mask(err)
+	}
+
+	return port, nil
+}
+
+func (a *Allocator) HealthPort(ctx context.Context, customObject v1alpha1.FlannelConfig) (int, error) {
+	// We prefer the port derived from the VNI in order to keep the port of
+	// networks which were created before ports got allocated explicitly. It is
+	// only kept as long as it lies within the configured range.
+	port, err := a.allocate(ctx, customObject, key.AnnotationHealthPort, "", legacyPortBase+key.FlannelVNI(customObject))
+	if err != nil {
+		return 0, microerror.Mask(err)
+	}
+
+	return port, nil
+}
+
+// allocate returns the port recorded in the given annotation of the custom
+// object. In case there is none yet the preferred port is allocated if it is
+// free, otherwise the first free port of the range. Zero means there is no
+// preferred port. A port the user requested via the given request annotation
+// is validated the same way and recorded in place of the allocated port. It
+// is never replaced by another port. An empty request annotation means ports
+// cannot be requested. Allocations are retried in case the custom object
+// changed concurrently.
+func (a *Allocator) allocate(ctx context.Context, customObject v1alpha1.FlannelConfig, annotation, request string, preferred int) (int, error) {
+	for i := 1; ; i++ {
+		port, err := a.tryAllocate(ctx, customObject, annotation, request, preferred)
+		if IsUpdateConflict(err) && i < maxAttempts {
+			a.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("retrying port allocation for annotation %#q due to a concurrent change", annotation))
+			continue
+		} else if err != nil {
+			return 0, microerror.Mask(err)
+		}
+
+		return port, nil
+	}
+}
+
+func (a *Allocator) tryAllocate(ctx context.Context, customObject v1alpha1.FlannelConfig, annotation, request string, preferred int) (int, error) {
+	latest, used, err := a.usedPorts(customObject, annotation, request)
+	if err != nil {
+		return 0, microerror.Mask(err)
+	}
+	annotations := latest.GetAnnotations()
+
+	// Requesting port zero disables the endpoint in case it is optional. If
+	// it is not, a port is allocated as if none was requested.
+	if v, ok := annotations[request]; ok && request != "" && v != "0" {
+		port, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, microerror.Maskf(portConflictError, "annotation %#q must be an integer, got %#q", request, v)
+		}
+
+		err = a.validate(port, used)
+		if err != nil {
+			return 0, microerror.Mask(err)
+		}
+
+		if annotations[annotation] != strconv.Itoa(port) {
+			err = a.claim(latest, annotation, request, port)
+			if err != nil {
+				return 0, microerror.Mask(err)
+			}
+
+			a.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("allocated requested port %d for annotation %#q", port, annotation))
+		}
+
+		return port, nil
+	}
+
+	// In case the port is already allocated we only verify that it is still
+	// valid. We never move an allocated port silently because the running
+	// network pods are bound to it.
+	if v, ok := annotations[annotation]; ok {
+		port, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, microerror.Maskf(portConflictError, "annotation %#q must be an integer, got %#q", annotation, v)
+		}
+
+		err = a.validate(port, used)
+		if err != nil {
+			return 0, microerror.Mask(err)
+		}
+
+		return port, nil
+	}
+
+	a.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("allocating port for annotation %#q", annotation))
+
+	port := preferred
+	if a.validate(port, used) != nil {
+		port = 0
+		for p := a.min; p <= a.max; p++ {
+			if a.validate(p, used) == nil {
+				port = p
+				break
+			}
+		}
+	}
+	if port == 0 {
+		return 0, microerror.Maskf(noPortAvailableError, "all ports between %d and %d are in use or reserved", a.min, a.max)
+	}
+
+	err = a.claim(latest, annotation, request, port)
+	if err != nil {
+		return 0, microerror.Mask(err)
+	}
+
+	a.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("allocated port %d for annotation %#q", port, annotation))
+
+	return port, nil
+}
+
+// claim records the given port in the given annotation of the custom object.
+// Operator replicas of other shards allocate ports for their own clusters at
+// the same time and the precondition of the update only protects the custom
+// object itself. That is why the claim is verified against the claims of all
+// FlannelConfigs after it got recorded. A replica seeing a concurrent claim of
+// the same port releases its own claim. The replica recording its claim last
+// always sees the claims recorded before, so at most one claim is kept.
+func (a *Allocator) claim(customObject v1alpha1.FlannelConfig, annotation, request string, port int) error {
+	updated, err := a.persist(customObject, annotation, port)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	_, used, err := a.usedPorts(*updated, annotation, request)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if id, ok := used[port]; ok {
+		err = a.release(*updated, annotation, port)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		return microerror.Maskf(portConflictError, "port %d got allocated by cluster %#q concurrently", port, id)
+	}
+
+	return nil
+}
+
+// persist records the allocated port on the given state of the custom object.
+// The update is preconditioned on the resource version of that state, so that
+// concurrent changes are never overwritten. They are reported as
+// updateConflictError instead.
+func (a *Allocator) persist(customObject v1alpha1.FlannelConfig, annotation string, port int) (*v1alpha1.FlannelConfig, error) {
+	fc := customObject.DeepCopy()
+	if fc.Annotations == nil {
+		fc.Annotations = map[string]string{}
+	}
+	fc.Annotations[annotation] = strconv.Itoa(port)
+
+	updated, err := a.g8sClient.CoreV1alpha1().FlannelConfigs(fc.GetNamespace()).Update(fc)
+	if apierrors.IsConflict(err) {
+		return nil, microerror.Maskf(updateConflictError, "FlannelConfig %#q changed concurrently", fc.GetName())
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return updated, nil
+}
+
+// release removes the port recorded by claim again. Concurrent changes of the
+// custom object are retried with its latest state, since the port must not
+// stay claimed.
+func (a *Allocator) release(customObject v1alpha1.FlannelConfig, annotation string, port int) error {
+	for i := 1; ; i++ {
+		if customObject.GetAnnotations()[annotation] != strconv.Itoa(port) {
+			return nil
+		}
+
+		fc := customObject.DeepCopy()
+		delete(fc.Annotations, annotation)
+
+		_, err := a.g8sClient.CoreV1alpha1().FlannelConfigs(fc.GetNamespace()).Update(fc)
+		if apierrors.IsConflict(err) && i < maxAttempts {
+			latest, err := a.g8sClient.CoreV1alpha1().FlannelConfigs(fc.GetNamespace()).Get(fc.GetName(), metav1.GetOptions{})
+			if err != nil {
+				return microerror.Mask(err)
+			}
+			customObject = *latest
+
+			continue
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		return nil
+	}
+}
+
+// usedPorts returns the latest state of the given custom object and the ports
+// used by all FlannelConfigs, mapped to the ID of the cluster using them.
+// The ports of the given annotations of the given custom object itself are not
+// accounted. FlannelConfigs without allocated health port are accounted with
+// the port derived from their VNI, which is the port their networks used
+// before ports got allocated explicitly.
+func (a *Allocator) usedPorts(customObject v1alpha1.FlannelConfig, ignored ...string) (v1alpha1.FlannelConfig, map[int]string, error) {
+	// Lists without resource version are served from etcd by a quorum read, so
+	// that the claims of other replicas are visible as soon as they got
+	// recorded.
+	list, err := a.g8sClient.CoreV1alpha1().FlannelConfigs(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return v1alpha1.FlannelConfig{}, nil, microerror.Mask(err)
+	}
+
+	// The custom object given to us might be outdated in case another resource
+	// allocated a port within the same reconciliation loop, which is why we
+	// prefer the state we just listed.
+	latest := customObject
+
+	used := map[int]string{}
+	for _, fc := range list.Items {
+		if key.ClusterID(fc) == key.ClusterID(customObject) {
+			latest = fc
+			continue
+		}
+
+		port := legacyPortBase + key.FlannelVNI(fc)
+		if v, ok := fc.GetAnnotations()[key.AnnotationHealthPort]; ok {
+			p, err := strconv.Atoi(v)
+			if err == nil {
+				port = p
+			}
+		}
+		used[port] = key.ClusterID(fc)
+
+		// Requested ports are accounted before they got validated and
+		// recorded as allocated port, so that clusters cannot take over the
+		// ports of each other.
+		for _, k := range []string{key.AnnotationAllocatedFlanneldHealthzPort, key.AnnotationFlanneldHealthzPort} {
+			if v, ok := fc.GetAnnotations()[k]; ok {
+				p, err := strconv.Atoi(v)
+				if err == nil {
+					used[p] = key.ClusterID(fc)
+				}
+			}
+		}
+	}
+
+	for _, k := range []string{key.AnnotationAllocatedFlanneldHealthzPort, key.AnnotationFlanneldHealthzPort, key.AnnotationHealthPort} {
+		if containsString(ignored, k) {
+			continue
+		}
+
+		if v, ok := latest.GetAnnotations()[k]; ok {
+			p, err := strconv.Atoi(v)
+			if err == nil {
+				used[p] = key.ClusterID(customObject)
+			}
+		}
+	}
+
+	return latest, used, nil
+}
+
+func (a *Allocator) validate(port int, used map[int]string) error {
+	if port < a.min || port > a.max {
+		return microerror.Maskf(portConflictError, "port %d must be between %d and %d", port, a.min, a.max)
+	}
+	if a.reserved[port] {
+		return microerror.Maskf(portConflictError, "port %d is reserved", port)
+	}
+	if id, ok := used[port]; ok {
+		return microerror.Maskf(portConflictError, "port %d is already used by cluster %#q", port, id)
+	}
+
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,459 @@
+package portallocator
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/micrologger/microloggertest"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newFlannelConfig(id string, vni int, annotations map[string]string) *v1alpha1.FlannelConfig {
+	return &v1alpha1.FlannelConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        id,
+			Namespace:   "default",
+			Annotations: annotations,
+		},
+		Spec: v1alpha1.FlannelConfigSpec{
+			Cluster: v1alpha1.FlannelConfigSpecCluster{
+				ID: id,
+			},
+			Flannel: v1alpha1.FlannelConfigSpecFlannel{
+				Spec: v1alpha1.FlannelConfigSpecFlannelSpec{
+					VNI: vni,
+				},
+			},
+		},
+	}
+}
+
+func Test_PortAllocator_HealthPort(t *testing.T) {
+	testCases := []struct {
+		Name         string
+		Existing     []runtime.Object
+		CustomObject *v1alpha1.FlannelConfig
+		Reserved     []int
+		Min          int
+		Max          int
+		ExpectedPort int
+		ErrorMatcher func(error) bool
+	}{
+		{
+			Name:         "case 0: the port derived from the VNI is preferred",
+			CustomObject: newFlannelConfig("al9qy", 26, nil),
+			ExpectedPort: 21026,
+		},
+		{
+			Name: "case 1: a port used by another cluster is skipped",
+			Existing: []runtime.Object{
+				newFlannelConfig("foo", 1, map[string]string{"flannel-operator.giantswarm.io/health-port": "21026"}),
+			},
+			CustomObject: newFlannelConfig("al9qy", 26, nil),
+			ExpectedPort: 21000,
+		},
+		{
+			Name:         "case 2: reserved ports are skipped",
+			CustomObject: newFlannelConfig("al9qy", 26, nil),
+			Reserved:     []int{21000, 21026},
+			ExpectedPort: 21001,
+		},
+		{
+			Name:         "case 3: VNIs exceeding the port range fall back to the first free port",
+			CustomObject: newFlannelConfig("al9qy", 70000, nil),
+			ExpectedPort: 21000,
+		},
+		{
+			Name:         "case 4: allocated ports are kept",
+			CustomObject: newFlannelConfig("al9qy", 26, map[string]string{"flannel-operator.giantswarm.io/health-port": "21500"}),
+			ExpectedPort: 21500,
+		},
+		{
+			Name: "case 5: collisions of allocated ports are reported",
+			Existing: []runtime.Object{
+				newFlannelConfig("foo", 1, map[string]string{"flannel-operator.giantswarm.io/health-port": "21500"}),
+			},
+			CustomObject: newFlannelConfig("al9qy", 26, map[string]string{"flannel-operator.giantswarm.io/health-port": "21500"}),
+			ErrorMatcher: IsPortConflict,
+		},
+		{
+			Name: "case 6: flanneld healthz ports of other clusters are in use",
+			Existing: []runtime.Object{
+				newFlannelConfig("foo", 1, map[string]string{
+					"flannel-operator.giantswarm.io/health-port":           "21001",
+					"flannel-operator.giantswarm.io/flanneld-healthz-port": "21026",
+				}),
+			},
+			CustomObject: newFlannelConfig("al9qy", 26, nil),
+			ExpectedPort: 21000,
+		},
+		{
+			Name:         "case 7: the port derived from the VNI does not follow the configured range",
+			CustomObject: newFlannelConfig("al9qy", 26, nil),
+			Min:          20000,
+			Max:          22999,
+			ExpectedPort: 21026,
+		},
+		{
+			Name: "case 8: clusters without allocated port use the port derived from their VNI",
+			Existing: []runtime.Object{
+				newFlannelConfig("foo", 5, nil),
+			},
+			CustomObject: newFlannelConfig("al9qy", 70000, nil),
+			Min:          21005,
+			Max:          21999,
+			ExpectedPort: 21006,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			min, max := tc.Min, tc.Max
+			if min == 0 {
+				min, max = 21000, 21999
+			}
+
+			objects := append(tc.Existing, tc.CustomObject)
+			g8sClient := fake.NewSimpleClientset(objects...)
+
+			var err error
+			var allocator *Allocator
+			{
+				c := Config{
+					G8sClient: g8sClient,
+					Logger:    microloggertest.New(),
+
+					Max:      max,
+					Min:      min,
+					Reserved: tc.Reserved,
+				}
+
+				allocator, err = New(c)
+				if err != nil {
+					t.Fatal("expected", nil, "got", err)
+				}
+			}
+
+			port, err := allocator.HealthPort(context.Background(), *tc.CustomObject)
+
+			switch {
+			case err == nil && tc.ErrorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.ErrorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.ErrorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.ErrorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if tc.ErrorMatcher != nil {
+				return
+			}
+
+			if port != tc.ExpectedPort {
+				t.Fatalf("expected %d got %d", tc.ExpectedPort, port)
+			}
+
+			fc, err := g8sClient.CoreV1alpha1().FlannelConfigs("default").Get(tc.CustomObject.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			if fc.Annotations["flannel-operator.giantswarm.io/health-port"] != strconv.Itoa(tc.ExpectedPort) {
+				t.Fatalf("expected port %d to be persisted, got %#v", tc.ExpectedPort, fc.Annotations)
+			}
+		})
+	}
+}
+
+func Test_PortAllocator_FlanneldHealthzPort(t *testing.T) {
+	testCases := []struct {
+		Name         string
+		Existing     []runtime.Object
+		CustomObject *v1alpha1.FlannelConfig
+		Preferred    int
+		Reserved     []int
+		ExpectedPort int
+		ErrorMatcher func(error) bool
+	}{
+		{
+			Name:         "case 0: the first free port is allocated",
+			CustomObject: newFlannelConfig("al9qy", 26, map[string]string{"flannel-operator.giantswarm.io/health-port": "21000"}),
+			ExpectedPort: 21001,
+		},
+		{
+			Name:         "case 1: the operator wide port is preferred",
+			CustomObject: newFlannelConfig("al9qy", 26, nil),
+			Preferred:    21500,
+			ExpectedPort: 21500,
+		},
+		{
+			Name: "case 2: the operator wide port is skipped when used by another cluster",
+			Existing: []runtime.Object{
+				newFlannelConfig("foo", 1, map[string]string{
+					"flannel-operator.giantswarm.io/health-port":                     "21001",
+					"flannel-operator.giantswarm.io/allocated-flanneld-healthz-port": "21500",
+				}),
+			},
+			CustomObject: newFlannelConfig("al9qy", 26, nil),
+			Preferred:    21500,
+			ExpectedPort: 21000,
+		},
+		{
+			Name:         "case 3: a requested port is allocated",
+			CustomObject: newFlannelConfig("al9qy", 26, map[string]string{"flannel-operator.giantswarm.io/flanneld-healthz-port": "21600"}),
+			Preferred:    21500,
+			ExpectedPort: 21600,
+		},
+		{
+			Name: "case 4: a requested port replaces the allocated port",
+			CustomObject: newFlannelConfig("al9qy", 26, map[string]string{
+				"flannel-operator.giantswarm.io/allocated-flanneld-healthz-port": "21500",
+				"flannel-operator.giantswarm.io/flanneld-healthz-port":           "21600",
+			}),
+			ExpectedPort: 21600,
+		},
+		{
+			Name:         "case 5: a requested reserved port is rejected",
+			CustomObject: newFlannelConfig("al9qy", 26, map[string]string{"flannel-operator.giantswarm.io/flanneld-healthz-port": "21600"}),
+			Reserved:     []int{21600},
+			ErrorMatcher: IsPortConflict,
+		},
+		{
+			Name:         "case 6: a requested port out of range is rejected",
+			CustomObject: newFlannelConfig("al9qy", 26, map[string]string{"flannel-operator.giantswarm.io/flanneld-healthz-port": "8080"}),
+			ErrorMatcher: IsPortConflict,
+		},
+		{
+			Name: "case 7: a requested port used by another cluster is rejected",
+			Existing: []runtime.Object{
+				newFlannelConfig("foo", 1, map[string]string{"flannel-operator.giantswarm.io/health-port": "21600"}),
+			},
+			CustomObject: newFlannelConfig("al9qy", 26, map[string]string{"flannel-operator.giantswarm.io/flanneld-healthz-port": "21600"}),
+			ErrorMatcher: IsPortConflict,
+		},
+		{
+			Name: "case 8: a requested port requested by another cluster is rejected",
+			Existing: []runtime.Object{
+				newFlannelConfig("foo", 1, map[string]string{"flannel-operator.giantswarm.io/flanneld-healthz-port": "21600"}),
+			},
+			CustomObject: newFlannelConfig("al9qy", 26, map[string]string{"flannel-operator.giantswarm.io/flanneld-healthz-port": "21600"}),
+			ErrorMatcher: IsPortConflict,
+		},
+		{
+			Name: "case 9: a requested port used by the health endpoint of the same cluster is rejected",
+			CustomObject: newFlannelConfig("al9qy", 26, map[string]string{
+				"flannel-operator.giantswarm.io/health-port":           "21600",
+				"flannel-operator.giantswarm.io/flanneld-healthz-port": "21600",
+			}),
+			ErrorMatcher: IsPortConflict,
+		},
+		{
+			Name:         "case 10: requesting port zero allocates a port",
+			CustomObject: newFlannelConfig("al9qy", 26, map[string]string{"flannel-operator.giantswarm.io/flanneld-healthz-port": "0"}),
+			ExpectedPort: 21000,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			objects := append(tc.Existing, tc.CustomObject)
+			g8sClient := fake.NewSimpleClientset(objects...)
+
+			var err error
+			var allocator *Allocator
+			{
+				c := Config{
+					G8sClient: g8sClient,
+					Logger:    microloggertest.New(),
+
+					FlanneldHealthzPort: tc.Preferred,
+					Max:                 21999,
+					Min:                 21000,
+					Reserved:            tc.Reserved,
+				}
+
+				allocator, err = New(c)
+				if err != nil {
+					t.Fatal("expected", nil, "got", err)
+				}
+			}
+
+			port, err := allocator.FlanneldHealthzPort(context.Background(), *tc.CustomObject)
+
+			switch {
+			case err == nil && tc.ErrorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.ErrorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.ErrorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.ErrorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if tc.ErrorMatcher != nil {
+				return
+			}
+
+			if port != tc.ExpectedPort {
+				t.Fatalf("expected %d got %d", tc.ExpectedPort, port)
+			}
+
+			fc, err := g8sClient.CoreV1alpha1().FlannelConfigs("default").Get(tc.CustomObject.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			if fc.Annotations["flannel-operator.giantswarm.io/allocated-flanneld-healthz-port"] != strconv.Itoa(tc.ExpectedPort) {
+				t.Fatalf("expected port %d to be persisted, got %#v", tc.ExpectedPort, fc.Annotations)
+			}
+		})
+	}
+}
+
+// preconditionReactor rejects updates of FlannelConfigs whose resource version
+// is outdated and bumps the resource version otherwise, like the Kubernetes API
+// does. The fake object tracker does neither.
+func preconditionReactor(tracker k8stesting.ObjectTracker) k8stesting.ReactionFunc {
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		fc := action.(k8stesting.UpdateAction).GetObject().(*v1alpha1.FlannelConfig)
+
+		current, err := tracker.Get(action.GetResource(), action.GetNamespace(), fc.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		if current.(*v1alpha1.FlannelConfig).GetResourceVersion() != fc.GetResourceVersion() {
+			return true, nil, apierrors.NewConflict(action.GetResource().GroupResource(), fc.GetName(), fmt.Errorf("resource version changed"))
+		}
+
+		rv, _ := strconv.Atoi(fc.GetResourceVersion())
+		fc.SetResourceVersion(strconv.Itoa(rv + 1))
+
+		return false, nil, nil
+	}
+}
+
+// Test_PortAllocator_Concurrent runs a second allocator in between the list
+// and the update of the first allocator, like the leader of another shard or a
+// former leader of the same shard would. Both allocators share the objects of
+// the same fake clientset. The second allocator uses its own fake clientset
+// for that, since the fake clientset cannot be called from within its own
+// reactors.
+func Test_PortAllocator_Concurrent(t *testing.T) {
+	testCases := []struct {
+		Name string
+		// Other is the cluster the second allocator allocates a port for.
+		Other                string
+		ExpectedErrorMatcher func(error) bool
+		ExpectedPort         int
+		ExpectedOtherPort    int
+	}{
+		{
+			Name:                 "case 0: a port claimed for another cluster concurrently is released",
+			Other:                "foo",
+			ExpectedErrorMatcher: IsPortConflict,
+			ExpectedPort:         21001,
+			ExpectedOtherPort:    21000,
+		},
+		{
+			Name:              "case 1: a port allocated for the same cluster concurrently is kept",
+			Other:             "al9qy",
+			ExpectedPort:      21000,
+			ExpectedOtherPort: 21000,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// VNIs exceeding the port range make both clusters prefer the
+			// first free port.
+			customObject := newFlannelConfig("al9qy", 70000, nil)
+			g8sClient := fake.NewSimpleClientset(customObject, newFlannelConfig("foo", 70001, nil))
+			g8sClient.PrependReactor("update", "flannelconfigs", preconditionReactor(g8sClient.Tracker()))
+
+			otherClient := &fake.Clientset{}
+			otherClient.AddReactor("*", "*", k8stesting.ObjectReaction(g8sClient.Tracker()))
+			otherClient.PrependReactor("update", "flannelconfigs", preconditionReactor(g8sClient.Tracker()))
+
+			newAllocator := func(g8sClient *fake.Clientset) *Allocator {
+				c := Config{
+					G8sClient: g8sClient,
+					Logger:    microloggertest.New(),
+
+					Max: 21999,
+					Min: 21000,
+				}
+
+				allocator, err := New(c)
+				if err != nil {
+					t.Fatal("expected", nil, "got", err)
+				}
+
+				return allocator
+			}
+
+			allocator := newAllocator(g8sClient)
+			otherAllocator := newAllocator(otherClient)
+
+			var otherPort int
+			{
+				var interleaved bool
+				g8sClient.PrependReactor("update", "flannelconfigs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					if interleaved {
+						return false, nil, nil
+					}
+					interleaved = true
+
+					fc, err := otherClient.CoreV1alpha1().FlannelConfigs("default").Get(tc.Other, metav1.GetOptions{})
+					if err != nil {
+						t.Fatal("expected", nil, "got", err)
+					}
+					otherPort, err = otherAllocator.HealthPort(context.Background(), *fc)
+					if err != nil {
+						t.Fatal("expected", nil, "got", err)
+					}
+
+					return false, nil, nil
+				})
+			}
+
+			port, err := allocator.HealthPort(context.Background(), *customObject)
+			if tc.ExpectedErrorMatcher != nil {
+				if !tc.ExpectedErrorMatcher(err) {
+					t.Fatalf("error == %#v, want matching", err)
+				}
+
+				// The resource running the allocation is retried, like the
+				// retry resource does.
+				port, err = allocator.HealthPort(context.Background(), *customObject)
+			}
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			if port != tc.ExpectedPort {
+				t.Fatalf("expected %d got %d", tc.ExpectedPort, port)
+			}
+			if otherPort != tc.ExpectedOtherPort {
+				t.Fatalf("expected %d got %d", tc.ExpectedOtherPort, otherPort)
+			}
+
+			for id, expected := range map[string]int{"al9qy": tc.ExpectedPort, tc.Other: tc.ExpectedOtherPort} {
+				fc, err := g8sClient.CoreV1alpha1().FlannelConfigs("default").Get(id, metav1.GetOptions{})
+				if err != nil {
+					t.Fatal("expected", nil, "got", err)
+				}
+				if fc.Annotations["flannel-operator.giantswarm.io/health-port"] != strconv.Itoa(expected) {
+					t.Fatalf("expected port %d to be persisted for %#q, got %#v", expected, id, fc.Annotations)
+				}
+			}
+		})
+	}
+}
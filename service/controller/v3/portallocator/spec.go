@@ -0,0 +1,18 @@
+package portallocator
+
+import (
+	"context"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+)
+
+type Interface interface {
+	// FlanneldHealthzPort returns the host port the flanneld health endpoint of
+	// the given tenant cluster network listens on. Ports are allocated and
+	// persisted the same way as HealthPort does.
+	FlanneldHealthzPort(ctx context.Context, customObject v1alpha1.FlannelConfig) (int, error)
+	// HealthPort returns the host port the health endpoint of the given tenant
+	// cluster network listens on. Ports are allocated once and persisted on the
+	// custom object so that they stay stable across reconciliations.
+	HealthPort(ctx context.Context, customObject v1alpha1.FlannelConfig) (int, error)
+}
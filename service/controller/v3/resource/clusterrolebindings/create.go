@@ -2,8 +2,93 @@ package clusterrolebindings
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/flannel-operator/pkg/ownership"
+	"github.com/giantswarm/flannel-operator/pkg/served"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
 )
 
 func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	desired := newClusterRoleBindings(customObject)
+
+	// Pod security policy bindings are only managed in case pod security
+	// policies still exist. Otherwise the Pod Security Admission labels of the
+	// namespaces apply and left over bindings are removed.
+	{
+		isServed, err := served.IsServed(r.k8sClient.Discovery(), podSecurityPolicyResource)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		if isServed {
+			desired = append(desired, newPodSecurityPolicyClusterRoleBindings(customObject)...)
+		} else {
+			for _, b := range newPodSecurityPolicyClusterRoleBindings(customObject) {
+				err := r.deleteClusterRoleBinding(ctx, b.GetName())
+				if err != nil {
+					return microerror.Mask(err)
+				}
+			}
+		}
+	}
+
+	for _, b := range desired {
+		err := r.ensureClusterRoleBinding(ctx, customObject, b)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
+
+// ensureClusterRoleBinding applies the given cluster role binding. The role
+// reference of cluster role bindings is immutable. In case it drifted, the
+// current cluster role binding is deleted first. Cluster role bindings which
+// are not owned by the reconciled custom object are left alone.
+func (r *Resource) ensureClusterRoleBinding(ctx context.Context, customObject v1alpha1.FlannelConfig, desired *rbacv1.ClusterRoleBinding) error {
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("ensuring cluster role binding %#q", desired.GetName()))
+
+	current, err := r.k8sClient.RbacV1().ClusterRoleBindings().Get(desired.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		current = nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if current != nil && ownership.IsForeign(current, customObject) {
+		r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("cluster role binding %#q is not owned by this FlannelConfig", desired.GetName()))
+		return nil
+	}
+
+	if current != nil && !reflect.DeepEqual(current.RoleRef, desired.RoleRef) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("role reference of cluster role binding %#q drifted", desired.GetName()))
+
+		err := r.deleteClusterRoleBinding(ctx, desired.GetName())
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	err = r.applier.Apply(ctx, desired)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("ensured cluster role binding %#q", desired.GetName()))
+
 	return nil
 }
@@ -0,0 +1,200 @@
+package clusterrolebindings
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/flannel-operator/pkg/apply/applytest"
+	"github.com/giantswarm/flannel-operator/pkg/ownership"
+)
+
+func newCustomObject() *v1alpha1.FlannelConfig {
+	return &v1alpha1.FlannelConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "al9qy",
+			Namespace: "default",
+			UID:       "uid-1",
+		},
+		Spec: v1alpha1.FlannelConfigSpec{
+			Cluster: v1alpha1.FlannelConfigSpecCluster{
+				Customer: "acme",
+				ID:       "al9qy",
+			},
+		},
+	}
+}
+
+func Test_Resource_EnsureCreated(t *testing.T) {
+	testCases := []struct {
+		Name                       string
+		Objects                    []runtime.Object
+		PodSecurityPolicyServed    bool
+		ExpectedApplied            []string
+		ExpectedClusterRoleBinding []string
+	}{
+		{
+			Name:                    "case 0: bindings of network and destroyer pods are applied",
+			Objects:                 nil,
+			PodSecurityPolicyServed: false,
+			ExpectedApplied: []string{
+				"al9qy-deletion",
+				"flannel-network-al9qy",
+			},
+			ExpectedClusterRoleBinding: nil,
+		},
+		{
+			Name:                    "case 1: pod security policy bindings are applied in case pod security policies are served",
+			Objects:                 nil,
+			PodSecurityPolicyServed: true,
+			ExpectedApplied: []string{
+				"al9qy-deletion",
+				"flannel-network-al9qy",
+				"flannel-network-al9qy-deletion-psp",
+				"flannel-network-al9qy-psp",
+			},
+			ExpectedClusterRoleBinding: nil,
+		},
+		{
+			Name: "case 2: left over pod security policy bindings are deleted",
+			Objects: []runtime.Object{
+				&rbacv1.ClusterRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "flannel-network-al9qy-psp",
+					},
+				},
+			},
+			PodSecurityPolicyServed: false,
+			ExpectedApplied: []string{
+				"al9qy-deletion",
+				"flannel-network-al9qy",
+			},
+			ExpectedClusterRoleBinding: nil,
+		},
+		{
+			Name: "case 3: bindings with a drifted role reference are recreated",
+			Objects: []runtime.Object{
+				&rbacv1.ClusterRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "flannel-network-al9qy",
+					},
+					RoleRef: rbacv1.RoleRef{
+						APIGroup: rbacv1.GroupName,
+						Kind:     "ClusterRole",
+						Name:     "cluster-admin",
+					},
+				},
+			},
+			PodSecurityPolicyServed: false,
+			ExpectedApplied: []string{
+				"al9qy-deletion",
+				"flannel-network-al9qy",
+			},
+			ExpectedClusterRoleBinding: nil,
+		},
+		{
+			Name: "case 4: bindings owned by another FlannelConfig are left alone",
+			Objects: []runtime.Object{
+				&rbacv1.ClusterRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "flannel-network-al9qy",
+						Labels: map[string]string{
+							ownership.LabelManagedBy: ownership.LabelManagedByValue,
+							ownership.LabelOwnerUID:  "uid-2",
+						},
+					},
+				},
+			},
+			PodSecurityPolicyServed: false,
+			ExpectedApplied: []string{
+				"al9qy-deletion",
+			},
+			ExpectedClusterRoleBinding: []string{
+				"flannel-network-al9qy",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			k8sClient := fake.NewSimpleClientset(tc.Objects...)
+			if tc.PodSecurityPolicyServed {
+				k8sClient.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+					{
+						GroupVersion: "policy/v1beta1",
+						APIResources: []metav1.APIResource{
+							{Name: "podsecuritypolicies", Namespaced: false, Kind: "PodSecurityPolicy"},
+						},
+					},
+				}
+			}
+			applier := applytest.New()
+
+			c := Config{
+				Applier:   applier,
+				K8sClient: k8sClient,
+				Logger:    microloggertest.New(),
+			}
+
+			r, err := NewResource(c)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			err = r.EnsureCreated(context.Background(), newCustomObject())
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			var applied []string
+			for _, obj := range applier.Applied() {
+				b := obj.(*rbacv1.ClusterRoleBinding)
+				if b.Subjects[0].Name != "al9qy" {
+					t.Fatalf("expected subject %#q got %#q", "al9qy", b.Subjects[0].Name)
+				}
+				applied = append(applied, b.GetName())
+			}
+			sort.Strings(applied)
+			if !equal(applied, tc.ExpectedApplied) {
+				t.Fatalf("expected applied %v got %v", tc.ExpectedApplied, applied)
+			}
+
+			// The fake applier does not create any objects. What is left in the
+			// fake clientset are the objects which were neither deleted nor
+			// recreated.
+			list, err := k8sClient.RbacV1().ClusterRoleBindings().List(metav1.ListOptions{})
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			var current []string
+			for _, b := range list.Items {
+				current = append(current, b.GetName())
+			}
+			sort.Strings(current)
+			if !equal(current, tc.ExpectedClusterRoleBinding) {
+				t.Fatalf("expected cluster role bindings %v got %v", tc.ExpectedClusterRoleBinding, current)
+			}
+		})
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
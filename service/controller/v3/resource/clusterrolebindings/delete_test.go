@@ -0,0 +1,111 @@
+package clusterrolebindings
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/controller/context/finalizerskeptcontext"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/flannel-operator/pkg/apply/applytest"
+)
+
+func Test_Resource_EnsureDeleted(t *testing.T) {
+	bindings := []runtime.Object{
+		&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "al9qy-deletion"}},
+		&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "flannel-network-al9qy"}},
+		&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "flannel-network-al9qy-deletion-psp"}},
+		&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "flannel-network-al9qy-psp"}},
+	}
+
+	testCases := []struct {
+		Name                   string
+		Namespaces             []runtime.Object
+		ExpectedBindings       int
+		ExpectedFinalizersKept bool
+	}{
+		{
+			Name:                   "case 0: bindings are deleted when no namespace exists",
+			Namespaces:             nil,
+			ExpectedBindings:       0,
+			ExpectedFinalizersKept: false,
+		},
+		{
+			Name: "case 1: bindings are kept while the network namespace exists",
+			Namespaces: []runtime.Object{
+				&corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{Name: "flannel-network-al9qy"},
+					Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+				},
+			},
+			ExpectedBindings:       4,
+			ExpectedFinalizersKept: true,
+		},
+		{
+			Name: "case 2: bindings are kept while the destroyer namespace exists",
+			Namespaces: []runtime.Object{
+				&corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{Name: "flannel-destroyer-al9qy"},
+					Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+				},
+			},
+			ExpectedBindings:       4,
+			ExpectedFinalizersKept: true,
+		},
+		{
+			Name: "case 3: bindings are deleted when the namespaces are terminating",
+			Namespaces: []runtime.Object{
+				&corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{Name: "flannel-network-al9qy"},
+					Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceTerminating},
+				},
+				&corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{Name: "flannel-destroyer-al9qy"},
+					Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceTerminating},
+				},
+			},
+			ExpectedBindings:       0,
+			ExpectedFinalizersKept: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			k8sClient := fake.NewSimpleClientset(append(tc.Namespaces, bindings...)...)
+
+			c := Config{
+				Applier:   applytest.New(),
+				K8sClient: k8sClient,
+				Logger:    microloggertest.New(),
+			}
+
+			r, err := NewResource(c)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			ctx := finalizerskeptcontext.NewContext(context.Background(), make(chan struct{}))
+
+			err = r.EnsureDeleted(ctx, newCustomObject())
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			list, err := k8sClient.RbacV1().ClusterRoleBindings().List(metav1.ListOptions{})
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			if len(list.Items) != tc.ExpectedBindings {
+				t.Fatalf("expected %d bindings got %d", tc.ExpectedBindings, len(list.Items))
+			}
+			if finalizerskeptcontext.IsKept(ctx) != tc.ExpectedFinalizersKept {
+				t.Fatalf("expected finalizers kept %t got %t", tc.ExpectedFinalizersKept, finalizerskeptcontext.IsKept(ctx))
+			}
+		})
+	}
+}
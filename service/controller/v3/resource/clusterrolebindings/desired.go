@@ -0,0 +1,77 @@
+package clusterrolebindings
+
+import (
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apismeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/flannel-operator/pkg/ownership"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
+)
+
+const (
+	// clusterRoleName is the cluster role installed with the operator which
+	// grants the cluster scoped permissions the network pods and the destroyer
+	// pods need. Namespaced permissions are granted by the role resource.
+	clusterRoleName                  = "flannel-network"
+	clusterRolePodSecurityPolicyName = "flannel-operator-psp"
+)
+
+// newClusterRoleBindings returns the cluster role bindings of the service
+// accounts of the network pods and the destroyer pods. The bindings for the
+// destroyer pods are created upfront so that they exist when the network of
+// the tenant cluster gets cleaned up on deletion.
+func newClusterRoleBindings(customObject v1alpha1.FlannelConfig) []*rbacv1.ClusterRoleBinding {
+	return []*rbacv1.ClusterRoleBinding{
+		newClusterRoleBinding(customObject, key.ClusterRoleBindingName(customObject), key.NetworkNamespace(customObject), clusterRoleName),
+		newClusterRoleBinding(customObject, key.ClusterRoleBindingForDeletionName(customObject), key.DestroyerNamespace(customObject), clusterRoleName),
+	}
+}
+
+// newPodSecurityPolicyClusterRoleBindings returns the cluster role bindings
+// granting the service accounts of the network pods and the destroyer pods the
+// use of the flannel operator pod security policy.
+func newPodSecurityPolicyClusterRoleBindings(customObject v1alpha1.FlannelConfig) []*rbacv1.ClusterRoleBinding {
+	return []*rbacv1.ClusterRoleBinding{
+		newClusterRoleBinding(customObject, key.ClusterRoleBindingPodSecurityPolicyName(customObject), key.NetworkNamespace(customObject), clusterRolePodSecurityPolicyName),
+		newClusterRoleBinding(customObject, key.ClusterRoleBindingPodSecurityPolicyForDeletionName(customObject), key.DestroyerNamespace(customObject), clusterRolePodSecurityPolicyName),
+	}
+}
+
+func newClusterRoleBinding(customObject v1alpha1.FlannelConfig, name, subjectNamespace, roleName string) *rbacv1.ClusterRoleBinding {
+	clusterRoleBinding := &rbacv1.ClusterRoleBinding{
+		TypeMeta: apismeta.TypeMeta{
+			Kind:       "ClusterRoleBinding",
+			APIVersion: rbacv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: apismeta.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				"app":                        key.NetworkID,
+				"giantswarm.io/cluster":      key.ClusterID(customObject),
+				"giantswarm.io/organization": key.ClusterCustomer(customObject),
+				// TODO remove deprecated labels.
+				//
+				//     https://github.com/giantswarm/giantswarm/issues/5860
+				//
+				"cluster-id":  key.ClusterID(customObject),
+				"customer-id": key.ClusterCustomer(customObject),
+			},
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Namespace: subjectNamespace,
+				Name:      key.ServiceAccountName(customObject),
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     roleName,
+		},
+	}
+	ownership.Set(clusterRoleBinding, customObject)
+
+	return clusterRoleBinding
+}
@@ -3,24 +3,44 @@ package clusterrolebindings
 import (
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
+
+	"github.com/giantswarm/flannel-operator/pkg/apply"
 )
 
 const (
 	Name = "clusterrolebindingsv3"
 )
 
+var (
+	// podSecurityPolicyResource is the deprecated pod security policy API. It
+	// is not served anymore by current Kubernetes versions.
+	podSecurityPolicyResource = schema.GroupVersionResource{
+		Group:    "policy",
+		Version:  "v1beta1",
+		Resource: "podsecuritypolicies",
+	}
+)
+
 type Config struct {
+	Applier   apply.Interface
 	K8sClient kubernetes.Interface
 	Logger    micrologger.Logger
 }
 
+// Resource manages the per-cluster cluster role bindings of the service
+// accounts of the network pods and the destroyer pods.
 type Resource struct {
+	applier   apply.Interface
 	k8sClient kubernetes.Interface
 	logger    micrologger.Logger
 }
 
 func NewResource(config Config) (*Resource, error) {
+	if config.Applier == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Applier must not be empty", config)
+	}
 	if config.K8sClient == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
 	}
@@ -29,6 +49,7 @@ func NewResource(config Config) (*Resource, error) {
 	}
 
 	r := &Resource{
+		applier:   config.Applier,
 		k8sClient: config.K8sClient,
 		logger:    config.Logger,
 	}
@@ -0,0 +1,11 @@
+package deletionprotection
+
+import (
+	"context"
+)
+
+// EnsureCreated does nothing. The deletion protection only affects deleted
+// FlannelConfigs.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	return nil
+}
@@ -0,0 +1,63 @@
+package deletionprotection
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller/context/finalizerskeptcontext"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/giantswarm/flannel-operator/pkg/event"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
+)
+
+var protectedReason = fmt.Sprintf("network teardown is held by annotation %#q, remove it to proceed with the deletion", key.AnnotationDeletionProtection)
+
+// EnsureDeleted holds the network teardown of protected FlannelConfigs. The
+// finalizers are kept and the reconciliation is canceled, so that none of the
+// following resources touches the bridges, the network state in etcd or the
+// namespaces. Removing the annotation triggers an update event, which replays
+// the deletion. The protection is recorded as reason in the deletion blocked
+// annotation, so that the event is only emitted once the teardown gets held.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	recorded := customObject.GetAnnotations()[key.AnnotationDeletionBlocked]
+
+	if !key.IsDeletionProtected(customObject) {
+		// Other reasons are recorded by the resources blocking the teardown
+		// and must be kept.
+		if recorded == protectedReason {
+			err = r.statusWriter.Write(ctx, customObject, map[string]string{key.AnnotationDeletionBlocked: ""})
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+
+		return nil
+	}
+
+	r.logger.LogCtx(ctx, "level", "warning", "message", protectedReason)
+
+	if recorded != protectedReason {
+		r.eventRecorder.Event(&customObject, corev1.EventTypeWarning, event.ReasonDeletionProtected, protectedReason)
+
+		err = r.statusWriter.Write(ctx, customObject, map[string]string{key.AnnotationDeletionBlocked: protectedReason})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	finalizerskeptcontext.SetKept(ctx)
+	r.logger.LogCtx(ctx, "level", "debug", "message", "keeping finalizers")
+
+	reconciliationcanceledcontext.SetCanceled(ctx)
+	r.logger.LogCtx(ctx, "level", "debug", "message", "canceling reconciliation")
+
+	return nil
+}
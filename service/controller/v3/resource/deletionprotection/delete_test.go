@@ -0,0 +1,170 @@
+package deletionprotection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/controller/context/finalizerskeptcontext"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/giantswarm/flannel-operator/pkg/status"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
+)
+
+func Test_Resource_DeletionProtection_EnsureDeleted(t *testing.T) {
+	testCases := []struct {
+		Name            string
+		Annotations     map[string]string
+		ExpectedHeld    bool
+		ExpectedEvents  int
+		ExpectedBlocked string
+	}{
+		{
+			Name:            "case 0: unprotected networks are torn down",
+			Annotations:     nil,
+			ExpectedHeld:    false,
+			ExpectedEvents:  0,
+			ExpectedBlocked: "",
+		},
+		{
+			Name: "case 1: protected networks are held",
+			Annotations: map[string]string{
+				key.AnnotationDeletionProtection: "true",
+			},
+			ExpectedHeld:    true,
+			ExpectedEvents:  1,
+			ExpectedBlocked: protectedReason,
+		},
+		{
+			Name: "case 2: networks with disabled protection are torn down",
+			Annotations: map[string]string{
+				key.AnnotationDeletionProtection: "false",
+			},
+			ExpectedHeld:    false,
+			ExpectedEvents:  0,
+			ExpectedBlocked: "",
+		},
+		{
+			Name: "case 3: networks with invalid protection values are held",
+			Annotations: map[string]string{
+				key.AnnotationDeletionProtection: "yes",
+			},
+			ExpectedHeld:    true,
+			ExpectedEvents:  1,
+			ExpectedBlocked: protectedReason,
+		},
+		{
+			Name: "case 4: networks held already do not emit events again",
+			Annotations: map[string]string{
+				key.AnnotationDeletionBlocked:    protectedReason,
+				key.AnnotationDeletionProtection: "true",
+			},
+			ExpectedHeld:    true,
+			ExpectedEvents:  0,
+			ExpectedBlocked: protectedReason,
+		},
+		{
+			Name: "case 5: networks held by another reason emit an event",
+			Annotations: map[string]string{
+				key.AnnotationDeletionBlocked:    "bridge cleanup failed",
+				key.AnnotationDeletionProtection: "true",
+			},
+			ExpectedHeld:    true,
+			ExpectedEvents:  1,
+			ExpectedBlocked: protectedReason,
+		},
+		{
+			Name: "case 6: the recorded protection is removed once the protection is removed",
+			Annotations: map[string]string{
+				key.AnnotationDeletionBlocked: protectedReason,
+			},
+			ExpectedHeld:    false,
+			ExpectedEvents:  0,
+			ExpectedBlocked: "",
+		},
+		{
+			Name: "case 7: other reasons are kept once the protection is removed",
+			Annotations: map[string]string{
+				key.AnnotationDeletionBlocked: "bridge cleanup failed",
+			},
+			ExpectedHeld:    false,
+			ExpectedEvents:  0,
+			ExpectedBlocked: "bridge cleanup failed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			customObject := &v1alpha1.FlannelConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "al9qy",
+					Namespace:         "default",
+					Annotations:       tc.Annotations,
+					DeletionTimestamp: &metav1.Time{Time: time.Now()},
+				},
+			}
+
+			g8sClient := fake.NewSimpleClientset(customObject)
+			recorder := record.NewFakeRecorder(10)
+
+			var statusWriter status.Interface
+			{
+				c := status.Config{
+					G8sClient: g8sClient,
+					Logger:    microloggertest.New(),
+				}
+
+				w, err := status.New(c)
+				if err != nil {
+					t.Fatal("expected", nil, "got", err)
+				}
+				statusWriter = w
+			}
+
+			c := Config{
+				EventRecorder: recorder,
+				Logger:        microloggertest.New(),
+				StatusWriter:  statusWriter,
+			}
+
+			r, err := New(c)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			ctx := context.Background()
+			ctx = finalizerskeptcontext.NewContext(ctx, make(chan struct{}))
+			ctx = reconciliationcanceledcontext.NewContext(ctx, make(chan struct{}))
+
+			err = r.EnsureDeleted(ctx, customObject)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			if finalizerskeptcontext.IsKept(ctx) != tc.ExpectedHeld {
+				t.Fatalf("expected finalizers kept %t got %t", tc.ExpectedHeld, finalizerskeptcontext.IsKept(ctx))
+			}
+			if reconciliationcanceledcontext.IsCanceled(ctx) != tc.ExpectedHeld {
+				t.Fatalf("expected reconciliation canceled %t got %t", tc.ExpectedHeld, reconciliationcanceledcontext.IsCanceled(ctx))
+			}
+
+			if len(recorder.Events) != tc.ExpectedEvents {
+				t.Fatalf("expected %d events got %d", tc.ExpectedEvents, len(recorder.Events))
+			}
+
+			fc, err := g8sClient.CoreV1alpha1().FlannelConfigs("default").Get("al9qy", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			if fc.Annotations[key.AnnotationDeletionBlocked] != tc.ExpectedBlocked {
+				t.Fatalf("expected deletion blocked %#q got %#q", tc.ExpectedBlocked, fc.Annotations[key.AnnotationDeletionBlocked])
+			}
+		})
+	}
+}
@@ -5,7 +5,6 @@ import (
 
 	"github.com/giantswarm/microerror"
 	appsv1 "k8s.io/api/apps/v1"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
 func (r *Resource) ApplyCreateChange(ctx context.Context, obj, createChange interface{}) error {
@@ -17,10 +16,8 @@ func (r *Resource) ApplyCreateChange(ctx context.Context, obj, createChange inte
 	if daemonSetToCreate != nil {
 		r.logger.LogCtx(ctx, "level", "debug", "message", "creating the daemon set in the Kubernetes API")
 
-		_, err = r.k8sClient.AppsV1().DaemonSets(daemonSetToCreate.GetNamespace()).Create(daemonSetToCreate)
-		if apierrors.IsAlreadyExists(err) {
-			// fall through
-		} else if err != nil {
+		err = r.applier.Apply(ctx, daemonSetToCreate)
+		if err != nil {
 			return microerror.Mask(err)
 		}
 
@@ -14,6 +14,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/giantswarm/flannel-operator/pkg/ownership"
 	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
 )
 
@@ -49,19 +50,19 @@ func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interf
 
 	// In case a tenant cluster deletion happens, we want to delete the tenant
 	// cluster network. We still need to use the network for resource creation in
-	// order to drain nodes on KVM though. So as long as pods are there we delay
-	// the deletion of the network here in order to still be able to create
-	// resources. As soon as the draining was done and the pods got removed, we
-	// get an empty list after the delete event got replayed. Then we delete the
+	// order to drain nodes on KVM though. So as long as the workload gate holds
+	// the deletion we keep the daemon set in order to still be able to create
+	// resources. As soon as the draining was done and the workloads got removed,
+	// the gate opens after the delete event got replayed. Then we delete the
 	// daemon set as usual.
 	if key.IsDeleted(customObject) {
-		list, err := r.k8sClient.CoreV1().Pods(key.ClusterNamespace(customObject)).List(metav1.ListOptions{})
+		wait, err := r.workloadGate.Wait(ctx, customObject)
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
 
-		if len(list.Items) != 0 {
-			r.logger.LogCtx(ctx, "level", "debug", "message", "cannot finish deletion due to existing pods")
+		if wait {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "cannot finish deletion due to existing workloads")
 
 			finalizerskeptcontext.SetKept(ctx)
 			r.logger.LogCtx(ctx, "level", "debug", "message", "keeping finalizers")
@@ -87,6 +88,17 @@ func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interf
 		} else {
 			r.logger.LogCtx(ctx, "level", "debug", "message", "found the daemon set in the Kubernetes API")
 
+			// The daemon set might be owned by another FlannelConfig or might not
+			// be managed by the operator at all. We must not touch it then.
+			if ownership.IsForeign(manifest, customObject) {
+				r.logger.LogCtx(ctx, "level", "warning", "message", "daemon set is not owned by this FlannelConfig")
+
+				resourcecanceledcontext.SetCanceled(ctx)
+				r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+
+				return nil, nil
+			}
+
 			currentDaemonSet = manifest
 
 			r.updateVersionBundleVersionGauge(ctx, customObject, versionBundleVersionGauge, currentDaemonSet)
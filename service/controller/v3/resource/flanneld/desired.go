@@ -2,7 +2,6 @@ package flanneld
 
 import (
 	"context"
-	"fmt"
 	"strconv"
 	"strings"
 
@@ -10,22 +9,21 @@ import (
 	"github.com/giantswarm/microerror"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/intstr"
 
+	"github.com/giantswarm/flannel-operator/pkg/ownership"
 	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
 )
 
+const (
+	envHostIP = "HOST_IP"
+)
+
 var (
-	containersPrivileged       = true
-	failureThreshold     int32 = 2
-	healthEndpoint             = "/healthz"
-	initialDelaySeconds  int32 = 10
-	periodSeconds        int32 = 10
-	portBase                   = 21000
-	probeHost                  = "127.0.0.1"
-	successThreshold     int32 = 1
-	timeoutSeconds       int32 = 5
+	containersPrivileged = true
+	healthEndpoint       = "/healthz"
+	probeHost            = "127.0.0.1"
 )
 
 func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interface{}, error) {
@@ -36,27 +34,162 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 
 	r.logger.LogCtx(ctx, "level", "debug", "message", "computing the desired daemon set")
 
-	daemonSet := newDaemonSet(customObject, r.etcdEndpoints, r.etcdCAFile, r.etcdCrtFile, r.etcdKeyFile)
+	options, err := r.options.withOverrides(customObject)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	healthPort, err := r.portAllocator.HealthPort(ctx, customObject)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	// Health endpoints are only reachable from within the node unless they have
+	// to be scraped by Prometheus. In that case they bind to the host IP and
+	// the flanneld health endpoint is enabled on an allocated port.
+	endpoints := endpoints{
+		HealthPort: healthPort,
+		ListenIP:   probeHost,
+		ProbeHost:  probeHost,
+	}
+	if r.monitoringEnabled {
+		endpoints.ListenIP = "$(" + envHostIP + ")"
+		endpoints.ProbeHost = ""
+	}
+
+	// The flanneld health endpoint binds to a host port as well. Ports
+	// requested via key.AnnotationFlanneldHealthzPort and the operator wide
+	// port are validated by the port allocator like any other port, so that
+	// they neither collide with other clusters nor with reserved ports.
+	if r.monitoringEnabled || options.HealthzPort != 0 {
+		options.HealthzPort, err = r.portAllocator.FlanneldHealthzPort(ctx, customObject)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	probes := probes{
+		Liveness:  r.livenessProbe,
+		Readiness: r.readinessProbe,
+	}
+
+	// Tenant clusters may use a different etcd cluster than the operator wide
+	// one, see key.AnnotationEtcdEndpoints.
+	etcdEndpoints, err := key.EtcdEndpoints(customObject)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if len(etcdEndpoints) == 0 {
+		etcdEndpoints = r.etcdEndpoints
+	}
+
+	// flanneld only reads its etcd certificates on startup. The secret the
+	// network pods mount is reconciled by the secret resource before, so that
+	// its checksum reflects rotated certificates of the current loop. The
+	// secret is gone on deletion, in which case the pods are not annotated.
+	var certsChecksum string
+	{
+		secret, err := r.k8sClient.CoreV1().Secrets(key.NetworkNamespace(customObject)).Get(key.EtcdCertsSecretName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			// fall through
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		} else {
+			certsChecksum = key.EtcdCertsChecksum(secret.Data)
+		}
+	}
+
+	daemonSet := newDaemonSet(customObject, etcdEndpoints, options, probes, endpoints, certsChecksum)
 
 	r.logger.LogCtx(ctx, "level", "debug", "message", "computed the desired daemon set")
 
 	return daemonSet, nil
 }
 
-func healthListenAddress(customObject v1alpha1.FlannelConfig) string {
-	return "http://" + probeHost + ":" + strconv.Itoa(int(livenessProbePort(customObject)))
+type probes struct {
+	Liveness  Probe
+	Readiness Probe
+}
+
+// endpoints describes where the health endpoints of the network containers
+// listen and where the kubelet probes them.
+type endpoints struct {
+	HealthPort int
+	// ListenIP is the IP the health endpoints bind to.
+	ListenIP string
+	// ProbeHost is the host the kubelet sends probes to. Empty means the pod IP,
+	// which is the node IP for host network pods.
+	ProbeHost string
+}
+
+func (e endpoints) healthListenAddress() string {
+	return "http://" + e.ListenIP + ":" + strconv.Itoa(e.HealthPort)
 }
 
-func livenessProbePort(customObject v1alpha1.FlannelConfig) int32 {
-	return int32(portBase + key.FlannelVNI(customObject))
+// flanneldReadinessProbe checks the flanneld health endpoint in case it is
+// enabled. Otherwise flanneld is considered ready as soon as the network health
+// endpoint reports the flannel device to be set up.
+func flanneldReadinessProbe(p Probe, options Options, endpoints endpoints) *corev1.Probe {
+	if options.HealthzPort != 0 {
+		return newHTTPProbe(p, endpoints.ProbeHost, options.HealthzPort)
+	}
+
+	return newHTTPProbe(p, endpoints.ProbeHost, endpoints.HealthPort)
 }
 
-func newDaemonSet(customObject v1alpha1.FlannelConfig, etcdEndpoints []string, etcdCAFile, etcdCrtFile, etcdKeyFile string) *appsv1.DaemonSet {
-	etcdEndpointsString := strings.Join(etcdEndpoints, ",")
+// flanneldPorts declares the flanneld health endpoint in case it is enabled so
+// that it can be referenced by name.
+func flanneldPorts(options Options) []corev1.ContainerPort {
+	if options.HealthzPort == 0 {
+		return nil
+	}
 
-	return &appsv1.DaemonSet{
+	ports := []corev1.ContainerPort{
+		{
+			Name:          key.PortNameHealthz,
+			ContainerPort: int32(options.HealthzPort),
+			Protocol:      corev1.ProtocolTCP,
+		},
+	}
+
+	return ports
+}
+
+// flanneldArgs returns the arguments of the flanneld container. Values taken
+// from the custom object are escaped, see escapeArg.
+func flanneldArgs(customObject v1alpha1.FlannelConfig, etcdEndpoints []string, options Options, endpoints endpoints) []string {
+	args := []string{
+		"--etcd-endpoints=" + escapeArg(strings.Join(etcdEndpoints, ",")),
+		"--etcd-cafile=" + key.EtcdCAFilePath(),
+		"--etcd-certfile=" + key.EtcdCrtFilePath(),
+		"--etcd-keyfile=" + key.EtcdKeyFilePath(),
+		"--etcd-prefix=" + escapeArg(key.EtcdPrefix(customObject)),
+		"--iface=" + escapeArg(key.NetworkInterfaceName(customObject)),
+		"--subnet-file=" + escapeArg(key.NetworkEnvFilePath(customObject)),
+	}
+
+	return append(args, options.args(endpoints.ListenIP)...)
+}
+
+// podAnnotations returns the annotations of the network pods. The checksum of
+// the mounted etcd certificates is recorded, so that rotating them rolls the
+// network pods.
+func podAnnotations(certsChecksum string) map[string]string {
+	if certsChecksum == "" {
+		return nil
+	}
+
+	annotations := map[string]string{
+		key.AnnotationEtcdCertsChecksum: certsChecksum,
+	}
+
+	return annotations
+}
+
+func newDaemonSet(customObject v1alpha1.FlannelConfig, etcdEndpoints []string, options Options, probes probes, endpoints endpoints, certsChecksum string) *appsv1.DaemonSet {
+	daemonSet := &appsv1.DaemonSet{
 		TypeMeta: metav1.TypeMeta{
-			Kind:       "daemonset",
+			Kind:       "DaemonSet",
 			APIVersion: "apps/v1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
@@ -81,6 +214,7 @@ func newDaemonSet(customObject v1alpha1.FlannelConfig, etcdEndpoints []string, e
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					GenerateName: key.NetworkID,
+					Annotations:  podAnnotations(certsChecksum),
 					Labels: map[string]string{
 						"app":      key.NetworkID,
 						"cluster":  key.ClusterID(customObject),
@@ -96,22 +230,30 @@ func newDaemonSet(customObject v1alpha1.FlannelConfig, etcdEndpoints []string, e
 							Image:           key.FlannelDockerImage,
 							ImagePullPolicy: corev1.PullAlways,
 							Command: []string{
-								"/bin/sh",
-								"-c",
-								fmt.Sprintf("/opt/bin/flanneld --etcd-endpoints=%s --etcd-cafile=${ETCD_CA} --etcd-certfile=${ETCD_CRT} --etcd-keyfile=${ETCD_KEY} --etcd-prefix=${ETCD_PREFIX} --iface=${NETWORK_INTERFACE_NAME} --subnet-file=${NETWORK_ENV_FILE_PATH} -v=0", etcdEndpointsString),
+								"/opt/bin/flanneld",
 							},
+							Args:  flanneldArgs(customObject, etcdEndpoints, options, endpoints),
+							Ports: flanneldPorts(options),
 							Env: []corev1.EnvVar{
+								{
+									Name: envHostIP,
+									ValueFrom: &corev1.EnvVarSource{
+										FieldRef: &corev1.ObjectFieldSelector{
+											FieldPath: "status.hostIP",
+										},
+									},
+								},
 								{
 									Name:  "ETCD_CA",
-									Value: etcdCAFile,
+									Value: key.EtcdCAFilePath(),
 								},
 								{
 									Name:  "ETCD_CRT",
-									Value: etcdCrtFile,
+									Value: key.EtcdCrtFilePath(),
 								},
 								{
 									Name:  "ETCD_KEY",
-									Value: etcdKeyFile,
+									Value: key.EtcdKeyFilePath(),
 								},
 								{
 									Name:  "ETCD_PREFIX",
@@ -130,24 +272,13 @@ func newDaemonSet(customObject v1alpha1.FlannelConfig, etcdEndpoints []string, e
 									Value: key.NetworkInterfaceName(customObject),
 								},
 							},
-							LivenessProbe: &corev1.Probe{
-								InitialDelaySeconds: initialDelaySeconds,
-								TimeoutSeconds:      timeoutSeconds,
-								PeriodSeconds:       periodSeconds,
-								FailureThreshold:    failureThreshold,
-								SuccessThreshold:    successThreshold,
-								Handler: corev1.Handler{
-									HTTPGet: &corev1.HTTPGetAction{
-										Path: healthEndpoint,
-										Port: intstr.IntOrString{IntVal: livenessProbePort(customObject)},
-										Host: probeHost,
-									},
-								},
-							},
+							LivenessProbe:  newHTTPProbe(probes.Liveness, endpoints.ProbeHost, endpoints.HealthPort),
+							ReadinessProbe: flanneldReadinessProbe(probes.Readiness, options, endpoints),
 							VolumeMounts: []corev1.VolumeMount{
 								{
 									Name:      "etcd-certs",
-									MountPath: "/etc/kubernetes/ssl/etcd",
+									MountPath: key.EtcdCertsMountPath,
+									ReadOnly:  true,
 								},
 								{
 									Name:      "flannel",
@@ -205,20 +336,8 @@ func newDaemonSet(customObject v1alpha1.FlannelConfig, etcdEndpoints []string, e
 									Value: key.NetworkTapName(customObject),
 								},
 							},
-							LivenessProbe: &corev1.Probe{
-								InitialDelaySeconds: initialDelaySeconds,
-								TimeoutSeconds:      timeoutSeconds,
-								PeriodSeconds:       periodSeconds,
-								FailureThreshold:    failureThreshold,
-								SuccessThreshold:    successThreshold,
-								Handler: corev1.Handler{
-									HTTPGet: &corev1.HTTPGetAction{
-										Path: healthEndpoint,
-										Port: intstr.IntOrString{IntVal: livenessProbePort(customObject)},
-										Host: probeHost,
-									},
-								},
-							},
+							LivenessProbe:  newHTTPProbe(probes.Liveness, endpoints.ProbeHost, endpoints.HealthPort),
+							ReadinessProbe: newHTTPProbe(probes.Readiness, endpoints.ProbeHost, endpoints.HealthPort),
 							SecurityContext: &corev1.SecurityContext{
 								Privileged: &containersPrivileged,
 							},
@@ -257,10 +376,26 @@ func newDaemonSet(customObject v1alpha1.FlannelConfig, etcdEndpoints []string, e
 							Name:            "flannel-network-health",
 							Image:           key.NetworkHealthDockerImage(customObject),
 							ImagePullPolicy: corev1.PullAlways,
+							ReadinessProbe:  newHTTPProbe(probes.Readiness, endpoints.ProbeHost, endpoints.HealthPort),
+							Ports: []corev1.ContainerPort{
+								{
+									Name:          key.PortNameMetrics,
+									ContainerPort: int32(endpoints.HealthPort),
+									Protocol:      corev1.ProtocolTCP,
+								},
+							},
 							Env: []corev1.EnvVar{
+								{
+									Name: envHostIP,
+									ValueFrom: &corev1.EnvVarSource{
+										FieldRef: &corev1.ObjectFieldSelector{
+											FieldPath: "status.hostIP",
+										},
+									},
+								},
 								{
 									Name:  "LISTEN_ADDRESS",
-									Value: healthListenAddress(customObject),
+									Value: endpoints.healthListenAddress(),
 								},
 								{
 									Name:  "NETWORK_BRIDGE_NAME",
@@ -314,8 +449,8 @@ func newDaemonSet(customObject v1alpha1.FlannelConfig, etcdEndpoints []string, e
 						{
 							Name: "etcd-certs",
 							VolumeSource: corev1.VolumeSource{
-								HostPath: &corev1.HostPathVolumeSource{
-									Path: "/etc/kubernetes/ssl/etcd",
+								Secret: &corev1.SecretVolumeSource{
+									SecretName: key.EtcdCertsSecretName,
 								},
 							},
 						},
@@ -371,4 +506,7 @@ func newDaemonSet(customObject v1alpha1.FlannelConfig, etcdEndpoints []string, e
 			},
 		},
 	}
+	ownership.Set(daemonSet, customObject)
+
+	return daemonSet
 }
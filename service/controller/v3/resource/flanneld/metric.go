@@ -2,6 +2,8 @@ package flanneld
 
 import (
 	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/flannel-operator/pkg/metric"
 )
 
 const (
@@ -10,7 +12,7 @@ const (
 	VersionBundleVersionAnnotation = "giantswarm.io/version-bundle-version"
 )
 
-var versionBundleVersionGauge = prometheus.NewGaugeVec(
+var versionBundleVersionGauge = metric.MustRegisterGaugeVec(prometheus.NewGaugeVec(
 	prometheus.GaugeOpts{
 		Namespace: PrometheusNamespace,
 		Subsystem: PrometheusSubsystem,
@@ -18,8 +20,4 @@ var versionBundleVersionGauge = prometheus.NewGaugeVec(
 		Help:      "A metric labeled by major, minor and patch version of the version bundle being in use.",
 	},
 	[]string{"major", "minor", "patch"},
-)
-
-func init() {
-	prometheus.MustRegister(versionBundleVersionGauge)
-}
+))
@@ -0,0 +1,56 @@
+package flanneld
+
+import (
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Probe configures the timings of the liveness and readiness probes of the
+// network containers.
+type Probe struct {
+	FailureThreshold    int32
+	InitialDelaySeconds int32
+	PeriodSeconds       int32
+	SuccessThreshold    int32
+	TimeoutSeconds      int32
+}
+
+// Validate returns an invalidConfigError in case the probe timings would be
+// rejected by the Kubernetes API.
+func (p Probe) Validate() error {
+	if p.FailureThreshold < 1 {
+		return microerror.Maskf(invalidConfigError, "probe failure threshold must be greater than 0, got %d", p.FailureThreshold)
+	}
+	if p.InitialDelaySeconds < 0 {
+		return microerror.Maskf(invalidConfigError, "probe initial delay must not be negative, got %d", p.InitialDelaySeconds)
+	}
+	if p.PeriodSeconds < 1 {
+		return microerror.Maskf(invalidConfigError, "probe period must be greater than 0, got %d", p.PeriodSeconds)
+	}
+	if p.SuccessThreshold < 1 {
+		return microerror.Maskf(invalidConfigError, "probe success threshold must be greater than 0, got %d", p.SuccessThreshold)
+	}
+	if p.TimeoutSeconds < 1 {
+		return microerror.Maskf(invalidConfigError, "probe timeout must be greater than 0, got %d", p.TimeoutSeconds)
+	}
+
+	return nil
+}
+
+func newHTTPProbe(p Probe, host string, port int) *corev1.Probe {
+	return &corev1.Probe{
+		FailureThreshold:    p.FailureThreshold,
+		InitialDelaySeconds: p.InitialDelaySeconds,
+		PeriodSeconds:       p.PeriodSeconds,
+		SuccessThreshold:    p.SuccessThreshold,
+		TimeoutSeconds:      p.TimeoutSeconds,
+		Handler: corev1.Handler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: healthEndpoint,
+				Port: intstr.FromInt(port),
+				Host: host,
+			},
+		},
+	}
+}
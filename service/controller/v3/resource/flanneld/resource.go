@@ -5,6 +5,10 @@ import (
 	"github.com/giantswarm/micrologger"
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/client-go/kubernetes"
+
+	"github.com/giantswarm/flannel-operator/pkg/apply"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/portallocator"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/workloadgate"
 )
 
 const (
@@ -15,28 +19,45 @@ const (
 // Config represents the configuration used to create a new cloud config
 // resource.
 type Config struct {
+	Applier       apply.Interface
 	EtcdEndpoints []string
 	K8sClient     kubernetes.Interface
 	Logger        micrologger.Logger
+	PortAllocator portallocator.Interface
+	WorkloadGate  workloadgate.Interface
 
-	EtcdCAFile  string
-	EtcdCrtFile string
-	EtcdKeyFile string
+	// MonitoringEnabled exposes the health endpoints of the network containers
+	// on the host IP so that they can be scraped by Prometheus.
+	MonitoringEnabled bool
+	// LivenessProbe and ReadinessProbe configure the probes of the network
+	// containers.
+	LivenessProbe  Probe
+	ReadinessProbe Probe
+	// Options are the operator wide flanneld options. They can be overridden
+	// per tenant cluster, see Options.
+	Options Options
 }
 
 // Resource implements the cloud config resource.
 type Resource struct {
+	applier       apply.Interface
 	etcdEndpoints []string
 	k8sClient     kubernetes.Interface
 	logger        micrologger.Logger
+	portAllocator portallocator.Interface
+	workloadGate  workloadgate.Interface
 
-	etcdCAFile  string
-	etcdCrtFile string
-	etcdKeyFile string
+	livenessProbe     Probe
+	monitoringEnabled bool
+	readinessProbe    Probe
+	options           Options
 }
 
 // New creates a new configured cloud config resource.
 func New(config Config) (*Resource, error) {
+	if config.Applier == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Applier must not be empty", config)
+	}
 	if len(config.EtcdEndpoints) == 0 {
 		return nil, microerror.Maskf(invalidConfigError, "config.EtcdEndpoints must not be empty")
 	}
@@ -46,25 +67,42 @@ func New(config Config) (*Resource, error) {
 	if config.Logger == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
 	}
+	if config.PortAllocator == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.PortAllocator must not be empty", config)
+	}
+	if config.WorkloadGate == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.WorkloadGate must not be empty", config)
+	}
 
-	if config.EtcdCAFile == "" {
-		return nil, microerror.Maskf(invalidConfigError, "%T.EtcdCAFile must not be empty", config)
+	err := config.LivenessProbe.Validate()
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.LivenessProbe must be valid: %s", config, err)
+	}
+	// Kubernetes only accepts a success threshold of 1 for liveness probes.
+	if config.LivenessProbe.SuccessThreshold != 1 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.LivenessProbe.SuccessThreshold must be 1", config)
 	}
-	if config.EtcdCrtFile == "" {
-		return nil, microerror.Maskf(invalidConfigError, "%T.EtcdCrtFile must not be empty", config)
+	err = config.ReadinessProbe.Validate()
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ReadinessProbe must be valid: %s", config, err)
 	}
-	if config.EtcdKeyFile == "" {
-		return nil, microerror.Maskf(invalidConfigError, "%T.EtcdKeyFile must not be empty", config)
+	err = config.Options.Validate()
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Options must be valid: %s", config, err)
 	}
 
 	r := &Resource{
+		applier:       config.Applier,
 		etcdEndpoints: config.EtcdEndpoints,
 		k8sClient:     config.K8sClient,
 		logger:        config.Logger,
+		portAllocator: config.PortAllocator,
+		workloadGate:  config.WorkloadGate,
 
-		etcdCAFile:  config.EtcdCAFile,
-		etcdCrtFile: config.EtcdCrtFile,
-		etcdKeyFile: config.EtcdKeyFile,
+		livenessProbe:     config.LivenessProbe,
+		monitoringEnabled: config.MonitoringEnabled,
+		readinessProbe:    config.ReadinessProbe,
+		options:           config.Options,
 	}
 
 	return r, nil
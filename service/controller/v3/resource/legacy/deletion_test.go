@@ -0,0 +1,566 @@
+package legacy
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/controller/context/finalizerskeptcontext"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/giantswarm/flannel-operator/pkg/apply/applytest"
+	"github.com/giantswarm/flannel-operator/pkg/status"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/workloadgate"
+)
+
+func newNamespaceWithPhase(name string, phase corev1.NamespacePhase) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Status: corev1.NamespaceStatus{
+			Phase: phase,
+		},
+	}
+}
+
+func newNode(name string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+	}
+}
+
+func newCleanupJob(node string, attempt int, succeeded bool, failed bool) *batchv1.Job {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName(node, attempt),
+			Namespace: "flannel-destroyer-al9qy",
+		},
+	}
+	if succeeded {
+		job.Status.Succeeded = 1
+	}
+	if failed {
+		job.Status.Conditions = []batchv1.JobCondition{
+			{Type: batchv1.JobFailed, Status: corev1.ConditionTrue},
+		}
+	}
+
+	return job
+}
+
+func newCleanupPod(node string, attempt int, exitCode int32, message string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", jobName(node, attempt), "x7k2p"),
+			Namespace: "flannel-destroyer-al9qy",
+			Labels: map[string]string{
+				jobNameLabel: jobName(node, attempt),
+			},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name: cleanupContainerName,
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: exitCode,
+							Message:  message,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func Test_Resource_newDeleteChange(t *testing.T) {
+	testCases := []struct {
+		Name                   string
+		Phase                  string
+		CleanupNodes           []cleanupNode
+		Objects                []runtime.Object
+		ExpectedPhase          string
+		ExpectedCleanupNodes   []cleanupNode
+		ExpectedFinalizersKept bool
+		ExpectedJobs           []string
+		ExpectedBlocked        bool
+		ExpectedEvents         []string
+	}{
+		{
+			Name:  "case 0: teardown waits for pods",
+			Phase: "",
+			Objects: []runtime.Object{
+				&corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "master-1",
+						Namespace: "al9qy",
+					},
+				},
+			},
+			ExpectedPhase:          key.DeletionPhaseWaitingForPods,
+			ExpectedFinalizersKept: true,
+		},
+		{
+			Name:  "case 1: teardown waits for the network namespace",
+			Phase: key.DeletionPhaseWaitingForPods,
+			Objects: []runtime.Object{
+				newNamespaceWithPhase("flannel-network-al9qy", corev1.NamespaceTerminating),
+			},
+			ExpectedPhase:          key.DeletionPhaseWaitingForPods,
+			ExpectedFinalizersKept: true,
+		},
+		{
+			Name:                   "case 2: teardown advances once the namespaces are gone",
+			Phase:                  key.DeletionPhaseWaitingForPods,
+			Objects:                nil,
+			ExpectedPhase:          key.DeletionPhaseNamespacesDeleted,
+			ExpectedFinalizersKept: true,
+		},
+		{
+			Name:  "case 3: a cleanup job is scheduled on every node including cordoned ones",
+			Phase: key.DeletionPhaseNamespacesDeleted,
+			Objects: []runtime.Object{
+				newNode("node-a"),
+				&corev1.Node{
+					ObjectMeta: metav1.ObjectMeta{Name: "node-b"},
+					Spec:       corev1.NodeSpec{Unschedulable: true},
+				},
+			},
+			ExpectedPhase: key.DeletionPhaseCleanupScheduled,
+			ExpectedCleanupNodes: []cleanupNode{
+				{Name: "node-a", Attempts: 1, State: cleanupStatePending},
+				{Name: "node-b", Attempts: 1, State: cleanupStatePending},
+			},
+			ExpectedFinalizersKept: true,
+			ExpectedJobs: []string{
+				jobName("node-a", 1),
+				jobName("node-b", 1),
+			},
+		},
+		{
+			Name:  "case 4: cleanup is not scheduled while the destroyer namespace terminates",
+			Phase: key.DeletionPhaseNamespacesDeleted,
+			Objects: []runtime.Object{
+				newNamespaceWithPhase("flannel-destroyer-al9qy", corev1.NamespaceTerminating),
+			},
+			ExpectedPhase:          key.DeletionPhaseNamespacesDeleted,
+			ExpectedFinalizersKept: true,
+		},
+		{
+			Name:  "case 5: teardown waits for pending nodes",
+			Phase: key.DeletionPhaseCleanupScheduled,
+			CleanupNodes: []cleanupNode{
+				{Name: "node-a", Attempts: 1, State: cleanupStatePending},
+				{Name: "node-b", Attempts: 1, State: cleanupStatePending},
+			},
+			Objects: []runtime.Object{
+				newNamespaceWithPhase("flannel-destroyer-al9qy", corev1.NamespaceActive),
+				newNode("node-a"),
+				newNode("node-b"),
+				newCleanupJob("node-a", 1, true, false),
+				newCleanupJob("node-b", 1, false, false),
+			},
+			ExpectedPhase: key.DeletionPhaseCleanupScheduled,
+			ExpectedCleanupNodes: []cleanupNode{
+				{Name: "node-a", Attempts: 1, State: cleanupStateSucceeded},
+				{Name: "node-b", Attempts: 1, State: cleanupStatePending},
+			},
+			ExpectedFinalizersKept: true,
+			ExpectedJobs: []string{
+				jobName("node-a", 1),
+				jobName("node-b", 1),
+			},
+			ExpectedEvents: []string{
+				"Normal NetworkCleanupSucceeded",
+			},
+		},
+		{
+			Name:  "case 6: joined nodes are added and failed attempts are retried",
+			Phase: key.DeletionPhaseCleanupScheduled,
+			CleanupNodes: []cleanupNode{
+				{Name: "node-a", Attempts: 1, State: cleanupStatePending},
+			},
+			Objects: []runtime.Object{
+				newNamespaceWithPhase("flannel-destroyer-al9qy", corev1.NamespaceActive),
+				newNode("node-a"),
+				newNode("node-c"),
+				newCleanupJob("node-a", 1, false, true),
+			},
+			ExpectedPhase: key.DeletionPhaseCleanupScheduled,
+			ExpectedCleanupNodes: []cleanupNode{
+				{Name: "node-a", Attempts: 2, State: cleanupStatePending},
+				{Name: "node-c", Attempts: 1, State: cleanupStatePending},
+			},
+			ExpectedFinalizersKept: true,
+			ExpectedJobs: []string{
+				jobName("node-a", 1),
+				jobName("node-a", 2),
+				jobName("node-c", 1),
+			},
+			ExpectedEvents: []string{
+				"Warning NetworkCleanupRetried",
+			},
+		},
+		{
+			Name:  "case 7: nodes are reported failed after the last attempt",
+			Phase: key.DeletionPhaseCleanupScheduled,
+			CleanupNodes: []cleanupNode{
+				{Name: "node-a", Attempts: maxCleanupAttempts, State: cleanupStatePending},
+			},
+			Objects: []runtime.Object{
+				newNamespaceWithPhase("flannel-destroyer-al9qy", corev1.NamespaceActive),
+				newNode("node-a"),
+				newCleanupJob("node-a", maxCleanupAttempts, false, true),
+			},
+			ExpectedPhase: key.DeletionPhaseCleanupScheduled,
+			ExpectedCleanupNodes: []cleanupNode{
+				{Name: "node-a", Attempts: maxCleanupAttempts, State: cleanupStateFailed},
+			},
+			ExpectedFinalizersKept: true,
+			ExpectedJobs: []string{
+				jobName("node-a", maxCleanupAttempts),
+			},
+			ExpectedBlocked: true,
+			ExpectedEvents: []string{
+				"Warning NetworkCleanupFailed",
+				"Warning DeletionBlocked",
+			},
+		},
+		{
+			Name:  "case 8: teardown advances once all nodes succeeded or left",
+			Phase: key.DeletionPhaseCleanupScheduled,
+			CleanupNodes: []cleanupNode{
+				{Name: "node-a", Attempts: 1, State: cleanupStatePending},
+				{Name: "node-b", Attempts: 1, State: cleanupStatePending},
+			},
+			Objects: []runtime.Object{
+				newNamespaceWithPhase("flannel-destroyer-al9qy", corev1.NamespaceActive),
+				newNode("node-a"),
+				newCleanupJob("node-a", 1, true, false),
+			},
+			ExpectedPhase: key.DeletionPhaseCleanupDone,
+			ExpectedCleanupNodes: []cleanupNode{
+				{Name: "node-a", Attempts: 1, State: cleanupStateSucceeded},
+				{Name: "node-b", Attempts: 1, State: cleanupStateGone},
+			},
+			ExpectedFinalizersKept: true,
+			ExpectedJobs: []string{
+				jobName("node-a", 1),
+			},
+			ExpectedEvents: []string{
+				"Normal NetworkCleanupSucceeded",
+			},
+		},
+		{
+			Name:  "case 9: the result of failed attempts is recorded",
+			Phase: key.DeletionPhaseCleanupScheduled,
+			CleanupNodes: []cleanupNode{
+				{Name: "node-a", Attempts: 1, State: cleanupStatePending},
+			},
+			Objects: []runtime.Object{
+				newNamespaceWithPhase("flannel-destroyer-al9qy", corev1.NamespaceActive),
+				newNode("node-a"),
+				newCleanupJob("node-a", 1, false, true),
+				newCleanupPod("node-a", 1, 2, "Cannot find device \"br-al9qy\"\n"),
+			},
+			ExpectedPhase: key.DeletionPhaseCleanupScheduled,
+			ExpectedCleanupNodes: []cleanupNode{
+				{Name: "node-a", Attempts: 2, State: cleanupStatePending, ExitCode: int32Ptr(2), Message: "Cannot find device \"br-al9qy\""},
+			},
+			ExpectedFinalizersKept: true,
+			ExpectedJobs: []string{
+				jobName("node-a", 1),
+				jobName("node-a", 2),
+			},
+			ExpectedEvents: []string{
+				"Warning NetworkCleanupRetried",
+			},
+		},
+		{
+			Name:  "case 10: nodes failing the last attempt block the teardown",
+			Phase: key.DeletionPhaseCleanupScheduled,
+			CleanupNodes: []cleanupNode{
+				{Name: "node-a", Attempts: maxCleanupAttempts, State: cleanupStatePending},
+				{Name: "node-b", Attempts: 1, State: cleanupStateSucceeded},
+			},
+			Objects: []runtime.Object{
+				newNamespaceWithPhase("flannel-destroyer-al9qy", corev1.NamespaceActive),
+				newNode("node-a"),
+				newNode("node-b"),
+				newCleanupJob("node-a", maxCleanupAttempts, false, true),
+				newCleanupPod("node-a", maxCleanupAttempts, 1, "RTNETLINK answers: Operation not permitted"),
+			},
+			ExpectedPhase: key.DeletionPhaseCleanupScheduled,
+			ExpectedCleanupNodes: []cleanupNode{
+				{Name: "node-a", Attempts: maxCleanupAttempts, State: cleanupStateFailed, ExitCode: int32Ptr(1), Message: "RTNETLINK answers: Operation not permitted"},
+				{Name: "node-b", Attempts: 1, State: cleanupStateSucceeded},
+			},
+			ExpectedFinalizersKept: true,
+			ExpectedJobs: []string{
+				jobName("node-a", maxCleanupAttempts),
+			},
+			ExpectedBlocked: true,
+			ExpectedEvents: []string{
+				"Warning NetworkCleanupFailed",
+				"Warning DeletionBlocked",
+			},
+		},
+		{
+			Name:  "case 11: nodes removed from the cleanup nodes are retried from scratch",
+			Phase: key.DeletionPhaseCleanupScheduled,
+			CleanupNodes: []cleanupNode{
+				{Name: "node-b", Attempts: 1, State: cleanupStateSucceeded},
+			},
+			Objects: []runtime.Object{
+				newNamespaceWithPhase("flannel-destroyer-al9qy", corev1.NamespaceActive),
+				newNode("node-a"),
+				newNode("node-b"),
+				newCleanupJob("node-a", 1, false, true),
+				newCleanupJob("node-a", 2, false, true),
+				newCleanupJob("node-a", 3, false, true),
+			},
+			ExpectedPhase: key.DeletionPhaseCleanupScheduled,
+			ExpectedCleanupNodes: []cleanupNode{
+				{Name: "node-a", Attempts: 1, State: cleanupStatePending},
+				{Name: "node-b", Attempts: 1, State: cleanupStateSucceeded},
+			},
+			ExpectedFinalizersKept: true,
+			ExpectedJobs: []string{
+				jobName("node-a", 1),
+			},
+		},
+		{
+			Name:  "case 12: successful cleanups are reported",
+			Phase: key.DeletionPhaseCleanupScheduled,
+			CleanupNodes: []cleanupNode{
+				{Name: "node-a", Attempts: 2, State: cleanupStatePending, ExitCode: int32Ptr(1), Message: "timeout"},
+			},
+			Objects: []runtime.Object{
+				newNamespaceWithPhase("flannel-destroyer-al9qy", corev1.NamespaceActive),
+				newNode("node-a"),
+				newCleanupJob("node-a", 2, true, false),
+				newCleanupPod("node-a", 2, 0, ""),
+			},
+			ExpectedPhase: key.DeletionPhaseCleanupDone,
+			ExpectedCleanupNodes: []cleanupNode{
+				{Name: "node-a", Attempts: 2, State: cleanupStateSucceeded, ExitCode: int32Ptr(0)},
+			},
+			ExpectedFinalizersKept: true,
+			ExpectedJobs: []string{
+				jobName("node-a", 2),
+			},
+			ExpectedEvents: []string{
+				"Normal NetworkCleanupSucceeded",
+			},
+		},
+		{
+			Name:                   "case 13: untracked cleanup is scheduled again",
+			Phase:                  key.DeletionPhaseCleanupScheduled,
+			Objects:                nil,
+			ExpectedPhase:          key.DeletionPhaseNamespacesDeleted,
+			ExpectedFinalizersKept: true,
+		},
+		{
+			Name:  "case 14: teardown waits for the destroyer namespace",
+			Phase: key.DeletionPhaseCleanupDone,
+			Objects: []runtime.Object{
+				newNamespaceWithPhase("flannel-destroyer-al9qy", corev1.NamespaceTerminating),
+			},
+			ExpectedPhase:          key.DeletionPhaseCleanupDone,
+			ExpectedFinalizersKept: true,
+		},
+		{
+			Name:                   "case 15: teardown is finalized once the destroyer namespace is gone",
+			Phase:                  key.DeletionPhaseCleanupDone,
+			Objects:                nil,
+			ExpectedPhase:          key.DeletionPhaseFinalized,
+			ExpectedFinalizersKept: false,
+		},
+		{
+			Name:                   "case 16: finalized teardown releases the finalizers",
+			Phase:                  key.DeletionPhaseFinalized,
+			Objects:                nil,
+			ExpectedPhase:          key.DeletionPhaseFinalized,
+			ExpectedFinalizersKept: false,
+		},
+		{
+			Name:  "case 17: teardown does not wait for completed pods",
+			Phase: "",
+			Objects: []runtime.Object{
+				&corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "cleanup-job-x7k2p",
+						Namespace: "al9qy",
+					},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodSucceeded,
+					},
+				},
+			},
+			ExpectedPhase:          key.DeletionPhaseNamespacesDeleted,
+			ExpectedFinalizersKept: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			customObject := &v1alpha1.FlannelConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "al9qy",
+					Namespace:   "default",
+					Annotations: map[string]string{},
+				},
+				Spec: v1alpha1.FlannelConfigSpec{
+					Cluster: v1alpha1.FlannelConfigSpecCluster{
+						ID:        "al9qy",
+						Namespace: "al9qy",
+					},
+				},
+			}
+			if tc.Phase != "" {
+				customObject.Annotations[key.AnnotationDeletionPhase] = tc.Phase
+			}
+			if tc.CleanupNodes != nil {
+				v, err := cleanupNodesToAnnotation(tc.CleanupNodes)
+				if err != nil {
+					t.Fatal("expected", nil, "got", err)
+				}
+				customObject.Annotations[key.AnnotationCleanupNodes] = v
+			}
+
+			g8sClient := fake.NewSimpleClientset(customObject)
+			k8sClient := k8sfake.NewSimpleClientset(tc.Objects...)
+
+			var statusWriter status.Interface
+			{
+				c := status.Config{
+					G8sClient: g8sClient,
+					Logger:    microloggertest.New(),
+				}
+
+				w, err := status.New(c)
+				if err != nil {
+					t.Fatal("expected", nil, "got", err)
+				}
+				statusWriter = w
+			}
+
+			c := DefaultConfig()
+			recorder := record.NewFakeRecorder(10)
+
+			var workloadGate workloadgate.Interface
+			{
+				c := workloadgate.Config{
+					EventRecorder: recorder,
+					K8sClient:     k8sClient,
+					Logger:        microloggertest.New(),
+					StatusWriter:  statusWriter,
+				}
+
+				g, err := workloadgate.New(c)
+				if err != nil {
+					t.Fatal("expected", nil, "got", err)
+				}
+				workloadGate = g
+			}
+
+			c.Applier = applytest.New()
+			c.EventRecorder = recorder
+			c.K8sClient = k8sClient
+			c.Logger = microloggertest.New()
+			c.StatusWriter = statusWriter
+			c.WorkloadGate = workloadGate
+
+			r, err := New(c)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			ctx := context.Background()
+			ctx = finalizerskeptcontext.NewContext(ctx, make(chan struct{}))
+			ctx = reconciliationcanceledcontext.NewContext(ctx, make(chan struct{}))
+
+			_, err = r.newDeleteChange(ctx, customObject, nil, nil)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			updated, err := g8sClient.CoreV1alpha1().FlannelConfigs("default").Get("al9qy", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			if key.DeletionPhase(*updated) != tc.ExpectedPhase {
+				t.Fatalf("expected phase %#q got %#q", tc.ExpectedPhase, key.DeletionPhase(*updated))
+			}
+			if tc.ExpectedCleanupNodes != nil {
+				nodes, err := cleanupNodesFromAnnotation(*updated)
+				if err != nil {
+					t.Fatal("expected", nil, "got", err)
+				}
+				if !reflect.DeepEqual(nodes, tc.ExpectedCleanupNodes) {
+					t.Fatalf("expected cleanup nodes %#v got %#v", tc.ExpectedCleanupNodes, nodes)
+				}
+			}
+			if finalizerskeptcontext.IsKept(ctx) != tc.ExpectedFinalizersKept {
+				t.Fatalf("expected finalizers kept %t got %t", tc.ExpectedFinalizersKept, finalizerskeptcontext.IsKept(ctx))
+			}
+			if reconciliationcanceledcontext.IsCanceled(ctx) != tc.ExpectedFinalizersKept {
+				t.Fatalf("expected reconciliation canceled %t got %t", tc.ExpectedFinalizersKept, reconciliationcanceledcontext.IsCanceled(ctx))
+			}
+
+			_, blocked := updated.GetAnnotations()[key.AnnotationDeletionBlocked]
+			if blocked != tc.ExpectedBlocked {
+				t.Fatalf("expected deletion blocked %t got %t", tc.ExpectedBlocked, blocked)
+			}
+
+			close(recorder.Events)
+			var events []string
+			for e := range recorder.Events {
+				events = append(events, e)
+			}
+			if len(events) != len(tc.ExpectedEvents) {
+				t.Fatalf("expected events %v got %v", tc.ExpectedEvents, events)
+			}
+			for i, e := range tc.ExpectedEvents {
+				if !strings.HasPrefix(events[i], e+" ") {
+					t.Fatalf("expected event %#q got %#q", e, events[i])
+				}
+			}
+
+			jobs, err := k8sClient.BatchV1().Jobs("flannel-destroyer-al9qy").List(metav1.ListOptions{})
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			var names []string
+			for _, j := range jobs.Items {
+				names = append(names, j.GetName())
+			}
+			sort.Strings(names)
+			expectedJobs := append([]string(nil), tc.ExpectedJobs...)
+			sort.Strings(expectedJobs)
+			if !reflect.DeepEqual(names, expectedJobs) {
+				t.Fatalf("expected jobs %v got %v", expectedJobs, names)
+			}
+		})
+	}
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
@@ -28,3 +28,21 @@ var wrongTypeError = &microerror.Error{
 func IsWrongTypeError(err error) bool {
 	return microerror.Cause(err) == wrongTypeError
 }
+
+var unknownDeletionPhaseError = &microerror.Error{
+	Kind: "unknownDeletionPhaseError",
+}
+
+// IsUnknownDeletionPhase asserts unknownDeletionPhaseError.
+func IsUnknownDeletionPhase(err error) bool {
+	return microerror.Cause(err) == unknownDeletionPhaseError
+}
+
+var invalidAnnotationError = &microerror.Error{
+	Kind: "invalidAnnotationError",
+}
+
+// IsInvalidAnnotation asserts invalidAnnotationError.
+func IsInvalidAnnotation(err error) bool {
+	return microerror.Cause(err) == invalidAnnotationError
+}
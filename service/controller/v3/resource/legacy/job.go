@@ -1,15 +1,53 @@
 package legacy
 
 import (
+	"fmt"
+	"hash/fnv"
+
 	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
 	batchv1 "k8s.io/api/batch/v1"
 	apiv1 "k8s.io/api/core/v1"
 	apismetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/giantswarm/flannel-operator/pkg/ownership"
 	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
 )
 
-func newJob(customObject v1alpha1.FlannelConfig, replicas int32) *batchv1.Job {
+const (
+	// annotationNode holds the name of the node a bridge cleanup job runs on.
+	annotationNode = "flannel-operator.giantswarm.io/node"
+
+	// cleanupContainerName is the name of the container running
+	// docker-entrypoint.sh delete. Its termination state is reported as the
+	// result of a cleanup attempt.
+	cleanupContainerName = "k8s-network-bridge"
+
+	// jobNameLabel is the label the job controller puts on the pods of a job.
+	jobNameLabel = "job-name"
+
+	// jobActiveDeadlineSeconds limits the time a single cleanup attempt may
+	// take, e.g. in case the pod cannot start on a node which is not ready.
+	jobActiveDeadlineSeconds = int64(600)
+	// jobBackoffLimit is the number of pod retries within a single cleanup
+	// attempt.
+	jobBackoffLimit = int32(3)
+)
+
+// jobName returns the name of the bridge cleanup job of the given node and
+// attempt. Node names may exceed the length limit of job names, which is why
+// the node name is hashed.
+func jobName(node string, attempt int) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(node))
+
+	return fmt.Sprintf("%s-%08x-%d", destroyerApp, h.Sum32(), attempt)
+}
+
+// newJob returns the bridge cleanup job of the given node. The pod is bound to
+// the node directly, bypassing the scheduler, so that cordoned nodes are
+// cleaned up too. All taints are tolerated for the same reason. Every cleanup
+// attempt of a node gets its own job, see jobName.
+func newJob(customObject v1alpha1.FlannelConfig, node string, attempt int) *batchv1.Job {
 	privileged := true
 
 	app := destroyerApp
@@ -20,28 +58,47 @@ func newJob(customObject v1alpha1.FlannelConfig, replicas int32) *batchv1.Job {
 		"app":      app,
 	}
 
-	return &batchv1.Job{
+	parallelism := int32(1)
+	completions := int32(1)
+	backoffLimit := jobBackoffLimit
+	activeDeadlineSeconds := jobActiveDeadlineSeconds
+
+	job := &batchv1.Job{
 		TypeMeta: apismetav1.TypeMeta{
-			Kind:       "deployment",
-			APIVersion: "apps/v1",
+			Kind:       "Job",
+			APIVersion: "batch/v1",
 		},
 		ObjectMeta: apismetav1.ObjectMeta{
-			Name:   app,
+			Name:   jobName(node, attempt),
 			Labels: labels,
+			Annotations: map[string]string{
+				annotationNode: node,
+			},
 		},
 		Spec: batchv1.JobSpec{
-			Parallelism: &replicas,
-			Completions: &replicas,
+			Parallelism:           &parallelism,
+			Completions:           &completions,
+			BackoffLimit:          &backoffLimit,
+			ActiveDeadlineSeconds: &activeDeadlineSeconds,
 			Template: apiv1.PodTemplateSpec{
 				ObjectMeta: apismetav1.ObjectMeta{
 					GenerateName: app,
 					Labels:       labels,
 				},
 				Spec: apiv1.PodSpec{
+					NodeName:           node,
 					ServiceAccountName: serviceAccountName(customObject.Spec),
-					RestartPolicy:      apiv1.RestartPolicyOnFailure,
-					HostNetwork:        true,
-					HostPID:            true,
+					Tolerations: []apiv1.Toleration{
+						{
+							Operator: apiv1.TolerationOpExists,
+						},
+					},
+					// Failed containers are not restarted in place so that every
+					// failed pod keeps the exit code and termination message of
+					// its cleanup attempt.
+					RestartPolicy: apiv1.RestartPolicyNever,
+					HostNetwork:   true,
+					HostPID:       true,
 					Volumes: []apiv1.Volume{
 						{
 							Name: "cgroup",
@@ -118,7 +175,7 @@ func newJob(customObject v1alpha1.FlannelConfig, replicas int32) *batchv1.Job {
 					},
 					Containers: []apiv1.Container{
 						{
-							Name:            "k8s-network-bridge",
+							Name:            cleanupContainerName,
 							Image:           networkBridgeDockerImage(customObject.Spec),
 							ImagePullPolicy: apiv1.PullAlways,
 							Command: []string{
@@ -159,6 +216,7 @@ func newJob(customObject v1alpha1.FlannelConfig, replicas int32) *batchv1.Job {
 							SecurityContext: &apiv1.SecurityContext{
 								Privileged: &privileged,
 							},
+							TerminationMessagePolicy: apiv1.TerminationMessageFallbackToLogsOnError,
 							VolumeMounts: []apiv1.VolumeMount{
 								{
 									Name:      "cgroup",
@@ -195,4 +253,7 @@ func newJob(customObject v1alpha1.FlannelConfig, replicas int32) *batchv1.Job {
 			},
 		},
 	}
+	ownership.Set(job, customObject)
+
+	return job
 }
@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+
+	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
 )
 
 const (
@@ -13,7 +15,7 @@ const (
 	networkApp = "flannel-network"
 	// networkApp is the app label for resources cleaning flannel network
 	// and bridges.
-	destroyerApp = "flannel-destroyer"
+	destroyerApp = key.DestroyerID
 )
 
 // destroyerNamespace returns the namespace in which resources performing
@@ -26,22 +28,6 @@ func clusterID(spec v1alpha1.FlannelConfigSpec) string {
 	return spec.Cluster.ID
 }
 
-func clusterRoleBindingForDeletion(spec v1alpha1.FlannelConfigSpec) string {
-	return clusterID(spec) + "-deletion"
-}
-
-func clusterRoleBinding(spec v1alpha1.FlannelConfigSpec) string {
-	return networkApp + "-" + clusterID(spec)
-}
-
-func clusterRoleBindingForPodSecurityPolicy(spec v1alpha1.FlannelConfigSpec) string {
-	return networkApp + "-" + clusterID(spec) + "-psp"
-}
-
-func clusterRoleBindingForPodSecurityPolicyForDeletion(spec v1alpha1.FlannelConfigSpec) string {
-	return networkApp + "-" + clusterID(spec) + "-deletion-psp"
-}
-
 func flannelRunDir(spec v1alpha1.FlannelConfigSpec) string {
 	return spec.Flannel.Spec.RunDir
 }
@@ -5,13 +5,14 @@ import (
 	apiv1 "k8s.io/api/core/v1"
 	apismetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/giantswarm/flannel-operator/pkg/ownership"
 	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
 )
 
 // newNamespace creates a namespace with a given name. The created namespace
 // has a commont set of labels for this operator.
 func newNamespace(customObject v1alpha1.FlannelConfig, name string) *apiv1.Namespace {
-	return &apiv1.Namespace{
+	namespace := &apiv1.Namespace{
 		TypeMeta: apismetav1.TypeMeta{
 			Kind:       "Namespace",
 			APIVersion: "v1",
@@ -21,7 +22,12 @@ func newNamespace(customObject v1alpha1.FlannelConfig, name string) *apiv1.Names
 			Labels: map[string]string{
 				"cluster":  key.ClusterID(customObject),
 				"customer": key.ClusterCustomer(customObject),
+
+				key.LabelPodSecurityEnforce: key.LabelPodSecurityEnforceValue,
 			},
 		},
 	}
+	ownership.Set(namespace, customObject)
+
+	return namespace
 }
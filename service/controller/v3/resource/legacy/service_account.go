@@ -5,17 +5,19 @@ import (
 	api "k8s.io/api/core/v1"
 	apismeta "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/giantswarm/flannel-operator/pkg/ownership"
 	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
 )
 
-func newServiceAccount(customObject v1alpha1.FlannelConfig, name string) *api.ServiceAccount {
+func newServiceAccount(customObject v1alpha1.FlannelConfig, name, namespace string) *api.ServiceAccount {
 	serviceAccount := &api.ServiceAccount{
 		TypeMeta: apismeta.TypeMeta{
 			Kind:       "ServiceAccount",
 			APIVersion: "v1",
 		},
 		ObjectMeta: apismeta.ObjectMeta{
-			Name: name,
+			Name:      name,
+			Namespace: namespace,
 			Labels: map[string]string{
 				"app":         networkApp,
 				"cluster-id":  key.ClusterID(customObject),
@@ -23,6 +25,7 @@ func newServiceAccount(customObject v1alpha1.FlannelConfig, name string) *api.Se
 			},
 		},
 	}
+	ownership.Set(serviceAccount, customObject)
 
 	return serviceAccount
 }
@@ -0,0 +1,94 @@
+package monitoring
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/flannel-operator/pkg/served"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
+)
+
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	healthPort, err := r.portAllocator.HealthPort(ctx, customObject)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	flanneldHealthzPort, err := r.portAllocator.FlanneldHealthzPort(ctx, customObject)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "ensuring the monitoring service")
+
+		desired := newService(customObject, healthPort, flanneldHealthzPort)
+
+		current, err := r.k8sClient.CoreV1().Services(desired.GetNamespace()).Get(desired.GetName(), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			_, err = r.k8sClient.CoreV1().Services(desired.GetNamespace()).Create(desired)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		} else if err != nil {
+			return microerror.Mask(err)
+		} else {
+			current.Labels = desired.Labels
+			current.Spec.Ports = desired.Spec.Ports
+			current.Spec.Selector = desired.Spec.Selector
+
+			_, err = r.k8sClient.CoreV1().Services(desired.GetNamespace()).Update(current)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "ensured the monitoring service")
+	}
+
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "ensuring the service monitor")
+
+		isServed, err := served.IsServed(r.k8sClient.Discovery(), serviceMonitorResource)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		if !isServed {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "service monitor is not served by the Kubernetes API")
+			r.logger.LogCtx(ctx, "level", "debug", "message", "not ensuring the service monitor")
+			return nil
+		}
+
+		desired := newServiceMonitor(customObject)
+		client := r.dynClient.Resource(serviceMonitorResource).Namespace(desired.GetNamespace())
+
+		current, err := client.Get(desired.GetName(), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			_, err = client.Create(desired, metav1.CreateOptions{})
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		} else if err != nil {
+			return microerror.Mask(err)
+		} else {
+			desired.SetResourceVersion(current.GetResourceVersion())
+
+			_, err = client.Update(desired, metav1.UpdateOptions{})
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "ensured the service monitor")
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package monitoring
+
+import (
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/giantswarm/flannel-operator/service/controller/v3/portallocator"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "monitoringv3"
+)
+
+const (
+	metricsPath = "/metrics"
+)
+
+var (
+	serviceMonitorResource = schema.GroupVersionResource{
+		Group:    "monitoring.coreos.com",
+		Version:  "v1",
+		Resource: "servicemonitors",
+	}
+)
+
+// Config represents the configuration used to create a new monitoring
+// resource.
+type Config struct {
+	DynClient     dynamic.Interface
+	K8sClient     kubernetes.Interface
+	Logger        micrologger.Logger
+	PortAllocator portallocator.Interface
+}
+
+// Resource implements the monitoring resource. It manages a headless service
+// selecting the network pods of a tenant cluster and a ServiceMonitor
+// instructing the Prometheus operator to scrape them. The ServiceMonitor is
+// only managed in case the Prometheus operator CRDs are installed.
+type Resource struct {
+	dynClient     dynamic.Interface
+	k8sClient     kubernetes.Interface
+	logger        micrologger.Logger
+	portAllocator portallocator.Interface
+}
+
+// New creates a new configured monitoring resource.
+func New(config Config) (*Resource, error) {
+	if config.DynClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.DynClient must not be empty", config)
+	}
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.PortAllocator == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.PortAllocator must not be empty", config)
+	}
+
+	r := &Resource{
+		dynClient:     config.DynClient,
+		k8sClient:     config.K8sClient,
+		logger:        config.Logger,
+		portAllocator: config.PortAllocator,
+	}
+
+	return r, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
@@ -0,0 +1,59 @@
+package monitoring
+
+import (
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/giantswarm/flannel-operator/pkg/ownership"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
+)
+
+// newServiceMonitor returns a monitoring.coreos.com/v1 ServiceMonitor scraping
+// the metrics port of the network pods. The operator does not depend on the
+// Prometheus operator types, which is why the object is unstructured. The
+// cluster and customer labels of the service are added to all scraped series.
+func newServiceMonitor(customObject v1alpha1.FlannelConfig) *unstructured.Unstructured {
+	l := map[string]interface{}{}
+	for k, v := range labels(customObject) {
+		l[k] = v
+	}
+	s := map[string]interface{}{}
+	for k, v := range selector(customObject) {
+		s[k] = v
+	}
+
+	u := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": serviceMonitorResource.GroupVersion().String(),
+			"kind":       "ServiceMonitor",
+			"metadata": map[string]interface{}{
+				"name":      key.NetworkID,
+				"namespace": key.NetworkNamespace(customObject),
+				"labels":    l,
+			},
+			"spec": map[string]interface{}{
+				"endpoints": []interface{}{
+					map[string]interface{}{
+						"path": metricsPath,
+						"port": key.PortNameMetrics,
+					},
+				},
+				"namespaceSelector": map[string]interface{}{
+					"matchNames": []interface{}{
+						key.NetworkNamespace(customObject),
+					},
+				},
+				"selector": map[string]interface{}{
+					"matchLabels": s,
+				},
+				"targetLabels": []interface{}{
+					"cluster",
+					"customer",
+				},
+			},
+		},
+	}
+	ownership.Set(u, customObject)
+
+	return u
+}
@@ -0,0 +1,247 @@
+package namespace
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	apiv1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	apismetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/flannel-operator/pkg/ownership"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
+)
+
+const (
+	baselineName = "flannel-network-baseline"
+)
+
+// Baseline configures the objects managed alongside the network namespace.
+type Baseline struct {
+	// NetworkPolicy enables a default deny network policy for all pods in the
+	// network namespace. The network pods run in the host network and are not
+	// affected by it. Any other pod ending up in the namespace is contained.
+	NetworkPolicy bool
+	// ResourceQuota limits the resources of the network namespace. Empty values
+	// are not limited. Without any value no resource quota is managed.
+	ResourceQuota ResourceQuota
+	// LimitRange defines default resources of containers in the network
+	// namespace. Empty values have no default. Without any value no limit range
+	// is managed.
+	LimitRange LimitRange
+}
+
+type ResourceQuota struct {
+	CPU    string
+	Memory string
+	Pods   string
+}
+
+type LimitRange struct {
+	DefaultCPU           string
+	DefaultMemory        string
+	DefaultRequestCPU    string
+	DefaultRequestMemory string
+}
+
+// Validate returns an invalidConfigError in case any quantity cannot be parsed
+// or a CPU or memory quota is configured without the matching default limit.
+// The containers of the network pods do not declare any resources, so a quota
+// on limits without defaults would make admission reject all of them.
+func (b Baseline) Validate() error {
+	quantities := map[string]string{
+		"resource quota cpu":                 b.ResourceQuota.CPU,
+		"resource quota memory":              b.ResourceQuota.Memory,
+		"resource quota pods":                b.ResourceQuota.Pods,
+		"limit range default cpu":            b.LimitRange.DefaultCPU,
+		"limit range default memory":         b.LimitRange.DefaultMemory,
+		"limit range default request cpu":    b.LimitRange.DefaultRequestCPU,
+		"limit range default request memory": b.LimitRange.DefaultRequestMemory,
+	}
+
+	for name, q := range quantities {
+		if q == "" {
+			continue
+		}
+		_, err := resource.ParseQuantity(q)
+		if err != nil {
+			return microerror.Maskf(invalidConfigError, "%s must be a valid quantity, got %#q", name, q)
+		}
+	}
+
+	if b.ResourceQuota.CPU != "" && b.LimitRange.DefaultCPU == "" {
+		return microerror.Maskf(invalidConfigError, "resource quota cpu requires limit range default cpu")
+	}
+	if b.ResourceQuota.Memory != "" && b.LimitRange.DefaultMemory == "" {
+		return microerror.Maskf(invalidConfigError, "resource quota memory requires limit range default memory")
+	}
+
+	return nil
+}
+
+func toResourceList(m map[apiv1.ResourceName]string) apiv1.ResourceList {
+	l := apiv1.ResourceList{}
+	for k, v := range m {
+		if v != "" {
+			l[k] = resource.MustParse(v)
+		}
+	}
+
+	if len(l) == 0 {
+		return nil
+	}
+
+	return l
+}
+
+func newNetworkPolicy(customObject v1alpha1.FlannelConfig) *networkingv1.NetworkPolicy {
+	networkPolicy := &networkingv1.NetworkPolicy{
+		TypeMeta: apismetav1.TypeMeta{
+			Kind:       "NetworkPolicy",
+			APIVersion: "networking.k8s.io/v1",
+		},
+		ObjectMeta: apismetav1.ObjectMeta{
+			Name:      baselineName,
+			Namespace: key.NetworkNamespace(customObject),
+			Labels:    baselineLabels(customObject),
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: apismetav1.LabelSelector{},
+			PolicyTypes: []networkingv1.PolicyType{
+				networkingv1.PolicyTypeIngress,
+				networkingv1.PolicyTypeEgress,
+			},
+		},
+	}
+	ownership.Set(networkPolicy, customObject)
+
+	return networkPolicy
+}
+
+// newResourceQuota returns nil in case no quota is configured.
+func newResourceQuota(customObject v1alpha1.FlannelConfig, q ResourceQuota) *apiv1.ResourceQuota {
+	hard := toResourceList(map[apiv1.ResourceName]string{
+		apiv1.ResourceLimitsCPU:    q.CPU,
+		apiv1.ResourceLimitsMemory: q.Memory,
+		apiv1.ResourcePods:         q.Pods,
+	})
+	if hard == nil {
+		return nil
+	}
+
+	resourceQuota := &apiv1.ResourceQuota{
+		TypeMeta: apismetav1.TypeMeta{
+			Kind:       "ResourceQuota",
+			APIVersion: "v1",
+		},
+		ObjectMeta: apismetav1.ObjectMeta{
+			Name:      baselineName,
+			Namespace: key.NetworkNamespace(customObject),
+			Labels:    baselineLabels(customObject),
+		},
+		Spec: apiv1.ResourceQuotaSpec{
+			Hard: hard,
+		},
+	}
+	ownership.Set(resourceQuota, customObject)
+
+	return resourceQuota
+}
+
+// newLimitRange returns nil in case no defaults are configured.
+func newLimitRange(customObject v1alpha1.FlannelConfig, l LimitRange) *apiv1.LimitRange {
+	def := toResourceList(map[apiv1.ResourceName]string{
+		apiv1.ResourceCPU:    l.DefaultCPU,
+		apiv1.ResourceMemory: l.DefaultMemory,
+	})
+	defRequest := toResourceList(map[apiv1.ResourceName]string{
+		apiv1.ResourceCPU:    l.DefaultRequestCPU,
+		apiv1.ResourceMemory: l.DefaultRequestMemory,
+	})
+	if def == nil && defRequest == nil {
+		return nil
+	}
+
+	limitRange := &apiv1.LimitRange{
+		TypeMeta: apismetav1.TypeMeta{
+			Kind:       "LimitRange",
+			APIVersion: "v1",
+		},
+		ObjectMeta: apismetav1.ObjectMeta{
+			Name:      baselineName,
+			Namespace: key.NetworkNamespace(customObject),
+			Labels:    baselineLabels(customObject),
+		},
+		Spec: apiv1.LimitRangeSpec{
+			Limits: []apiv1.LimitRangeItem{
+				{
+					Type:           apiv1.LimitTypeContainer,
+					Default:        def,
+					DefaultRequest: defRequest,
+				},
+			},
+		},
+	}
+	ownership.Set(limitRange, customObject)
+
+	return limitRange
+}
+
+func baselineLabels(customObject v1alpha1.FlannelConfig) map[string]string {
+	return map[string]string{
+		"cluster":  key.ClusterID(customObject),
+		"customer": key.ClusterCustomer(customObject),
+	}
+}
+
+// ensureBaseline applies the configured baseline objects to the network
+// namespace of the given custom object. Objects which are disabled are
+// deleted, so that changes of the configuration are reflected in existing
+// namespaces.
+func (r *Resource) ensureBaseline(ctx context.Context, customObject v1alpha1.FlannelConfig) error {
+	namespace := key.NetworkNamespace(customObject)
+
+	if r.baseline.NetworkPolicy {
+		err := r.applier.Apply(ctx, newNetworkPolicy(customObject))
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	} else {
+		err := r.k8sClient.NetworkingV1().NetworkPolicies(namespace).Delete(baselineName, &apismetav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return microerror.Mask(err)
+		}
+	}
+
+	if q := newResourceQuota(customObject, r.baseline.ResourceQuota); q != nil {
+		err := r.applier.Apply(ctx, q)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	} else {
+		err := r.k8sClient.CoreV1().ResourceQuotas(namespace).Delete(baselineName, &apismetav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return microerror.Mask(err)
+		}
+	}
+
+	if l := newLimitRange(customObject, r.baseline.LimitRange); l != nil {
+		err := r.applier.Apply(ctx, l)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	} else {
+		err := r.k8sClient.CoreV1().LimitRanges(namespace).Delete(baselineName, &apismetav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return microerror.Mask(err)
+		}
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("ensured the baseline objects of namespace %#q", namespace))
+
+	return nil
+}
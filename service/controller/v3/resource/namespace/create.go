@@ -5,7 +5,8 @@ import (
 
 	"github.com/giantswarm/microerror"
 	apiv1 "k8s.io/api/core/v1"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
 )
 
 func (r *Resource) ApplyCreateChange(ctx context.Context, obj, createChange interface{}) error {
@@ -17,14 +18,22 @@ func (r *Resource) ApplyCreateChange(ctx context.Context, obj, createChange inte
 	if namespaceToCreate != nil {
 		r.logger.LogCtx(ctx, "level", "debug", "message", "creating the namespace in the Kubernetes API")
 
-		_, err = r.k8sClient.CoreV1().Namespaces().Create(namespaceToCreate)
-		if apierrors.IsAlreadyExists(err) {
-			// fall through
-		} else if err != nil {
+		err = r.applier.Apply(ctx, namespaceToCreate)
+		if err != nil {
 			return microerror.Mask(err)
 		}
 
 		r.logger.LogCtx(ctx, "level", "debug", "message", "created the namespace in the Kubernetes API")
+
+		customObject, err := key.ToCustomObject(obj)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		err = r.ensureBaseline(ctx, customObject)
+		if err != nil {
+			return microerror.Mask(err)
+		}
 	} else {
 		r.logger.LogCtx(ctx, "level", "debug", "message", "the namespace does not need to be created in the Kubernetes API")
 	}
@@ -11,6 +11,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/giantswarm/flannel-operator/pkg/ownership"
 	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
 )
 
@@ -36,35 +37,53 @@ func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interf
 		}
 	}
 
+	// The namespace might be owned by another FlannelConfig or might not be
+	// managed by the operator at all. We must not touch it then.
+	if namespace != nil && ownership.IsForeign(namespace, customObject) {
+		r.logger.LogCtx(ctx, "level", "warning", "message", "namespace is not owned by this FlannelConfig")
+
+		resourcecanceledcontext.SetCanceled(ctx)
+		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+
+		return nil, nil
+	}
+
 	// In case the namespace is already terminating we do not need to do any
 	// further work. Then we cancel the reconciliation to prevent the current and
-	// any further resource from being processed.
-	if namespace != nil && namespace.Status.Phase == "Terminating" {
+	// any further resource from being processed. On deletion only this resource
+	// is canceled. The legacy resource after it has to run, since its deletion
+	// phases decide when the finalizers are released. Canceling the
+	// reconciliation here would release them before the network cleanup ran.
+	if namespace != nil && namespace.Status.Phase == corev1.NamespaceTerminating {
 		r.logger.LogCtx(ctx, "level", "debug", "message", "namespace is in state 'Terminating'")
 
-		reconciliationcanceledcontext.SetCanceled(ctx)
-		if reconciliationcanceledcontext.IsCanceled(ctx) {
-			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling reconciliation")
+		if key.IsDeleted(customObject) {
+			resourcecanceledcontext.SetCanceled(ctx)
+			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
 
 			return nil, nil
 		}
+
+		reconciliationcanceledcontext.SetCanceled(ctx)
+		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling reconciliation")
+
+		return nil, nil
 	}
 
 	// In case a cluster deletion happens, we want to delete the guest cluster
 	// namespace. We still need to use the namespace for resource creation in
-	// order to drain nodes on KVM though. So as long as pods are there we delay
-	// the deletion of the namespace here in order to still be able to create
-	// resources in it. As soon as the draining was done and the pods got removed
-	// we get an empty list here after the delete event got replayed. Then we just
-	// remove the namespace as usual.
+	// order to drain nodes on KVM though. So as long as the workload gate holds
+	// the deletion we delay the deletion of the namespace here in order to still
+	// be able to create resources in it. As soon as the draining was done and the
+	// workloads got removed, the gate opens after the delete event got replayed.
+	// Then we just remove the namespace as usual.
 	if key.IsDeleted(customObject) {
-		n := key.ClusterNamespace(customObject)
-		list, err := r.k8sClient.CoreV1().Pods(n).List(metav1.ListOptions{})
+		wait, err := r.workloadGate.Wait(ctx, customObject)
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
-		if len(list.Items) != 0 {
-			r.logger.LogCtx(ctx, "level", "debug", "message", "cannot finish deletion of namespace due to existing pods")
+		if wait {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "cannot finish deletion of namespace due to existing workloads")
 			resourcecanceledcontext.SetCanceled(ctx)
 			finalizerskeptcontext.SetKept(ctx)
 			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
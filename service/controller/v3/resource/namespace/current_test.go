@@ -7,48 +7,168 @@ import (
 
 	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
 	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/controller/context/finalizerskeptcontext"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+	"github.com/giantswarm/operatorkit/controller/context/resourcecanceledcontext"
 	apiv1 "k8s.io/api/core/v1"
+	apismetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/flannel-operator/pkg/apply/applytest"
+	"github.com/giantswarm/flannel-operator/pkg/ownership"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/workloadgate/workloadgatetest"
 )
 
 func Test_Resource_Namespace_GetCurrentState(t *testing.T) {
+	customObject := &v1alpha1.FlannelConfig{
+		ObjectMeta: apismetav1.ObjectMeta{
+			Name:      "al9qy",
+			Namespace: "default",
+			UID:       "uid-1",
+		},
+		Spec: v1alpha1.FlannelConfigSpec{
+			Cluster: v1alpha1.FlannelConfigSpecCluster{
+				ID: "al9qy",
+			},
+		},
+	}
+
+	ownedNamespace := &apiv1.Namespace{
+		ObjectMeta: apismetav1.ObjectMeta{
+			Name:   "flannel-network-al9qy",
+			Labels: ownership.Labels(*customObject),
+		},
+	}
+
+	foreignNamespace := &apiv1.Namespace{
+		ObjectMeta: apismetav1.ObjectMeta{
+			Name: "flannel-network-al9qy",
+			Labels: map[string]string{
+				ownership.LabelManagedBy: ownership.LabelManagedByValue,
+				ownership.LabelOwnerUID:  "uid-2",
+			},
+		},
+	}
+
+	terminatingNamespace := ownedNamespace.DeepCopy()
+	terminatingNamespace.Status.Phase = apiv1.NamespaceTerminating
+
 	testCases := []struct {
-		Obj               interface{}
-		ExpectedNamespace *apiv1.Namespace
+		Name                   string
+		Deleted                bool
+		WorkloadsLeft          bool
+		Objects                []runtime.Object
+		ExpectedNamespace      *apiv1.Namespace
+		ExpectedFinalizersKept bool
+		ExpectedCanceled       bool
 	}{
 		{
-			Obj: &v1alpha1.FlannelConfig{
-				Spec: v1alpha1.FlannelConfigSpec{
-					Cluster: v1alpha1.FlannelConfigSpecCluster{
-						ID: "al9qy",
-					},
-				},
+			Name:              "case 0: no namespace exists",
+			Objects:           nil,
+			ExpectedNamespace: nil,
+		},
+		{
+			Name: "case 1: owned namespace is returned",
+			Objects: []runtime.Object{
+				ownedNamespace,
+			},
+			ExpectedNamespace: ownedNamespace,
+		},
+		{
+			Name: "case 2: foreign namespace is ignored",
+			Objects: []runtime.Object{
+				foreignNamespace,
 			},
 			ExpectedNamespace: nil,
 		},
+		{
+			Name:          "case 3: deletion waits for workloads",
+			Deleted:       true,
+			WorkloadsLeft: true,
+			Objects: []runtime.Object{
+				ownedNamespace,
+			},
+			ExpectedNamespace:      nil,
+			ExpectedFinalizersKept: true,
+		},
+		{
+			Name:          "case 4: deletion proceeds without workloads",
+			Deleted:       true,
+			WorkloadsLeft: false,
+			Objects: []runtime.Object{
+				ownedNamespace,
+			},
+			ExpectedNamespace:      ownedNamespace,
+			ExpectedFinalizersKept: false,
+		},
+		{
+			Name: "case 5: terminating namespace cancels the reconciliation",
+			Objects: []runtime.Object{
+				terminatingNamespace,
+			},
+			ExpectedNamespace: nil,
+			ExpectedCanceled:  true,
+		},
+		{
+			Name:    "case 6: terminating namespace does not cancel the deletion",
+			Deleted: true,
+			Objects: []runtime.Object{
+				terminatingNamespace,
+			},
+			ExpectedNamespace: nil,
+			ExpectedCanceled:  false,
+		},
 	}
 
-	var err error
-	var newResource *Resource
-	{
-		c := Config{
-			K8sClient: fake.NewSimpleClientset(),
-			Logger:    microloggertest.New(),
-		}
-
-		newResource, err = New(c)
-		if err != nil {
-			t.Fatal("expected", nil, "got", err)
-		}
-	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var err error
+
+			var newResource *Resource
+			{
+				c := Config{
+					Applier:      applytest.New(),
+					K8sClient:    fake.NewSimpleClientset(tc.Objects...),
+					Logger:       microloggertest.New(),
+					WorkloadGate: workloadgatetest.New(tc.WorkloadsLeft),
+				}
+
+				newResource, err = New(c)
+				if err != nil {
+					t.Fatal("expected", nil, "got", err)
+				}
+			}
+
+			obj := customObject.DeepCopy()
+			if tc.Deleted {
+				now := apismetav1.Now()
+				obj.SetDeletionTimestamp(&now)
+			}
+
+			ctx := finalizerskeptcontext.NewContext(context.Background(), make(chan struct{}))
+			ctx = resourcecanceledcontext.NewContext(ctx, make(chan struct{}))
+			ctx = reconciliationcanceledcontext.NewContext(ctx, make(chan struct{}))
 
-	for i, tc := range testCases {
-		result, err := newResource.GetCurrentState(context.TODO(), tc.Obj)
-		if err != nil {
-			t.Fatal("case", i+1, "expected", nil, "got", err)
-		}
-		if !reflect.DeepEqual(tc.ExpectedNamespace, result) {
-			t.Fatalf("case %d expected %#v got %#v", i+1, tc.ExpectedNamespace, result)
-		}
+			result, err := newResource.GetCurrentState(ctx, obj)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			if finalizerskeptcontext.IsKept(ctx) != tc.ExpectedFinalizersKept {
+				t.Fatalf("expected finalizers kept %t got %t", tc.ExpectedFinalizersKept, finalizerskeptcontext.IsKept(ctx))
+			}
+			if reconciliationcanceledcontext.IsCanceled(ctx) != tc.ExpectedCanceled {
+				t.Fatalf("expected reconciliation canceled %t got %t", tc.ExpectedCanceled, reconciliationcanceledcontext.IsCanceled(ctx))
+			}
+			if tc.ExpectedNamespace == nil {
+				if n, ok := result.(*apiv1.Namespace); ok && n != nil {
+					t.Fatalf("expected nil got %#v", result)
+				}
+				return
+			}
+			if !reflect.DeepEqual(tc.ExpectedNamespace, result) {
+				t.Fatalf("expected %#v got %#v", tc.ExpectedNamespace, result)
+			}
+		})
 	}
 }
@@ -9,6 +9,9 @@ import (
 	apiv1 "k8s.io/api/core/v1"
 	apismetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/flannel-operator/pkg/apply/applytest"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/workloadgate/workloadgatetest"
 )
 
 func Test_Resource_Namespace_newDeleteChange(t *testing.T) {
@@ -97,8 +100,10 @@ func Test_Resource_Namespace_newDeleteChange(t *testing.T) {
 	var newResource *Resource
 	{
 		c := Config{
-			K8sClient: fake.NewSimpleClientset(),
-			Logger:    microloggertest.New(),
+			Applier:      applytest.New(),
+			K8sClient:    fake.NewSimpleClientset(),
+			Logger:       microloggertest.New(),
+			WorkloadGate: workloadgatetest.New(false),
 		}
 
 		newResource, err = New(c)
@@ -7,6 +7,7 @@ import (
 	apiv1 "k8s.io/api/core/v1"
 	apismetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/giantswarm/flannel-operator/pkg/ownership"
 	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
 )
 
@@ -28,9 +29,12 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 			Labels: map[string]string{
 				"cluster":  key.ClusterID(customObject),
 				"customer": key.ClusterCustomer(customObject),
+
+				key.LabelPodSecurityEnforce: key.LabelPodSecurityEnforceValue,
 			},
 		},
 	}
+	ownership.Set(namespace, customObject)
 
 	r.logger.LogCtx(ctx, "level", "debug", "message", "computed the desired namespace")
 
@@ -8,6 +8,9 @@ import (
 	"github.com/giantswarm/micrologger/microloggertest"
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/flannel-operator/pkg/apply/applytest"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/workloadgate/workloadgatetest"
 )
 
 func Test_Resource_Namespace_GetDesiredState(t *testing.T) {
@@ -41,8 +44,10 @@ func Test_Resource_Namespace_GetDesiredState(t *testing.T) {
 	var newResource *Resource
 	{
 		c := Config{
-			K8sClient: fake.NewSimpleClientset(),
-			Logger:    microloggertest.New(),
+			Applier:      applytest.New(),
+			K8sClient:    fake.NewSimpleClientset(),
+			Logger:       microloggertest.New(),
+			WorkloadGate: workloadgatetest.New(false),
 		}
 
 		newResource, err = New(c)
@@ -60,5 +65,9 @@ func Test_Resource_Namespace_GetDesiredState(t *testing.T) {
 		if tc.ExpectedName != name {
 			t.Fatalf("case %d expected %#v got %#v", i+1, tc.ExpectedName, name)
 		}
+		label := result.(*apiv1.Namespace).Labels["pod-security.kubernetes.io/enforce"]
+		if label != "privileged" {
+			t.Fatalf("case %d expected %#v got %#v", i+1, "privileged", label)
+		}
 	}
 }
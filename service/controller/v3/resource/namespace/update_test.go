@@ -0,0 +1,103 @@
+package namespace
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	apiv1 "k8s.io/api/core/v1"
+	apismetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/flannel-operator/pkg/apply/applytest"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/workloadgate/workloadgatetest"
+)
+
+func Test_Resource_Namespace_newUpdateChange(t *testing.T) {
+	desired := &apiv1.Namespace{
+		TypeMeta: apismetav1.TypeMeta{
+			Kind:       "Namespace",
+			APIVersion: "v1",
+		},
+		ObjectMeta: apismetav1.ObjectMeta{
+			Name: "flannel-network-al9qy",
+			Labels: map[string]string{
+				"cluster":  "al9qy",
+				"customer": "test-customer",
+			},
+		},
+	}
+
+	testCases := []struct {
+		Name              string
+		Cur               interface{}
+		Des               interface{}
+		ExpectedNamespace *apiv1.Namespace
+	}{
+		{
+			Name:              "case 0: missing namespaces are not updated",
+			Cur:               nil,
+			Des:               desired,
+			ExpectedNamespace: nil,
+		},
+		{
+			Name: "case 1: drifted namespaces are applied",
+			Cur: &apiv1.Namespace{
+				ObjectMeta: apismetav1.ObjectMeta{
+					Name: "flannel-network-al9qy",
+					Labels: map[string]string{
+						"cluster": "foo",
+						"other":   "bar",
+					},
+				},
+			},
+			Des:               desired,
+			ExpectedNamespace: desired,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			applier := applytest.New()
+
+			c := Config{
+				Applier:      applier,
+				K8sClient:    fake.NewSimpleClientset(),
+				Logger:       microloggertest.New(),
+				WorkloadGate: workloadgatetest.New(false),
+			}
+
+			r, err := New(c)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			obj := &v1alpha1.FlannelConfig{}
+
+			result, err := r.newUpdateChange(context.TODO(), obj, tc.Cur, tc.Des)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			namespace, err := toNamespace(result)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			if !reflect.DeepEqual(tc.ExpectedNamespace, namespace) {
+				t.Fatalf("expected %#v got %#v", tc.ExpectedNamespace, namespace)
+			}
+
+			err = r.ApplyUpdateChange(context.TODO(), obj, result)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			if tc.ExpectedNamespace != nil && len(applier.Applied()) != 1 {
+				t.Fatalf("expected namespace to be applied, got %d applied objects", len(applier.Applied()))
+			}
+			if tc.ExpectedNamespace == nil && len(applier.Applied()) != 0 {
+				t.Fatalf("expected nothing to be applied, got %d applied objects", len(applier.Applied()))
+			}
+		})
+	}
+}
@@ -21,12 +21,17 @@ func (r *Resource) ApplyCreateChange(ctx context.Context, obj, createChange inte
 
 	var emptyNetworkConfig NetworkConfig
 	if networkConfigToCreate != emptyNetworkConfig {
+		store, err := r.storeFor(ctx, customObject)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
 		b, err := json.Marshal(networkConfigToCreate)
 		if err != nil {
 			return microerror.Mask(err)
 		}
 		p := key.EtcdNetworkConfigPath(customObject)
-		err = r.store.Create(ctx, p, string(b))
+		err = store.Create(ctx, p, string(b))
 		if err != nil {
 			return microerror.Mask(err)
 		}
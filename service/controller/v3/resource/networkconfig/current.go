@@ -16,10 +16,18 @@ func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interf
 		return nil, microerror.Mask(err)
 	}
 
+	store, err := r.storeFor(ctx, customObject)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if store == nil {
+		return NetworkConfig{}, nil
+	}
+
 	var networkConfig NetworkConfig
 	{
 		p := key.EtcdNetworkConfigPath(customObject)
-		s, err := r.store.Search(ctx, p)
+		s, err := store.Search(ctx, p)
 		if etcd.IsNotFound(err) {
 			// fall through
 		} else if err != nil {
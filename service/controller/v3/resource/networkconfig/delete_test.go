@@ -7,8 +7,13 @@ import (
 
 	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
 	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/controller/context/finalizerskeptcontext"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
 
+	"github.com/giantswarm/flannel-operator/service/controller/v3/etcd"
 	etcdfake "github.com/giantswarm/flannel-operator/service/controller/v3/etcd/fake"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
 )
 
 func Test_Resource_NetworkConfig_newDeleteChange(t *testing.T) {
@@ -112,14 +117,40 @@ func Test_Resource_NetworkConfig_newDeleteChange(t *testing.T) {
 				},
 			},
 		},
+
+		// Test 4 ensures that the delete state matches the desired state in case
+		// the current state is empty. Subnet leases may be left without network
+		// config.
+		{
+			Obj:          &v1alpha1.FlannelConfig{},
+			CurrentState: NetworkConfig{},
+			DesiredState: NetworkConfig{
+				Network:   "172.26.0.0/16",
+				SubnetLen: 30,
+				Backend: Backend{
+					Type: "vxlan",
+					VNI:  26,
+				},
+			},
+			ExpectedNetworkConfig: NetworkConfig{
+				Network:   "172.26.0.0/16",
+				SubnetLen: 30,
+				Backend: Backend{
+					Type: "vxlan",
+					VNI:  26,
+				},
+			},
+		},
 	}
 
 	var err error
 	var newResource *Resource
 	{
 		c := Config{
-			Logger: microloggertest.New(),
-			Store:  etcdfake.New(),
+			K8sClient:  k8sfake.NewSimpleClientset(),
+			Logger:     microloggertest.New(),
+			Store:      etcdfake.New(),
+			StoreCache: etcd.NewStoreCache(etcd.NewStore),
 		}
 
 		newResource, err = New(c)
@@ -145,3 +176,134 @@ func Test_Resource_NetworkConfig_newDeleteChange(t *testing.T) {
 		})
 	}
 }
+
+// testStore is an etcd store which records deleted keys. Exists reports the
+// configured result for every key.
+type testStore struct {
+	etcdfake.Fake
+
+	deleted []string
+	exists  bool
+}
+
+func (s *testStore) Delete(ctx context.Context, key string) error {
+	s.deleted = append(s.deleted, key)
+	return nil
+}
+
+func (s *testStore) Exists(ctx context.Context, key string) (bool, error) {
+	return s.exists, nil
+}
+
+func Test_Resource_NetworkConfig_EnsureDeleted(t *testing.T) {
+	testCases := []struct {
+		Name                   string
+		Phase                  string
+		EtcdSecret             string
+		Exists                 bool
+		ExpectedDeleted        []string
+		ExpectedFinalizersKept bool
+	}{
+		{
+			Name:                   "case 0: network state is kept while flanneld runs",
+			Phase:                  "",
+			ExpectedDeleted:        nil,
+			ExpectedFinalizersKept: true,
+		},
+		{
+			Name:                   "case 1: network state is kept during the bridge cleanup",
+			Phase:                  key.DeletionPhaseCleanupScheduled,
+			ExpectedDeleted:        nil,
+			ExpectedFinalizersKept: true,
+		},
+		{
+			Name:                   "case 2: network state is deleted after the bridge cleanup",
+			Phase:                  key.DeletionPhaseCleanupDone,
+			ExpectedDeleted:        []string{"coreos.com/network/br-al9qy"},
+			ExpectedFinalizersKept: false,
+		},
+		{
+			Name:                   "case 3: finalizers are kept while the network path exists",
+			Phase:                  key.DeletionPhaseFinalized,
+			Exists:                 true,
+			ExpectedDeleted:        []string{"coreos.com/network/br-al9qy"},
+			ExpectedFinalizersKept: true,
+		},
+		{
+			Name:                   "case 4: network state is skipped without the etcd secret",
+			Phase:                  key.DeletionPhaseCleanupDone,
+			EtcdSecret:             "al9qy-etcd",
+			ExpectedDeleted:        nil,
+			ExpectedFinalizersKept: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			customObject := &v1alpha1.FlannelConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "al9qy",
+					Namespace:         "default",
+					Annotations:       map[string]string{},
+					DeletionTimestamp: &metav1.Time{},
+				},
+				Spec: v1alpha1.FlannelConfigSpec{
+					Cluster: v1alpha1.FlannelConfigSpecCluster{
+						ID: "al9qy",
+					},
+				},
+			}
+			if tc.Phase != "" {
+				customObject.Annotations[key.AnnotationDeletionPhase] = tc.Phase
+			}
+			if tc.EtcdSecret != "" {
+				customObject.Annotations[key.AnnotationEtcdSecret] = tc.EtcdSecret
+			}
+
+			store := &testStore{
+				exists: tc.Exists,
+			}
+
+			c := Config{
+				K8sClient:  k8sfake.NewSimpleClientset(),
+				Logger:     microloggertest.New(),
+				Store:      store,
+				StoreCache: etcd.NewStoreCache(etcd.NewStore),
+			}
+
+			r, err := New(c)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			ctx := finalizerskeptcontext.NewContext(context.Background(), make(chan struct{}))
+
+			desiredState := NetworkConfig{
+				Network:   "172.26.0.0/16",
+				SubnetLen: 30,
+				Backend: Backend{
+					Type: "vxlan",
+					VNI:  26,
+				},
+			}
+
+			patch, err := r.NewDeletePatch(ctx, customObject, NetworkConfig{}, desiredState)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			if patch != nil {
+				err = r.ApplyDeleteChange(ctx, customObject, desiredState)
+				if err != nil {
+					t.Fatal("expected", nil, "got", err)
+				}
+			}
+
+			if !reflect.DeepEqual(store.deleted, tc.ExpectedDeleted) {
+				t.Fatalf("expected deleted %v got %v", tc.ExpectedDeleted, store.deleted)
+			}
+			if finalizerskeptcontext.IsKept(ctx) != tc.ExpectedFinalizersKept {
+				t.Fatalf("expected finalizers kept %t got %t", tc.ExpectedFinalizersKept, finalizerskeptcontext.IsKept(ctx))
+			}
+		})
+	}
+}
@@ -7,7 +7,9 @@ import (
 
 	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
 	"github.com/giantswarm/micrologger/microloggertest"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
 
+	"github.com/giantswarm/flannel-operator/service/controller/v3/etcd"
 	etcdfake "github.com/giantswarm/flannel-operator/service/controller/v3/etcd/fake"
 )
 
@@ -52,8 +54,10 @@ func Test_Resource_NetworkConfig_GetDesiredState(t *testing.T) {
 	var newResource *Resource
 	{
 		c := Config{
-			Logger: microloggertest.New(),
-			Store:  etcdfake.New(),
+			K8sClient:  k8sfake.NewSimpleClientset(),
+			Logger:     microloggertest.New(),
+			Store:      etcdfake.New(),
+			StoreCache: etcd.NewStoreCache(etcd.NewStore),
 		}
 
 		newResource, err = New(c)
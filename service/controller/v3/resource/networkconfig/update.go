@@ -22,8 +22,13 @@ func (r *Resource) ApplyUpdateChange(ctx context.Context, obj, updateChange inte
 
 	var emptyNetworkConfig NetworkConfig
 	if networkConfigToUpdate != emptyNetworkConfig {
+		store, err := r.storeFor(ctx, customObject)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
 		p := key.EtcdNetworkPath(customObject)
-		err = r.store.Delete(ctx, p)
+		err = store.Delete(ctx, p)
 		if err != nil {
 			return microerror.Mask(err)
 		}
@@ -33,7 +38,7 @@ func (r *Resource) ApplyUpdateChange(ctx context.Context, obj, updateChange inte
 			return microerror.Mask(err)
 		}
 		p = key.EtcdNetworkConfigPath(customObject)
-		err = r.store.Create(ctx, p, string(b))
+		err = store.Create(ctx, p, string(b))
 		if err != nil {
 			return microerror.Mask(err)
 		}
@@ -0,0 +1,136 @@
+package nodestatus
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newNode(name string, taints ...corev1.Taint) corev1.Node {
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: corev1.NodeSpec{
+			Taints: taints,
+		},
+	}
+}
+
+func newPod(node string, ready []bool, restarts int32) corev1.Pod {
+	p := corev1.Pod{
+		Spec: corev1.PodSpec{
+			NodeName: node,
+		},
+	}
+	for _, r := range ready {
+		p.Spec.Containers = append(p.Spec.Containers, corev1.Container{})
+		p.Status.ContainerStatuses = append(p.Status.ContainerStatuses, corev1.ContainerStatus{Ready: r, RestartCount: restarts})
+	}
+
+	return p
+}
+
+func Test_Resource_NodeStatus_aggregate(t *testing.T) {
+	testCases := []struct {
+		Name            string
+		Nodes           []corev1.Node
+		Pods            []corev1.Pod
+		ExpectedNodes   []nodeStatus
+		ExpectedSummary string
+	}{
+		{
+			Name:            "case 0: no pods",
+			Pods:            nil,
+			ExpectedNodes:   nil,
+			ExpectedSummary: "0/0 nodes ready",
+		},
+		{
+			Name: "case 1: all nodes ready",
+			Pods: []corev1.Pod{
+				newPod("node-b", []bool{true, true, true}, 0),
+				newPod("node-a", []bool{true, true, true}, 1),
+			},
+			ExpectedNodes: []nodeStatus{
+				{Node: "node-a", Ready: true, Restarts: 3},
+				{Node: "node-b", Ready: true, Restarts: 0},
+			},
+			ExpectedSummary: "2/2 nodes ready",
+		},
+		{
+			Name: "case 2: failing nodes are listed",
+			Pods: []corev1.Pod{
+				newPod("node-a", []bool{true, false, true}, 2),
+				newPod("node-b", []bool{true, true, true}, 0),
+				newPod("node-c", nil, 0),
+				newPod("", []bool{false}, 0),
+			},
+			ExpectedNodes: []nodeStatus{
+				{Node: "node-a", Ready: false, Restarts: 6},
+				{Node: "node-b", Ready: true, Restarts: 0},
+				{Node: "node-c", Ready: false, Restarts: 0},
+			},
+			ExpectedSummary: "1/3 nodes ready; failing: node-a, node-c",
+		},
+		{
+			Name: "case 3: pods without container status are not ready",
+			Pods: []corev1.Pod{
+				{
+					Spec: corev1.PodSpec{
+						NodeName:   "node-a",
+						Containers: []corev1.Container{{}},
+					},
+				},
+			},
+			ExpectedNodes: []nodeStatus{
+				{Node: "node-a", Ready: false, Restarts: 0},
+			},
+			ExpectedSummary: "0/1 nodes ready; failing: node-a",
+		},
+		{
+			Name: "case 4: nodes without network pod are not ready",
+			Nodes: []corev1.Node{
+				newNode("node-a"),
+				newNode("node-b"),
+			},
+			Pods: []corev1.Pod{
+				newPod("node-a", []bool{true, true, true}, 0),
+			},
+			ExpectedNodes: []nodeStatus{
+				{Node: "node-a", Ready: true, Restarts: 0},
+				{Node: "node-b", Ready: false, Restarts: 0},
+			},
+			ExpectedSummary: "1/2 nodes ready; failing: node-b",
+		},
+		{
+			Name: "case 5: only nodes tainted by their conditions are expected to run network pods",
+			Nodes: []corev1.Node{
+				newNode("master-a", corev1.Taint{Key: "node-role.kubernetes.io/master", Effect: corev1.TaintEffectNoSchedule}),
+				newNode("node-a", corev1.Taint{Key: "node.kubernetes.io/unschedulable", Effect: corev1.TaintEffectNoSchedule}),
+				newNode("node-b", corev1.Taint{Key: "dedicated", Effect: corev1.TaintEffectPreferNoSchedule}),
+			},
+			Pods: nil,
+			ExpectedNodes: []nodeStatus{
+				{Node: "node-a", Ready: false, Restarts: 0},
+				{Node: "node-b", Ready: false, Restarts: 0},
+			},
+			ExpectedSummary: "0/2 nodes ready; failing: node-a, node-b",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			nodes := aggregate(tc.Nodes, tc.Pods)
+			if !reflect.DeepEqual(nodes, tc.ExpectedNodes) {
+				t.Fatalf("expected %#v got %#v", tc.ExpectedNodes, nodes)
+			}
+
+			s := summary(nodes)
+			if s != tc.ExpectedSummary {
+				t.Fatalf("expected %#q got %#q", tc.ExpectedSummary, s)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package nodestatus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
+)
+
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", "computing the network status per node")
+
+	selector := labels.SelectorFromSet(labels.Set{
+		"app":     key.NetworkID,
+		"cluster": key.ClusterID(customObject),
+	})
+	pods, err := r.k8sClient.CoreV1().Pods(key.NetworkNamespace(customObject)).List(metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	// Nodes are listed as well, so that nodes without network pod are reported
+	// instead of going unnoticed.
+	nodeList, err := r.k8sClient.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	nodes := aggregate(nodeList.Items, pods.Items)
+	s := summary(nodes)
+
+	r.updateMetrics(key.ClusterID(customObject), nodes)
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("computed the network status per node: %s", s))
+
+	err = r.statusWriter.Write(ctx, customObject, map[string]string{key.AnnotationNetworkStatus: s})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,21 @@
+package nodestatus
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
+)
+
+// EnsureDeleted removes the metrics of the deleted tenant cluster.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.updateMetrics(key.ClusterID(customObject), nil)
+
+	return nil
+}
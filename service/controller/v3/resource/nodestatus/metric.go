@@ -0,0 +1,32 @@
+package nodestatus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/flannel-operator/pkg/metric"
+)
+
+const (
+	PrometheusNamespace = "flannel_operator"
+	PrometheusSubsystem = "nodestatus_resource"
+)
+
+var nodeReadyGauge = metric.MustRegisterGaugeVec(prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: PrometheusNamespace,
+		Subsystem: PrometheusSubsystem,
+		Name:      "node_ready",
+		Help:      "Whether all containers of the network pod of a tenant cluster are ready on a node.",
+	},
+	[]string{"cluster", "node"},
+))
+
+var nodeRestartsGauge = metric.MustRegisterGaugeVec(prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: PrometheusNamespace,
+		Subsystem: PrometheusSubsystem,
+		Name:      "node_container_restarts",
+		Help:      "Number of container restarts of the network pod of a tenant cluster on a node.",
+	},
+	[]string{"cluster", "node"},
+))
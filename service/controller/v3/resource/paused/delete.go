@@ -0,0 +1,39 @@
+package paused
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller/context/finalizerskeptcontext"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+
+	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
+)
+
+// EnsureDeleted cancels the reconciliation of paused FlannelConfigs and keeps
+// their finalizers, so that the network teardown proceeds once the pause ends.
+// The metric of unpaused FlannelConfigs is removed.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if r.track(ctx, customObject) {
+		finalizerskeptcontext.SetKept(ctx)
+		r.logger.LogCtx(ctx, "level", "debug", "message", "keeping finalizers")
+
+		reconciliationcanceledcontext.SetCanceled(ctx)
+		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling reconciliation")
+
+		return nil
+	}
+
+	r.pausedMutex.Lock()
+	delete(r.paused, key.ClusterID(customObject))
+	r.pausedMutex.Unlock()
+
+	pausedGauge.DeleteLabelValues(key.ClusterID(customObject))
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package paused
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/giantswarm/flannel-operator/pkg/event"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "pausedv3"
+)
+
+// Config represents the configuration used to create a new paused resource.
+type Config struct {
+	EventRecorder record.EventRecorder
+	Logger        micrologger.Logger
+}
+
+// Resource implements the paused resource. It runs first in the resource set
+// and cancels the reconciliation of FlannelConfigs which carry the paused
+// annotation, see key.AnnotationPaused.
+type Resource struct {
+	eventRecorder record.EventRecorder
+	logger        micrologger.Logger
+
+	// paused tracks the cluster IDs whose reconciliation is paused, so that
+	// events are only recorded when a pause starts or ends.
+	paused      map[string]bool
+	pausedMutex sync.Mutex
+}
+
+// New creates a new configured paused resource.
+func New(config Config) (*Resource, error) {
+	if config.EventRecorder == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.EventRecorder must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	r := &Resource{
+		eventRecorder: config.EventRecorder,
+		logger:        config.Logger,
+
+		paused: map[string]bool{},
+	}
+
+	return r, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
+
+// track updates the metric of the given FlannelConfig, records an event in
+// case its pause started or ended and returns whether it is paused.
+func (r *Resource) track(ctx context.Context, customObject v1alpha1.FlannelConfig) bool {
+	id := key.ClusterID(customObject)
+	paused := key.IsPaused(customObject)
+
+	r.pausedMutex.Lock()
+	wasPaused := r.paused[id]
+	r.paused[id] = paused
+	r.pausedMutex.Unlock()
+
+	if paused {
+		pausedGauge.WithLabelValues(id).Set(1)
+	} else {
+		pausedGauge.WithLabelValues(id).Set(0)
+	}
+
+	switch {
+	case paused && !wasPaused:
+		message := fmt.Sprintf("reconciliation is paused by annotation %#q", key.AnnotationPaused)
+		r.logger.LogCtx(ctx, "level", "warning", "message", message)
+		r.eventRecorder.Event(&customObject, corev1.EventTypeNormal, event.ReasonReconciliationPaused, message)
+	case !paused && wasPaused:
+		message := fmt.Sprintf("reconciliation is resumed, annotation %#q does not pause it anymore", key.AnnotationPaused)
+		r.logger.LogCtx(ctx, "level", "info", "message", message)
+		r.eventRecorder.Event(&customObject, corev1.EventTypeNormal, event.ReasonReconciliationResumed, message)
+	}
+
+	return paused
+}
@@ -0,0 +1,143 @@
+package paused
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/controller/context/finalizerskeptcontext"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/giantswarm/flannel-operator/pkg/event"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
+)
+
+func newCustomObject(paused string, deleted bool) *v1alpha1.FlannelConfig {
+	customObject := &v1alpha1.FlannelConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "al9qy",
+			Namespace:   "default",
+			Annotations: map[string]string{},
+		},
+		Spec: v1alpha1.FlannelConfigSpec{
+			Cluster: v1alpha1.FlannelConfigSpecCluster{
+				ID: "al9qy",
+			},
+		},
+	}
+
+	if paused != "" {
+		customObject.Annotations[key.AnnotationPaused] = paused
+	}
+	if deleted {
+		customObject.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	}
+
+	return customObject
+}
+
+// Test_Resource_Paused walks a FlannelConfig through a pause and verifies the
+// reconciliation is only canceled while it is paused and events are only
+// recorded when the pause starts or ends.
+func Test_Resource_Paused(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+
+	c := Config{
+		EventRecorder: recorder,
+		Logger:        microloggertest.New(),
+	}
+
+	r, err := New(c)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	steps := []struct {
+		Name                   string
+		CustomObject           *v1alpha1.FlannelConfig
+		ExpectedCanceled       bool
+		ExpectedFinalizersKept bool
+		ExpectedPaused         float64
+		ExpectedEvent          string
+	}{
+		{
+			Name:             "step 0: unpaused FlannelConfigs are reconciled",
+			CustomObject:     newCustomObject("", false),
+			ExpectedCanceled: false,
+			ExpectedPaused:   0,
+			ExpectedEvent:    "",
+		},
+		{
+			Name:             "step 1: the pause starts",
+			CustomObject:     newCustomObject("true", false),
+			ExpectedCanceled: true,
+			ExpectedPaused:   1,
+			ExpectedEvent:    event.ReasonReconciliationPaused,
+		},
+		{
+			Name:             "step 2: the pause continues",
+			CustomObject:     newCustomObject("true", false),
+			ExpectedCanceled: true,
+			ExpectedPaused:   1,
+			ExpectedEvent:    "",
+		},
+		{
+			Name:                   "step 3: the deletion of paused FlannelConfigs is held",
+			CustomObject:           newCustomObject("true", true),
+			ExpectedCanceled:       true,
+			ExpectedFinalizersKept: true,
+			ExpectedPaused:         1,
+			ExpectedEvent:          "",
+		},
+		{
+			Name:             "step 4: the pause ends",
+			CustomObject:     newCustomObject("false", true),
+			ExpectedCanceled: false,
+			ExpectedPaused:   0,
+			ExpectedEvent:    event.ReasonReconciliationResumed,
+		},
+	}
+
+	for _, s := range steps {
+		ctx := context.Background()
+		ctx = finalizerskeptcontext.NewContext(ctx, make(chan struct{}))
+		ctx = reconciliationcanceledcontext.NewContext(ctx, make(chan struct{}))
+
+		if key.IsDeleted(*s.CustomObject) {
+			err = r.EnsureDeleted(ctx, s.CustomObject)
+		} else {
+			err = r.EnsureCreated(ctx, s.CustomObject)
+		}
+		if err != nil {
+			t.Fatalf("%s: expected %#v got %#v", s.Name, nil, err)
+		}
+
+		if reconciliationcanceledcontext.IsCanceled(ctx) != s.ExpectedCanceled {
+			t.Fatalf("%s: expected reconciliation canceled %t got %t", s.Name, s.ExpectedCanceled, reconciliationcanceledcontext.IsCanceled(ctx))
+		}
+		if finalizerskeptcontext.IsKept(ctx) != s.ExpectedFinalizersKept {
+			t.Fatalf("%s: expected finalizers kept %t got %t", s.Name, s.ExpectedFinalizersKept, finalizerskeptcontext.IsKept(ctx))
+		}
+		if paused := testutil.ToFloat64(pausedGauge.WithLabelValues("al9qy")); paused != s.ExpectedPaused {
+			t.Fatalf("%s: expected paused %v got %v", s.Name, s.ExpectedPaused, paused)
+		}
+
+		var e string
+		select {
+		case e = <-recorder.Events:
+		default:
+		}
+		if s.ExpectedEvent == "" && e != "" {
+			t.Fatalf("%s: expected no event got %#q", s.Name, e)
+		}
+		if s.ExpectedEvent != "" && !strings.Contains(e, " "+s.ExpectedEvent+" ") {
+			t.Fatalf("%s: expected event %#q got %#q", s.Name, s.ExpectedEvent, e)
+		}
+	}
+}
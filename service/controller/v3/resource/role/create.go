@@ -0,0 +1,40 @@
+package role
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
+)
+
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "ensuring the role")
+
+		err := r.applier.Apply(ctx, newRole(customObject))
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "ensured the role")
+	}
+
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "ensuring the role binding")
+
+		err := r.applier.Apply(ctx, newRoleBinding(customObject))
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "ensured the role binding")
+	}
+
+	return nil
+}
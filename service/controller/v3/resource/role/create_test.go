@@ -0,0 +1,227 @@
+package role
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/flannel-operator/pkg/apply/applytest"
+	"github.com/giantswarm/flannel-operator/pkg/helmtest"
+)
+
+// evaluator answers authorization requests of service accounts based on the
+// RBAC objects stored in a fake clientset, following the RBAC authorizer of
+// the API server for the subset of rules the operator generates.
+type evaluator struct {
+	k8sClient kubernetes.Interface
+}
+
+type request struct {
+	Verb      string
+	Resource  string
+	Namespace string
+	Name      string
+}
+
+func (e evaluator) allowed(t *testing.T, subject rbacv1.Subject, req request) bool {
+	matchesSubject := func(subjects []rbacv1.Subject) bool {
+		for _, s := range subjects {
+			if s.Kind == subject.Kind && s.Namespace == subject.Namespace && s.Name == subject.Name {
+				return true
+			}
+		}
+		return false
+	}
+
+	var rules []rbacv1.PolicyRule
+	{
+		clusterRoleBindings, err := e.k8sClient.RbacV1().ClusterRoleBindings().List(metav1.ListOptions{})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		for _, b := range clusterRoleBindings.Items {
+			if !matchesSubject(b.Subjects) {
+				continue
+			}
+			r, err := e.k8sClient.RbacV1().ClusterRoles().Get(b.RoleRef.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			rules = append(rules, r.Rules...)
+		}
+	}
+	if req.Namespace != "" {
+		roleBindings, err := e.k8sClient.RbacV1().RoleBindings(req.Namespace).List(metav1.ListOptions{})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		for _, b := range roleBindings.Items {
+			if !matchesSubject(b.Subjects) {
+				continue
+			}
+			r, err := e.k8sClient.RbacV1().Roles(req.Namespace).Get(b.RoleRef.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			rules = append(rules, r.Rules...)
+		}
+	}
+
+	contains := func(l []string, s string) bool {
+		for _, v := range l {
+			if v == s || v == rbacv1.VerbAll {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, r := range rules {
+		if !contains(r.APIGroups, "") || !contains(r.Resources, req.Resource) || !contains(r.Verbs, req.Verb) {
+			continue
+		}
+		if len(r.ResourceNames) != 0 && !contains(r.ResourceNames, req.Name) {
+			continue
+		}
+		return true
+	}
+
+	return false
+}
+
+func Test_Resource_EnsureCreated_Permissions(t *testing.T) {
+	customObject := &v1alpha1.FlannelConfig{
+		Spec: v1alpha1.FlannelConfigSpec{
+			Cluster: v1alpha1.FlannelConfigSpecCluster{
+				ID: "al9qy",
+			},
+		},
+	}
+
+	applier := applytest.New()
+
+	c := Config{
+		Applier: applier,
+		Logger:  microloggertest.New(),
+	}
+
+	r, err := New(c)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	err = r.EnsureCreated(context.Background(), customObject)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// The cluster role of the network pods is installed with the operator and
+	// bound by the clusterrolebindings resource. It is taken from the chart.
+	clusterRole, err := helmtest.ClusterRole("../../../../../helm/flannel-operator/templates/rbac.yaml", "flannel-network")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	objects := []runtime.Object{
+		clusterRole,
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "flannel-network-al9qy",
+			},
+			Subjects: []rbacv1.Subject{
+				{Kind: rbacv1.ServiceAccountKind, Namespace: "flannel-network-al9qy", Name: "al9qy"},
+			},
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "ClusterRole",
+				Name:     "flannel-network",
+			},
+		},
+	}
+	objects = append(objects, applier.Applied()...)
+
+	e := evaluator{
+		k8sClient: fake.NewSimpleClientset(objects...),
+	}
+
+	networkPods := rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "flannel-network-al9qy", Name: "al9qy"}
+	otherPods := rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "flannel-network-xa5ly", Name: "xa5ly"}
+
+	testCases := []struct {
+		Name     string
+		Subject  rbacv1.Subject
+		Request  request
+		Expected bool
+	}{
+		{
+			Name:     "case 0: network pods may list nodes",
+			Subject:  networkPods,
+			Request:  request{Verb: "list", Resource: "nodes"},
+			Expected: true,
+		},
+		{
+			Name:     "case 1: network pods may get pods in the network namespace",
+			Subject:  networkPods,
+			Request:  request{Verb: "get", Resource: "pods", Namespace: "flannel-network-al9qy", Name: "flannel-network-1"},
+			Expected: true,
+		},
+		{
+			Name:     "case 2: network pods may get the etcd certificates secret",
+			Subject:  networkPods,
+			Request:  request{Verb: "get", Resource: "secrets", Namespace: "flannel-network-al9qy", Name: "flannel-network-etcd-certs"},
+			Expected: true,
+		},
+		{
+			Name:     "case 3: network pods must not get other secrets",
+			Subject:  networkPods,
+			Request:  request{Verb: "get", Resource: "secrets", Namespace: "flannel-network-al9qy", Name: "default-token"},
+			Expected: false,
+		},
+		{
+			Name:     "case 4: network pods must not list secrets",
+			Subject:  networkPods,
+			Request:  request{Verb: "list", Resource: "secrets", Namespace: "flannel-network-al9qy"},
+			Expected: false,
+		},
+		{
+			Name:     "case 5: network pods must not get pods of other namespaces",
+			Subject:  networkPods,
+			Request:  request{Verb: "get", Resource: "pods", Namespace: "kube-system", Name: "kube-apiserver"},
+			Expected: false,
+		},
+		{
+			Name:     "case 6: network pods must not delete pods",
+			Subject:  networkPods,
+			Request:  request{Verb: "delete", Resource: "pods", Namespace: "flannel-network-al9qy", Name: "flannel-network-1"},
+			Expected: false,
+		},
+		{
+			Name:     "case 7: network pods must not update nodes",
+			Subject:  networkPods,
+			Request:  request{Verb: "update", Resource: "nodes", Name: "node-a"},
+			Expected: false,
+		},
+		{
+			Name:     "case 8: network pods of other clusters must not get pods in the network namespace",
+			Subject:  otherPods,
+			Request:  request{Verb: "get", Resource: "pods", Namespace: "flannel-network-al9qy", Name: "flannel-network-1"},
+			Expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			allowed := e.allowed(t, tc.Subject, tc.Request)
+			if allowed != tc.Expected {
+				t.Fatalf("expected %t got %t", tc.Expected, allowed)
+			}
+		})
+	}
+}
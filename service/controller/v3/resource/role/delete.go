@@ -0,0 +1,11 @@
+package role
+
+import (
+	"context"
+)
+
+// EnsureDeleted does nothing. The role and role binding are removed together
+// with the network namespace.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	return nil
+}
@@ -0,0 +1,76 @@
+package role
+
+import (
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/flannel-operator/pkg/ownership"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
+)
+
+// newRole returns the Role of the network pods. They may inspect the pods of
+// the network namespace and read the etcd certificates secret. Everything else
+// is denied. The owner is identified by the ownership labels.
+func newRole(customObject v1alpha1.FlannelConfig) *rbacv1.Role {
+	role := &rbacv1.Role{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Role",
+			APIVersion: rbacv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.NetworkID,
+			Namespace: key.NetworkNamespace(customObject),
+			Labels: map[string]string{
+				"app": key.NetworkID,
+			},
+		},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{""},
+				Resources: []string{"pods"},
+				Verbs:     []string{"get", "list"},
+			},
+			{
+				APIGroups:     []string{""},
+				Resources:     []string{"secrets"},
+				ResourceNames: []string{key.EtcdCertsSecretName},
+				Verbs:         []string{"get"},
+			},
+		},
+	}
+	ownership.Set(role, customObject)
+
+	return role
+}
+
+func newRoleBinding(customObject v1alpha1.FlannelConfig) *rbacv1.RoleBinding {
+	roleBinding := &rbacv1.RoleBinding{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "RoleBinding",
+			APIVersion: rbacv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.NetworkID,
+			Namespace: key.NetworkNamespace(customObject),
+			Labels: map[string]string{
+				"app": key.NetworkID,
+			},
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Namespace: key.NetworkNamespace(customObject),
+				Name:      key.ServiceAccountName(customObject),
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     key.NetworkID,
+		},
+	}
+	ownership.Set(roleBinding, customObject)
+
+	return roleBinding
+}
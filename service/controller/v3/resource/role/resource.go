@@ -0,0 +1,49 @@
+package role
+
+import (
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/flannel-operator/pkg/apply"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "rolev3"
+)
+
+// Config represents the configuration used to create a new role resource.
+type Config struct {
+	Applier apply.Interface
+	Logger  micrologger.Logger
+}
+
+// Resource implements the role resource. It manages the Role and RoleBinding
+// granting the service account of the network pods the minimal set of
+// permissions they need within the network namespace. Both are removed
+// together with the network namespace.
+type Resource struct {
+	applier apply.Interface
+	logger  micrologger.Logger
+}
+
+// New creates a new configured role resource.
+func New(config Config) (*Resource, error) {
+	if config.Applier == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Applier must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	r := &Resource{
+		applier: config.Applier,
+		logger:  config.Logger,
+	}
+
+	return r, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
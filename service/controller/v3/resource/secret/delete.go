@@ -0,0 +1,19 @@
+package secret
+
+import (
+	"context"
+
+	"github.com/giantswarm/operatorkit/resource/crud"
+)
+
+func (r *Resource) ApplyDeleteChange(ctx context.Context, obj, deleteChange interface{}) error {
+	return nil
+}
+
+// NewDeletePatch does not return any delete change. The secret lives in the
+// network namespace and is removed together with it by the namespace resource.
+// Deleting it earlier would pull the certificates away from flanneld while the
+// network is still in use during tenant cluster deletion.
+func (r *Resource) NewDeletePatch(ctx context.Context, obj, currentState, desiredState interface{}) (*crud.Patch, error) {
+	return crud.NewPatch(), nil
+}
@@ -0,0 +1,88 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/flannel-operator/pkg/ownership"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
+)
+
+func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interface{}, error) {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", "computing the desired secret")
+
+	// The certificates are read on every reconciliation loop. That way rotated
+	// certificates on the operator's file system or in the Secret referenced
+	// by the FlannelConfig are propagated to the secret with the next resync.
+	var data map[string][]byte
+	if name := key.EtcdSecretName(customObject); name != "" {
+		secret, err := r.k8sClient.CoreV1().Secrets(customObject.GetNamespace()).Get(name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) && key.IsDeleted(customObject) {
+			// The secret is not deleted explicitly on deletion, which is why
+			// there is nothing to do without the etcd secret. It might have
+			// been deleted together with the tenant cluster already.
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("etcd secret %#q does not exist", customObject.GetNamespace()+"/"+name))
+			return nil, nil
+		} else if apierrors.IsNotFound(err) {
+			return nil, microerror.Maskf(notFoundError, "etcd secret %#q", customObject.GetNamespace()+"/"+name)
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		data, err = key.EtcdSecretData(secret)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	} else {
+		data = map[string][]byte{}
+
+		files := map[string]string{
+			key.EtcdCAFileName:  r.etcdCAFile,
+			key.EtcdCrtFileName: r.etcdCrtFile,
+			key.EtcdKeyFileName: r.etcdKeyFile,
+		}
+
+		for k, p := range files {
+			b, err := ioutil.ReadFile(p)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+
+			data[k] = b
+		}
+	}
+
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.EtcdCertsSecretName,
+			Namespace: key.NetworkNamespace(customObject),
+			Labels: map[string]string{
+				"app":      key.NetworkID,
+				"cluster":  key.ClusterID(customObject),
+				"customer": key.ClusterCustomer(customObject),
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+	ownership.Set(secret, customObject)
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", "computed the desired secret")
+
+	return secret, nil
+}
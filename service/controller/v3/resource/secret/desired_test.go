@@ -0,0 +1,176 @@
+package secret
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
+)
+
+func Test_Resource_Secret_GetDesiredState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secret")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"ca.pem":  "ca-content",
+		"crt.pem": "crt-content",
+		"key.pem": "key-content",
+	}
+	for n, c := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, n), []byte(c), 0600)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	var newResource *Resource
+	{
+		c := Config{
+			K8sClient: fake.NewSimpleClientset(),
+			Logger:    microloggertest.New(),
+
+			EtcdCAFile:  filepath.Join(dir, "ca.pem"),
+			EtcdCrtFile: filepath.Join(dir, "crt.pem"),
+			EtcdKeyFile: filepath.Join(dir, "key.pem"),
+		}
+
+		newResource, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	obj := &v1alpha1.FlannelConfig{
+		Spec: v1alpha1.FlannelConfigSpec{
+			Cluster: v1alpha1.FlannelConfigSpecCluster{
+				ID: "al9qy",
+			},
+		},
+	}
+
+	result, err := newResource.GetDesiredState(context.TODO(), obj)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	secret := result.(*corev1.Secret)
+
+	if secret.Namespace != "flannel-network-al9qy" {
+		t.Fatalf("expected %#v got %#v", "flannel-network-al9qy", secret.Namespace)
+	}
+	for n, c := range files {
+		if string(secret.Data[n]) != c {
+			t.Fatalf("expected %#v got %#v", c, string(secret.Data[n]))
+		}
+	}
+
+	// Rotating a certificate on disk must be reflected by the desired state.
+	err = ioutil.WriteFile(filepath.Join(dir, "crt.pem"), []byte("rotated-content"), 0600)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	result, err = newResource.GetDesiredState(context.TODO(), obj)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	secret = result.(*corev1.Secret)
+
+	if string(secret.Data["crt.pem"]) != "rotated-content" {
+		t.Fatalf("expected %#v got %#v", "rotated-content", string(secret.Data["crt.pem"]))
+	}
+}
+
+func Test_Resource_Secret_GetDesiredState_EtcdSecret(t *testing.T) {
+	etcdSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "al9qy-etcd",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			"ca.pem":  []byte("ca-override"),
+			"crt.pem": []byte("crt-override"),
+			"key.pem": []byte("key-override"),
+		},
+	}
+
+	var newResource *Resource
+	{
+		c := Config{
+			K8sClient: fake.NewSimpleClientset(etcdSecret),
+			Logger:    microloggertest.New(),
+
+			// The operator wide certificates must not be read for tenant
+			// clusters referencing their own etcd secret.
+			EtcdCAFile:  "/does/not/exist/ca.pem",
+			EtcdCrtFile: "/does/not/exist/crt.pem",
+			EtcdKeyFile: "/does/not/exist/key.pem",
+		}
+
+		var err error
+		newResource, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	obj := &v1alpha1.FlannelConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "al9qy",
+			Namespace: "default",
+			Annotations: map[string]string{
+				key.AnnotationEtcdSecret: "al9qy-etcd",
+			},
+		},
+		Spec: v1alpha1.FlannelConfigSpec{
+			Cluster: v1alpha1.FlannelConfigSpecCluster{
+				ID: "al9qy",
+			},
+		},
+	}
+
+	result, err := newResource.GetDesiredState(context.TODO(), obj)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	secret := result.(*corev1.Secret)
+
+	for n, c := range etcdSecret.Data {
+		if string(secret.Data[n]) != string(c) {
+			t.Fatalf("expected %#v got %#v", string(c), string(secret.Data[n]))
+		}
+	}
+
+	obj.Annotations[key.AnnotationEtcdSecret] = "missing"
+
+	_, err = newResource.GetDesiredState(context.TODO(), obj)
+	if !IsNotFound(err) {
+		t.Fatalf("expected not found error got %#v", err)
+	}
+
+	// A missing etcd secret must not block the deletion.
+	obj.SetDeletionTimestamp(&metav1.Time{})
+
+	result, err = newResource.GetDesiredState(context.TODO(), obj)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if s, ok := result.(*corev1.Secret); ok && s != nil {
+		t.Fatalf("expected nil got %#v", result)
+	}
+	_, err = newResource.NewDeletePatch(context.TODO(), obj, nil, result)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+}
@@ -0,0 +1,75 @@
+package secret
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/resource/crud"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func (r *Resource) ApplyUpdateChange(ctx context.Context, obj, updateChange interface{}) error {
+	secretToUpdate, err := toSecret(updateChange)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if secretToUpdate != nil {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "updating the secret in the Kubernetes API")
+
+		_, err = r.k8sClient.CoreV1().Secrets(secretToUpdate.GetNamespace()).Update(secretToUpdate)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "updated the secret in the Kubernetes API")
+	} else {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "the secret does not need to be updated in the Kubernetes API")
+	}
+
+	return nil
+}
+
+func (r *Resource) NewUpdatePatch(ctx context.Context, obj, currentState, desiredState interface{}) (*crud.Patch, error) {
+	create, err := r.newCreateChange(ctx, obj, currentState, desiredState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	update, err := r.newUpdateChange(ctx, obj, currentState, desiredState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	patch := crud.NewPatch()
+	patch.SetCreateChange(create)
+	patch.SetUpdateChange(update)
+
+	return patch, nil
+}
+
+func (r *Resource) newUpdateChange(ctx context.Context, obj, currentState, desiredState interface{}) (interface{}, error) {
+	currentSecret, err := toSecret(currentState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	desiredSecret, err := toSecret(desiredState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", "finding out if the secret has to be updated")
+
+	// The secret only needs to be updated in case the certificates changed,
+	// e.g. because they got rotated on the operator's file system.
+	var secretToUpdate *corev1.Secret
+	if currentSecret != nil && desiredSecret != nil && !reflect.DeepEqual(currentSecret.Data, desiredSecret.Data) {
+		secretToUpdate = currentSecret.DeepCopy()
+		secretToUpdate.Data = desiredSecret.Data
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", "found out if the secret has to be updated")
+
+	return secretToUpdate, nil
+}
@@ -0,0 +1,115 @@
+package secret
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_Resource_Secret_newUpdateChange(t *testing.T) {
+	testCases := []struct {
+		Cur          interface{}
+		Des          interface{}
+		ExpectedData map[string][]byte
+	}{
+		// Test 0 ensures that no update is computed when the secret does not
+		// exist yet.
+		{
+			Cur: nil,
+			Des: &corev1.Secret{
+				Data: map[string][]byte{
+					"ca.pem": []byte("foo"),
+				},
+			},
+			ExpectedData: nil,
+		},
+
+		// Test 1 ensures that no update is computed when the certificates did not
+		// change.
+		{
+			Cur: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "flannel-network-etcd-certs",
+				},
+				Data: map[string][]byte{
+					"ca.pem": []byte("foo"),
+				},
+			},
+			Des: &corev1.Secret{
+				Data: map[string][]byte{
+					"ca.pem": []byte("foo"),
+				},
+			},
+			ExpectedData: nil,
+		},
+
+		// Test 2 ensures that rotated certificates result in an update.
+		{
+			Cur: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "flannel-network-etcd-certs",
+				},
+				Data: map[string][]byte{
+					"ca.pem": []byte("foo"),
+				},
+			},
+			Des: &corev1.Secret{
+				Data: map[string][]byte{
+					"ca.pem": []byte("bar"),
+				},
+			},
+			ExpectedData: map[string][]byte{
+				"ca.pem": []byte("bar"),
+			},
+		},
+	}
+
+	var err error
+	var newResource *Resource
+	{
+		c := Config{
+			K8sClient: fake.NewSimpleClientset(),
+			Logger:    microloggertest.New(),
+
+			EtcdCAFile:  "/ca.pem",
+			EtcdCrtFile: "/crt.pem",
+			EtcdKeyFile: "/key.pem",
+		}
+
+		newResource, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	for i, tc := range testCases {
+		result, err := newResource.newUpdateChange(context.TODO(), &v1alpha1.FlannelConfig{}, tc.Cur, tc.Des)
+		if err != nil {
+			t.Fatal("case", i, "expected", nil, "got", err)
+		}
+
+		secret, err := toSecret(result)
+		if err != nil {
+			t.Fatal("case", i, "expected", nil, "got", err)
+		}
+
+		if tc.ExpectedData == nil {
+			if secret != nil {
+				t.Fatalf("case %d expected %#v got %#v", i, nil, secret)
+			}
+		} else {
+			if secret == nil {
+				t.Fatalf("case %d expected secret got %#v", i, nil)
+			}
+			if !reflect.DeepEqual(tc.ExpectedData, secret.Data) {
+				t.Fatalf("case %d expected %#v got %#v", i, tc.ExpectedData, secret.Data)
+			}
+		}
+	}
+}
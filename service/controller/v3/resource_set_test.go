@@ -0,0 +1,228 @@
+package v3
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	g8sfake "github.com/giantswarm/apiextensions/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/k8sclient/k8sclienttest"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/controller"
+	"github.com/giantswarm/operatorkit/controller/context/finalizerskeptcontext"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/giantswarm/flannel-operator/pkg/shard"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/etcd/fake"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/resource/flanneld"
+)
+
+// testStore holds an empty network config, which is enough for the
+// networkconfig resource to tear down the network state.
+type testStore struct {
+	*fake.Fake
+}
+
+func (s testStore) Search(ctx context.Context, key string) (string, error) {
+	return "{}", nil
+}
+
+// Test_ResourceSet_Delete_TerminatingNamespace deletes a FlannelConfig whose
+// network namespace is terminating already. The deletion phases of the legacy
+// resource have to start right away and pass every phase, including the
+// network bridge cleanup. The finalizers have to be kept until the teardown is
+// finalized.
+func Test_ResourceSet_Delete_TerminatingNamespace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flannel-operator")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{}
+	for _, f := range []string{key.EtcdCAFileName, key.EtcdCrtFileName, key.EtcdKeyFileName} {
+		files[f] = filepath.Join(dir, f)
+		err := ioutil.WriteFile(files[f], []byte(f), 0600)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	customObject := &v1alpha1.FlannelConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "al9qy",
+			Namespace:         "default",
+			UID:               "uid-1",
+			Annotations:       map[string]string{},
+			DeletionTimestamp: &metav1.Time{},
+			Finalizers: []string{
+				"operatorkit.giantswarm.io/flannel-operator",
+			},
+		},
+		Spec: v1alpha1.FlannelConfigSpec{
+			Cluster: v1alpha1.FlannelConfigSpecCluster{
+				ID:        "al9qy",
+				Namespace: "al9qy",
+			},
+			VersionBundle: v1alpha1.FlannelConfigSpecVersionBundle{
+				Version: VersionBundle().Version,
+			},
+		},
+	}
+
+	g8sClient := g8sfake.NewSimpleClientset(customObject)
+	k8sClient := k8sfake.NewSimpleClientset(
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: key.NetworkNamespace(*customObject),
+			},
+			Status: corev1.NamespaceStatus{
+				Phase: corev1.NamespaceTerminating,
+			},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-a",
+			},
+		},
+	)
+
+	// The fake dynamic client does not support server side apply.
+	dynClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	dynClient.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &unstructured.Unstructured{}, nil
+	})
+
+	var resourceSet *controller.ResourceSet
+	{
+		clients, err := k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
+			DynClient: dynClient,
+			G8sClient: g8sClient,
+			K8sClient: k8sClient,
+		})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		probe := flanneld.Probe{
+			FailureThreshold: 3,
+			PeriodSeconds:    10,
+			SuccessThreshold: 1,
+			TimeoutSeconds:   5,
+		}
+
+		s, err := shard.New(shard.Config{Count: 1})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		c := ResourceSetConfig{
+			EventRecorder: record.NewFakeRecorder(100),
+			K8sClient:     clients,
+			Logger:        microloggertest.New(),
+			Shard:         s,
+			Store:         testStore{Fake: fake.New()},
+
+			CAFile:        files[key.EtcdCAFileName],
+			CrtFile:       files[key.EtcdCrtFileName],
+			EtcdEndpoints: []string{"https://127.0.0.1:2379"},
+			KeyFile:       files[key.EtcdKeyFileName],
+
+			HealthPortMax:  21999,
+			HealthPortMin:  21000,
+			LivenessProbe:  probe,
+			ReadinessProbe: probe,
+		}
+
+		resourceSet, err = NewResourceSet(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	var phases []string
+	for i := 0; i < 10; i++ {
+		obj, err := g8sClient.CoreV1alpha1().FlannelConfigs("default").Get("al9qy", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		ctx := finalizerskeptcontext.NewContext(context.Background(), make(chan struct{}))
+
+		err = controller.ProcessDelete(ctx, obj, resourceSet.Resources())
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		obj, err = g8sClient.CoreV1alpha1().FlannelConfigs("default").Get("al9qy", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		phase := key.DeletionPhase(*obj)
+		phases = append(phases, phase)
+
+		if phase != key.DeletionPhaseFinalized {
+			if !finalizerskeptcontext.IsKept(ctx) {
+				t.Fatalf("expected finalizers kept in phase %#q after phases %v", phase, phases)
+			}
+		} else {
+			if finalizerskeptcontext.IsKept(ctx) {
+				t.Fatalf("expected finalizers released in phase %#q", phase)
+			}
+			break
+		}
+
+		// The world moves on between reconciliation loops. Terminating
+		// namespaces go away and the cleanup jobs succeed.
+		{
+			namespaces, err := k8sClient.CoreV1().Namespaces().List(metav1.ListOptions{})
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			for _, n := range namespaces.Items {
+				if n.Status.Phase != corev1.NamespaceTerminating {
+					continue
+				}
+				err := k8sClient.CoreV1().Namespaces().Delete(n.GetName(), &metav1.DeleteOptions{})
+				if err != nil {
+					t.Fatal("expected", nil, "got", err)
+				}
+			}
+
+			jobs, err := k8sClient.BatchV1().Jobs(metav1.NamespaceAll).List(metav1.ListOptions{})
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			for _, j := range jobs.Items {
+				j.Status.Succeeded = 1
+				_, err := k8sClient.BatchV1().Jobs(j.GetNamespace()).UpdateStatus(&j)
+				if err != nil {
+					t.Fatal("expected", nil, "got", err)
+				}
+			}
+		}
+	}
+
+	expected := []string{
+		key.DeletionPhaseWaitingForPods,
+		key.DeletionPhaseNamespacesDeleted,
+		key.DeletionPhaseCleanupScheduled,
+		key.DeletionPhaseCleanupDone,
+		key.DeletionPhaseFinalized,
+	}
+	if !reflect.DeepEqual(phases, expected) {
+		t.Fatalf("expected phases %v got %v", expected, phases)
+	}
+}
@@ -0,0 +1,17 @@
+package workloadgate
+
+import (
+	"context"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+)
+
+type Interface interface {
+	// Wait returns whether the teardown of the network of the given deleted
+	// tenant cluster has to wait for workloads of the tenant cluster. The
+	// network is still needed then, e.g. to drain nodes on KVM.
+	Wait(ctx context.Context, customObject v1alpha1.FlannelConfig) (bool, error)
+	// Forget drops the state kept for the given FlannelConfig. It is called
+	// once the teardown of its network is finalized.
+	Forget(customObject v1alpha1.FlannelConfig)
+}
@@ -0,0 +1,200 @@
+// Package workloadgate decides when the network of a deleted tenant cluster
+// may be torn down. The network is kept as long as workloads of the tenant
+// cluster run in its cluster namespace. Pods which completed or failed, e.g.
+// job pods or evicted pods, are not considered workloads. Pods can be narrowed
+// down further via a label selector. The teardown is held for a limited time
+// only so that pods which never go away do not block it forever. The wait is
+// counted from the time the gate first held the teardown, which is recorded in
+// an annotation of the FlannelConfig so that it survives operator restarts.
+package workloadgate
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/giantswarm/flannel-operator/pkg/event"
+	"github.com/giantswarm/flannel-operator/pkg/status"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
+)
+
+type Config struct {
+	EventRecorder record.EventRecorder
+	K8sClient     kubernetes.Interface
+	Logger        micrologger.Logger
+	StatusWriter  status.Interface
+
+	// LabelSelector selects the pods in the cluster namespace considered
+	// workloads. An empty selector selects all pods.
+	LabelSelector string
+	// MaxWait is the maximum time the teardown waits for workloads, counted
+	// from the time the gate first held the teardown, see
+	// key.AnnotationWorkloadWaitStarted. Zero means to wait forever.
+	MaxWait time.Duration
+}
+
+type Gate struct {
+	eventRecorder record.EventRecorder
+	k8sClient     kubernetes.Interface
+	logger        micrologger.Logger
+	statusWriter  status.Interface
+
+	labelSelector string
+	maxWait       time.Duration
+
+	// expired holds the FlannelConfigs the maximum wait expired for, so that
+	// the warning event is only emitted once per FlannelConfig by the
+	// resources sharing the gate. started holds the recorded start of the
+	// wait, since the resources sharing the gate within one reconciliation
+	// loop do not see the annotation written by the first of them. Both are
+	// cleared via Forget.
+	expired map[types.UID]bool
+	started map[types.UID]time.Time
+	mutex   sync.Mutex
+	now     func() time.Time
+}
+
+func New(config Config) (*Gate, error) {
+	if config.EventRecorder == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.EventRecorder must not be empty", config)
+	}
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.StatusWriter == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.StatusWriter must not be empty", config)
+	}
+
+	_, err := labels.Parse(config.LabelSelector)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.LabelSelector must be valid: %s", config, err)
+	}
+	if config.MaxWait < 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.MaxWait must not be negative", config)
+	}
+
+	g := &Gate{
+		eventRecorder: config.EventRecorder,
+		k8sClient:     config.K8sClient,
+		logger:        config.Logger,
+		statusWriter:  config.StatusWriter,
+
+		labelSelector: config.LabelSelector,
+		maxWait:       config.MaxWait,
+
+		expired: map[types.UID]bool{},
+		started: map[types.UID]time.Time{},
+		now:     time.Now,
+	}
+
+	return g, nil
+}
+
+func (g *Gate) Wait(ctx context.Context, customObject v1alpha1.FlannelConfig) (bool, error) {
+	var workloads []string
+	{
+		o := metav1.ListOptions{
+			LabelSelector: g.labelSelector,
+		}
+		list, err := g.k8sClient.CoreV1().Pods(key.ClusterNamespace(customObject)).List(o)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+
+		for _, p := range list.Items {
+			if p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed {
+				continue
+			}
+
+			workloads = append(workloads, p.GetName())
+		}
+	}
+
+	if len(workloads) == 0 {
+		return false, nil
+	}
+
+	if g.maxWait == 0 || !key.IsDeleted(customObject) {
+		g.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("waiting for %d workloads in namespace %#q", len(workloads), key.ClusterNamespace(customObject)))
+		return true, nil
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	started, err := g.waitStarted(ctx, customObject)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	if g.now().Sub(started) < g.maxWait {
+		g.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("waiting for %d workloads in namespace %#q", len(workloads), key.ClusterNamespace(customObject)))
+		return true, nil
+	}
+
+	g.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("not waiting for %d workloads in namespace %#q any longer than %s", len(workloads), key.ClusterNamespace(customObject), g.maxWait))
+
+	if !g.expired[customObject.GetUID()] {
+		g.eventRecorder.Eventf(&customObject, corev1.EventTypeWarning, event.ReasonWorkloadWaitExpired, "tearing down the network with %d workloads left in namespace %q after waiting %s", len(workloads), key.ClusterNamespace(customObject), g.maxWait)
+		g.expired[customObject.GetUID()] = true
+	}
+
+	return false, nil
+}
+
+// Forget implements Interface.
+func (g *Gate) Forget(customObject v1alpha1.FlannelConfig) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	delete(g.expired, customObject.GetUID())
+	delete(g.started, customObject.GetUID())
+}
+
+// waitStarted returns the time the gate first held the teardown of the given
+// FlannelConfig. It is recorded in key.AnnotationWorkloadWaitStarted the first
+// time the gate holds the teardown. Values which cannot be parsed are
+// replaced, so that a broken annotation restarts the wait instead of blocking
+// the teardown. The caller must hold the mutex.
+func (g *Gate) waitStarted(ctx context.Context, customObject v1alpha1.FlannelConfig) (time.Time, error) {
+	started, ok := g.started[customObject.GetUID()]
+	if ok {
+		return started, nil
+	}
+
+	v, ok := customObject.GetAnnotations()[key.AnnotationWorkloadWaitStarted]
+	if ok {
+		started, err := time.Parse(time.RFC3339, v)
+		if err == nil {
+			g.started[customObject.GetUID()] = started
+			return started, nil
+		}
+
+		g.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("replacing annotation %#q: invalid time format, expected RFC 3339, got %#q", key.AnnotationWorkloadWaitStarted, v))
+	}
+
+	started = g.now().UTC().Truncate(time.Second)
+
+	err := g.statusWriter.Write(ctx, customObject, map[string]string{key.AnnotationWorkloadWaitStarted: started.Format(time.RFC3339)})
+	if err != nil {
+		return time.Time{}, microerror.Mask(err)
+	}
+
+	g.started[customObject.GetUID()] = started
+
+	return started, nil
+}
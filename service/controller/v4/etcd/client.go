@@ -0,0 +1,159 @@
+package etcd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/coreos/etcd/client"
+	"github.com/giantswarm/microerror"
+	microtls "github.com/giantswarm/microkit/tls"
+)
+
+// ClientConfig represents the configuration used to create an etcd client
+// authenticating with TLS certificates. The certificates are either given as
+// file paths or as PEM encoded data. Data takes precedence over files.
+type ClientConfig struct {
+	Endpoints []string
+
+	CAFile  string
+	CrtFile string
+	KeyFile string
+
+	CAData  []byte
+	CrtData []byte
+	KeyData []byte
+}
+
+// NewClient creates an etcd v2 client for the given endpoints. The CA is
+// optional.
+func NewClient(config ClientConfig) (client.Client, error) {
+	etcdClient, _, err := newClient(config)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return etcdClient, nil
+}
+
+// NewStore creates a Store backed by an etcd v2 client for the given
+// configuration. The Store closes the idle connections of its client via
+// CloseIdleConnections once it is not used anymore.
+func NewStore(config ClientConfig) (Store, error) {
+	etcdClient, transport, err := newClient(config)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	c := DefaultConfig()
+	c.EtcdClient = etcdClient
+
+	store, err := New(c)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	store.transport = transport
+
+	return store, nil
+}
+
+func newClient(config ClientConfig) (client.Client, *http.Transport, error) {
+	if len(config.Endpoints) == 0 {
+		return nil, nil, microerror.Maskf(invalidConfigError, "%T.Endpoints must not be empty", config)
+	}
+
+	var err error
+
+	var tlsConfig *tls.Config
+	if len(config.CrtData) != 0 || len(config.KeyData) != 0 {
+		tlsConfig, err = newTLSConfigFromData(config)
+		if err != nil {
+			return nil, nil, microerror.Mask(err)
+		}
+	} else {
+		tlsConfig, err = newTLSConfigFromFiles(config)
+		if err != nil {
+			return nil, nil, microerror.Mask(err)
+		}
+	}
+
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		Dial: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).Dial,
+		TLSHandshakeTimeout: 10 * time.Second,
+		TLSClientConfig:     tlsConfig,
+	}
+
+	etcdConfig := client.Config{
+		Endpoints: config.Endpoints,
+		Transport: transport,
+	}
+
+	etcdClient, err := client.New(etcdConfig)
+	if err != nil {
+		return nil, nil, microerror.Mask(err)
+	}
+
+	return etcdClient, transport, nil
+}
+
+func newTLSConfigFromData(config ClientConfig) (*tls.Config, error) {
+	if len(config.CrtData) == 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CrtData must not be empty", config)
+	}
+	if len(config.KeyData) == 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.KeyData must not be empty", config)
+	}
+
+	cert, err := tls.X509KeyPair(config.CrtData, config.KeyData)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CrtData and %T.KeyData must be a valid key pair: %s", config, config, err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if len(config.CAData) != 0 {
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(config.CAData) {
+			return nil, microerror.Maskf(invalidConfigError, "%T.CAData must hold PEM encoded certificates", config)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	return tlsConfig, nil
+}
+
+func newTLSConfigFromFiles(config ClientConfig) (*tls.Config, error) {
+	if config.CrtFile == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CrtFile must not be empty", config)
+	}
+	if config.KeyFile == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.KeyFile must not be empty", config)
+	}
+
+	rootCAs := []string{}
+	if config.CAFile != "" {
+		rootCAs = []string{
+			config.CAFile,
+		}
+	}
+	certFiles := microtls.CertFiles{
+		RootCAs: rootCAs,
+		Cert:    config.CrtFile,
+		Key:     config.KeyFile,
+	}
+
+	tlsConfig, err := microtls.LoadTLSConfig(certFiles)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,474 @@
+package key
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/giantswarm/flannel-operator/pkg/apis/core/v1alpha2"
+)
+
+const (
+	// NetworkID is the ID used to label apps for resources running flannel
+	// components.
+	NetworkID = "flannel-network"
+
+	// DestroyerID is the ID used to label apps for resources cleaning up the
+	// flannel network and bridges of deleted tenant clusters.
+	DestroyerID = "flannel-destroyer"
+
+	// EtcdNetworksPath is the etcd directory holding the network state of all
+	// tenant cluster networks. The state of a single network lives in the
+	// directory named after its bridge, see NetworkBridgePrefix.
+	EtcdNetworksPath = "coreos.com/network"
+
+	// NetworkBridgePrefix is the prefix of the bridge name of tenant cluster
+	// networks. It is followed by the cluster ID.
+	NetworkBridgePrefix = "br-"
+
+	// EtcdCertsMountPath is the path the etcd certificates secret is mounted to
+	// within the flanneld container.
+	EtcdCertsMountPath = "/etc/flannel/etcd"
+	// EtcdCertsSecretName is the name of the etcd certificates secret in the
+	// network namespace of every tenant cluster.
+	EtcdCertsSecretName = NetworkID + "-etcd-certs"
+
+	// EtcdCAFileName, EtcdCrtFileName and EtcdKeyFileName are the keys of the
+	// etcd certificates secret. They are also the file names of the
+	// certificates within EtcdCertsMountPath.
+	EtcdCAFileName  = "ca.pem"
+	EtcdCrtFileName = "crt.pem"
+	EtcdKeyFileName = "key.pem"
+
+	// PortNameHealthz and PortNameMetrics are the names of the container ports
+	// of the flanneld health endpoint and the network health endpoint, which
+	// also serves metrics.
+	PortNameHealthz = "healthz"
+	PortNameMetrics = "metrics"
+
+	// LabelPodSecurityEnforce is the Pod Security Admission label defining the
+	// policy enforced for pods of a namespace. The network and destroyer pods
+	// run privileged in the host network, which requires the privileged
+	// policy.
+	LabelPodSecurityEnforce      = "pod-security.kubernetes.io/enforce"
+	LabelPodSecurityEnforceValue = "privileged"
+
+	// flanneld image
+	FlannelDockerImage = "quay.io/giantswarm/flannel:v0.12.0-amd64"
+)
+
+const (
+	// AnnotationFlanneldHealthzPort, AnnotationFlanneldIfaceRegex,
+	// AnnotationFlanneldIPMasq, AnnotationFlanneldPublicIP,
+	// AnnotationFlanneldSubnetLeaseRenewMargin and AnnotationFlanneldVerbosity
+	// can be put on a FlannelConfig to override the operator wide flanneld
+	// options for a single tenant cluster.
+	AnnotationFlanneldHealthzPort            = "flannel-operator.giantswarm.io/flanneld-healthz-port"
+	AnnotationFlanneldIfaceRegex             = "flannel-operator.giantswarm.io/flanneld-iface-regex"
+	AnnotationFlanneldIPMasq                 = "flannel-operator.giantswarm.io/flanneld-ip-masq"
+	AnnotationFlanneldPublicIP               = "flannel-operator.giantswarm.io/flanneld-public-ip"
+	AnnotationFlanneldSubnetLeaseRenewMargin = "flannel-operator.giantswarm.io/flanneld-subnet-lease-renew-margin"
+	AnnotationFlanneldVerbosity              = "flannel-operator.giantswarm.io/flanneld-verbosity"
+
+	// AnnotationHealthPort holds the host port allocated for the health
+	// endpoint of the tenant cluster network. It is managed by the operator and
+	// must not be changed manually.
+	AnnotationHealthPort = v1alpha2.AnnotationHealthPort
+
+	// AnnotationAllocatedFlanneldHealthzPort holds the host port allocated for
+	// the flanneld health endpoint of the tenant cluster network. Ports
+	// requested via AnnotationFlanneldHealthzPort are recorded here too, once
+	// they got validated. It is managed by the operator and must not be
+	// changed manually.
+	AnnotationAllocatedFlanneldHealthzPort = "flannel-operator.giantswarm.io/allocated-flanneld-healthz-port"
+
+	// AnnotationNetworkStatus holds a summary of the readiness of the network
+	// pods of the tenant cluster per node. It is managed by the operator.
+	AnnotationNetworkStatus = v1alpha2.AnnotationNetworkStatus
+
+	// AnnotationDeletionPhase holds the phase of the network teardown of a
+	// deleted FlannelConfig. It is managed by the operator.
+	AnnotationDeletionPhase = v1alpha2.AnnotationDeletionPhase
+
+	// AnnotationCleanupNodes holds the nodes the bridge cleanup of a deleted
+	// FlannelConfig targets and the cleanup state of each of them. It is
+	// managed by the operator.
+	AnnotationCleanupNodes = "flannel-operator.giantswarm.io/cleanup-nodes"
+
+	// AnnotationDeletionBlocked holds the reason why the network teardown of a
+	// deleted FlannelConfig cannot proceed, e.g. because the bridge cleanup
+	// failed on some nodes or the network is protected, see
+	// AnnotationDeletionProtection. It is managed by the operator and empty as
+	// long as the teardown is not blocked.
+	AnnotationDeletionBlocked = "flannel-operator.giantswarm.io/deletion-blocked"
+
+	// AnnotationWorkloadWaitStarted holds the time the workload gate started
+	// holding the network teardown of a deleted FlannelConfig in RFC 3339
+	// format. The maximum wait is counted from it. It is managed by the
+	// operator.
+	AnnotationWorkloadWaitStarted = "flannel-operator.giantswarm.io/workload-wait-started"
+
+	// AnnotationDeletionProtection protects the network of a tenant cluster
+	// from being torn down. As long as it is set to anything but "false" the
+	// teardown of a deleted FlannelConfig is held. It is managed by the user.
+	AnnotationDeletionProtection = "flannel-operator.giantswarm.io/deletion-protection"
+
+	// AnnotationPaused pauses the reconciliation of a FlannelConfig. As long as
+	// it is set to anything but "false" the operator does not touch the network
+	// of the tenant cluster, neither on creation and update nor on deletion. It
+	// is managed by the user.
+	AnnotationPaused = "flannel-operator.giantswarm.io/paused"
+
+	// AnnotationEtcdEndpoints and AnnotationEtcdSecret make the network of a
+	// tenant cluster use a different etcd cluster than the operator wide one.
+	// AnnotationEtcdEndpoints holds a comma separated list of endpoints.
+	// AnnotationEtcdSecret names a Secret in the namespace of the
+	// FlannelConfig holding the etcd client certificates under EtcdCAFileName,
+	// EtcdCrtFileName and EtcdKeyFileName. The operator wide endpoints and
+	// certificates are used for whatever is not overridden. Both are managed
+	// by the user. Changing them does not migrate the network state between
+	// etcd clusters.
+	AnnotationEtcdEndpoints = "flannel-operator.giantswarm.io/etcd-endpoints"
+	AnnotationEtcdSecret    = "flannel-operator.giantswarm.io/etcd-secret"
+
+	// AnnotationEtcdCertsChecksum holds a checksum of the etcd client
+	// certificates the network pods mount. It is put on the pod template by
+	// the operator, so that rotated certificates roll the network pods.
+	AnnotationEtcdCertsChecksum = "flannel-operator.giantswarm.io/etcd-certs-checksum"
+)
+
+// The phases of the network teardown of a deleted FlannelConfig in the order
+// they are passed.
+const (
+	// DeletionPhaseWaitingForPods means the teardown waits for the pods of the
+	// tenant cluster and the cluster and network namespaces to be gone.
+	DeletionPhaseWaitingForPods = "waiting-for-pods"
+	// DeletionPhaseNamespacesDeleted means the cluster and network namespaces
+	// are gone and the bridge cleanup can be scheduled.
+	DeletionPhaseNamespacesDeleted = "namespaces-deleted"
+	// DeletionPhaseCleanupScheduled means the bridge cleanup job was created
+	// and the teardown waits for it to complete.
+	DeletionPhaseCleanupScheduled = "cleanup-scheduled"
+	// DeletionPhaseCleanupDone means the bridge cleanup completed and the
+	// teardown waits for the destroyer namespace to be gone.
+	DeletionPhaseCleanupDone = "cleanup-done"
+	// DeletionPhaseFinalized means the teardown is complete and the finalizers
+	// of the FlannelConfig may be removed.
+	DeletionPhaseFinalized = "finalized"
+)
+
+var deletionPhases = []string{
+	DeletionPhaseWaitingForPods,
+	DeletionPhaseNamespacesDeleted,
+	DeletionPhaseCleanupScheduled,
+	DeletionPhaseCleanupDone,
+	DeletionPhaseFinalized,
+}
+
+// ClusterRoleBindingName returns the name of the cluster role binding of the
+// service account of the network pods.
+func ClusterRoleBindingName(customObject v1alpha1.FlannelConfig) string {
+	return NetworkID + "-" + ClusterID(customObject)
+}
+
+// ClusterRoleBindingForDeletionName returns the name of the cluster role
+// binding of the service account of the destroyer pods.
+func ClusterRoleBindingForDeletionName(customObject v1alpha1.FlannelConfig) string {
+	return ClusterID(customObject) + "-deletion"
+}
+
+// ClusterRoleBindingPodSecurityPolicyName returns the name of the pod
+// security policy cluster role binding of the service account of the network
+// pods.
+func ClusterRoleBindingPodSecurityPolicyName(customObject v1alpha1.FlannelConfig) string {
+	return NetworkID + "-" + ClusterID(customObject) + "-psp"
+}
+
+// ClusterRoleBindingPodSecurityPolicyForDeletionName returns the name of the
+// pod security policy cluster role binding of the service account of the
+// destroyer pods.
+func ClusterRoleBindingPodSecurityPolicyForDeletionName(customObject v1alpha1.FlannelConfig) string {
+	return NetworkID + "-" + ClusterID(customObject) + "-deletion-psp"
+}
+
+func ClusterCustomer(customObject v1alpha1.FlannelConfig) string {
+	return customObject.Spec.Cluster.Customer
+}
+
+func ClusterID(customObject v1alpha1.FlannelConfig) string {
+	return customObject.Spec.Cluster.ID
+}
+
+func ClusterNamespace(customObject v1alpha1.FlannelConfig) string {
+	return customObject.Spec.Cluster.Namespace
+}
+
+// DeletionPhase returns the phase of the network teardown of the given custom
+// object. It is empty in case the teardown did not start yet.
+func DeletionPhase(customObject v1alpha1.FlannelConfig) string {
+	return customObject.GetAnnotations()[AnnotationDeletionPhase]
+}
+
+// DeletionPhaseReached returns whether the network teardown of the given custom
+// object is in the given phase or passed it already.
+func DeletionPhaseReached(customObject v1alpha1.FlannelConfig, phase string) bool {
+	current := DeletionPhase(customObject)
+	if current == "" {
+		current = DeletionPhaseWaitingForPods
+	}
+
+	for _, p := range deletionPhases {
+		if p == current {
+			return p == phase
+		}
+		if p == phase {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DestroyerNamespace returns the namespace the resources cleaning up the
+// network of a deleted tenant cluster run in.
+func DestroyerNamespace(customObject v1alpha1.FlannelConfig) string {
+	return DestroyerID + "-" + ClusterID(customObject)
+}
+
+func EtcdCAFilePath() string {
+	return EtcdCertsMountPath + "/" + EtcdCAFileName
+}
+
+func EtcdCrtFilePath() string {
+	return EtcdCertsMountPath + "/" + EtcdCrtFileName
+}
+
+// EtcdEndpoints returns the etcd endpoints the network of the given tenant
+// cluster uses instead of the operator wide ones. It returns nil in case the
+// endpoints are not overridden.
+func EtcdEndpoints(customObject v1alpha1.FlannelConfig) ([]string, error) {
+	v, ok := customObject.GetAnnotations()[AnnotationEtcdEndpoints]
+	if !ok {
+		return nil, nil
+	}
+
+	var endpoints []string
+	for _, e := range strings.Split(v, ",") {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+
+		u, err := url.Parse(e)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, microerror.Maskf(invalidConfigError, "annotation %#q must hold http or https URLs, got %#q", AnnotationEtcdEndpoints, e)
+		}
+
+		endpoints = append(endpoints, e)
+	}
+
+	if len(endpoints) == 0 {
+		return nil, microerror.Maskf(invalidConfigError, "annotation %#q must not be empty", AnnotationEtcdEndpoints)
+	}
+
+	return endpoints, nil
+}
+
+func EtcdKeyFilePath() string {
+	return EtcdCertsMountPath + "/" + EtcdKeyFileName
+}
+
+func EtcdNetworkConfigPath(customObject v1alpha1.FlannelConfig) string {
+	return EtcdNetworkPath(customObject) + "/config"
+}
+
+func EtcdNetworkPath(customObject v1alpha1.FlannelConfig) string {
+	return EtcdNetworksPath + "/" + NetworkBridgeName(customObject)
+}
+
+func EtcdPrefix(customObject v1alpha1.FlannelConfig) string {
+	return "/" + EtcdNetworkPath(customObject)
+}
+
+// EtcdCertsChecksum returns a checksum of the CA, certificate and key held by
+// the given Secret data.
+func EtcdCertsChecksum(data map[string][]byte) string {
+	h := sha256.New()
+	for _, k := range []string{EtcdCAFileName, EtcdCrtFileName, EtcdKeyFileName} {
+		h.Write([]byte(k))
+		h.Write([]byte{0})
+		h.Write(data[k])
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// EtcdSecretData returns the etcd client certificates held by the given
+// Secret referenced via AnnotationEtcdSecret.
+func EtcdSecretData(secret *corev1.Secret) (map[string][]byte, error) {
+	data := map[string][]byte{}
+
+	for _, k := range []string{EtcdCAFileName, EtcdCrtFileName, EtcdKeyFileName} {
+		b := secret.Data[k]
+		if len(b) == 0 {
+			return nil, microerror.Maskf(invalidConfigError, "secret %#q must hold %#q", secret.Namespace+"/"+secret.Name, k)
+		}
+
+		data[k] = b
+	}
+
+	return data, nil
+}
+
+// EtcdSecretName returns the name of the Secret in the namespace of the given
+// FlannelConfig holding the etcd client certificates its network uses instead
+// of the operator wide ones. It returns an empty string in case the
+// certificates are not overridden.
+func EtcdSecretName(customObject v1alpha1.FlannelConfig) string {
+	return customObject.GetAnnotations()[AnnotationEtcdSecret]
+}
+
+func FlannelRunDir(customObject v1alpha1.FlannelConfig) string {
+	return customObject.Spec.Flannel.Spec.RunDir
+}
+
+func FlannelVNI(customObject v1alpha1.FlannelConfig) int {
+	return customObject.Spec.Flannel.Spec.VNI
+}
+
+func HostPrivateNetwork(customObject v1alpha1.FlannelConfig) string {
+	return customObject.Spec.Bridge.Spec.PrivateNetwork
+}
+
+func IsDeleted(customObject v1alpha1.FlannelConfig) bool {
+	return customObject.GetDeletionTimestamp() != nil
+}
+
+// IsDeletionProtected returns whether the network of the given FlannelConfig
+// is protected from being torn down. Values which cannot be parsed as boolean
+// protect the network too, so that a typo never causes a teardown.
+func IsDeletionProtected(customObject v1alpha1.FlannelConfig) bool {
+	v, ok := customObject.GetAnnotations()[AnnotationDeletionProtection]
+	if !ok {
+		return false
+	}
+
+	protected, err := strconv.ParseBool(v)
+	if err != nil {
+		return true
+	}
+
+	return protected
+}
+
+// IsPaused returns whether the reconciliation of the given FlannelConfig is
+// paused. Values which cannot be parsed as boolean pause the reconciliation
+// too, so that a typo never resumes it.
+func IsPaused(customObject v1alpha1.FlannelConfig) bool {
+	v, ok := customObject.GetAnnotations()[AnnotationPaused]
+	if !ok {
+		return false
+	}
+
+	paused, err := strconv.ParseBool(v)
+	if err != nil {
+		return true
+	}
+
+	return paused
+}
+
+// MaxUnavailable is used for the Kubernetes update strategy. We want only one
+// pod at a time to be unavailable during updates.
+func MaxUnavailable() *intstr.IntOrString {
+	v := intstr.FromInt(1)
+	return &v
+}
+
+func NetworkBridgeDockerImage(customObject v1alpha1.FlannelConfig) string {
+	return customObject.Spec.Bridge.Docker.Image
+}
+
+func NetworkBridgeName(customObject v1alpha1.FlannelConfig) string {
+	return NetworkBridgePrefix + ClusterID(customObject)
+}
+
+func NetworkDNSBlock(customObject v1alpha1.FlannelConfig) string {
+	var parts []string
+
+	for _, s := range customObject.Spec.Bridge.Spec.DNS.Servers {
+		parts = append(parts, fmt.Sprintf("DNS=%s", s))
+	}
+
+	return strings.Join(parts, "\n")
+}
+
+func NetworkEnvFilePath(customObject v1alpha1.FlannelConfig) string {
+	return fmt.Sprintf("%s/networks/%s.env", FlannelRunDir(customObject), NetworkBridgeName(customObject))
+}
+
+func NetworkFlannelDevice(customObject v1alpha1.FlannelConfig) string {
+	return fmt.Sprintf("flannel.%d", FlannelVNI(customObject))
+}
+
+func NetworkHealthDockerImage(customObject v1alpha1.FlannelConfig) string {
+	return customObject.Spec.Health.Docker.Image
+}
+
+func NetworkInterfaceName(customObject v1alpha1.FlannelConfig) string {
+	return customObject.Spec.Bridge.Spec.Interface
+}
+
+func NetworkNamespace(customObject v1alpha1.FlannelConfig) string {
+	return NetworkID + "-" + ClusterID(customObject)
+}
+
+func NetworkNTPBlock(customObject v1alpha1.FlannelConfig) string {
+	var parts []string
+
+	for _, s := range customObject.Spec.Bridge.Spec.NTP.Servers {
+		parts = append(parts, fmt.Sprintf("NTP=%s", s))
+	}
+
+	return strings.Join(parts, "\n")
+}
+
+func NetworkTapName(customObject v1alpha1.FlannelConfig) string {
+	return "tap-" + ClusterID(customObject)
+}
+
+func ServiceAccountName(customResource v1alpha1.FlannelConfig) string {
+	return ClusterID(customResource)
+}
+
+// ToCustomObject returns the given FlannelConfig as v1alpha1. v1alpha2
+// FlannelConfigs are converted, so that all accessors of this package work on
+// both API versions.
+func ToCustomObject(v interface{}) (v1alpha1.FlannelConfig, error) {
+	switch customObjectPointer := v.(type) {
+	case *v1alpha1.FlannelConfig:
+		return *customObjectPointer, nil
+	case *v1alpha2.FlannelConfig:
+		customObject, err := v1alpha2.ConvertToV1alpha1(customObjectPointer)
+		if err != nil {
+			return v1alpha1.FlannelConfig{}, microerror.Mask(err)
+		}
+
+		return *customObject, nil
+	default:
+		return v1alpha1.FlannelConfig{}, microerror.Maskf(wrongTypeError, "expected '%T' or '%T', got '%T'", &v1alpha1.FlannelConfig{}, &v1alpha2.FlannelConfig{}, v)
+	}
+}
+
+func VersionBundleVersion(customObject v1alpha1.FlannelConfig) string {
+	return customObject.Spec.VersionBundle.Version
+}
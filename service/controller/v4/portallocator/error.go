@@ -0,0 +1,39 @@
+package portallocator
+
+import "github.com/giantswarm/microerror"
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var noPortAvailableError = &microerror.Error{
+	Kind: "noPortAvailableError",
+}
+
+// IsNoPortAvailable asserts noPortAvailableError.
+func IsNoPortAvailable(err error) bool {
+	return microerror.Cause(err) == noPortAvailableError
+}
+
+var portConflictError = &microerror.Error{
+	Kind: "portConflictError",
+}
+
+// IsPortConflict asserts portConflictError.
+func IsPortConflict(err error) bool {
+	return microerror.Cause(err) == portConflictError
+}
+
+var updateConflictError = &microerror.Error{
+	Kind: "updateConflictError",
+}
+
+// IsUpdateConflict asserts updateConflictError.
+func IsUpdateConflict(err error) bool {
+	return microerror.Cause(err) == updateConflictError
+}
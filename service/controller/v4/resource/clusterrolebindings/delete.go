@@ -0,0 +1,79 @@
+package clusterrolebindings
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller/context/finalizerskeptcontext"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/flannel-operator/service/controller/v4/key"
+)
+
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	// The cluster role bindings are needed as long as network pods or
+	// destroyer pods may run. That is the case until both the network
+	// namespace and the destroyer namespace are gone.
+	for _, n := range []string{key.NetworkNamespace(customObject), key.DestroyerNamespace(customObject)} {
+		inUse, err := r.isNamespaceInUse(n)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		if inUse {
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("cannot delete cluster role bindings due to existing namespace %#q", n))
+
+			finalizerskeptcontext.SetKept(ctx)
+			r.logger.LogCtx(ctx, "level", "debug", "message", "keeping finalizers")
+
+			return nil
+		}
+	}
+
+	bindings := append(newClusterRoleBindings(customObject), newPodSecurityPolicyClusterRoleBindings(customObject)...)
+	for _, b := range bindings {
+		err := r.deleteClusterRoleBinding(ctx, b.GetName())
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
+
+func (r *Resource) deleteClusterRoleBinding(ctx context.Context, name string) error {
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("deleting cluster role binding %#q", name))
+
+	err := r.k8sClient.RbacV1().ClusterRoleBindings().Delete(name, &metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("cluster role binding %#q does not exist", name))
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("deleted cluster role binding %#q", name))
+
+	return nil
+}
+
+// isNamespaceInUse returns whether the given namespace exists and is not
+// terminating.
+func (r *Resource) isNamespaceInUse(name string) (bool, error) {
+	n, err := r.k8sClient.CoreV1().Namespaces().Get(name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return n.Status.Phase != corev1.NamespaceTerminating, nil
+}
@@ -0,0 +1,57 @@
+package deletionprotection
+
+import (
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/giantswarm/flannel-operator/pkg/status"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "deletionprotectionv4"
+)
+
+// Config represents the configuration used to create a new deletion
+// protection resource.
+type Config struct {
+	EventRecorder record.EventRecorder
+	Logger        micrologger.Logger
+	StatusWriter  status.Interface
+}
+
+// Resource implements the deletion protection resource. It runs right after
+// the paused resource, before any resource tearing down parts of the network,
+// and holds the network teardown of deleted FlannelConfigs which carry the
+// deletion protection annotation, see key.AnnotationDeletionProtection.
+type Resource struct {
+	eventRecorder record.EventRecorder
+	logger        micrologger.Logger
+	statusWriter  status.Interface
+}
+
+// New creates a new configured deletion protection resource.
+func New(config Config) (*Resource, error) {
+	if config.EventRecorder == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.EventRecorder must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.StatusWriter == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.StatusWriter must not be empty", config)
+	}
+
+	r := &Resource{
+		eventRecorder: config.EventRecorder,
+		logger:        config.Logger,
+		statusWriter:  config.StatusWriter,
+	}
+
+	return r, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
@@ -0,0 +1,512 @@
+package flanneld
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/flannel-operator/pkg/ownership"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/key"
+)
+
+const (
+	envHostIP = "HOST_IP"
+)
+
+var (
+	containersPrivileged = true
+	healthEndpoint       = "/healthz"
+	probeHost            = "127.0.0.1"
+)
+
+func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interface{}, error) {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", "computing the desired daemon set")
+
+	options, err := r.options.withOverrides(customObject)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	healthPort, err := r.portAllocator.HealthPort(ctx, customObject)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	// Health endpoints are only reachable from within the node unless they have
+	// to be scraped by Prometheus. In that case they bind to the host IP and
+	// the flanneld health endpoint is enabled on an allocated port.
+	endpoints := endpoints{
+		HealthPort: healthPort,
+		ListenIP:   probeHost,
+		ProbeHost:  probeHost,
+	}
+	if r.monitoringEnabled {
+		endpoints.ListenIP = "$(" + envHostIP + ")"
+		endpoints.ProbeHost = ""
+	}
+
+	// The flanneld health endpoint binds to a host port as well. Ports
+	// requested via key.AnnotationFlanneldHealthzPort and the operator wide
+	// port are validated by the port allocator like any other port, so that
+	// they neither collide with other clusters nor with reserved ports.
+	if r.monitoringEnabled || options.HealthzPort != 0 {
+		options.HealthzPort, err = r.portAllocator.FlanneldHealthzPort(ctx, customObject)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	probes := probes{
+		Liveness:  r.livenessProbe,
+		Readiness: r.readinessProbe,
+	}
+
+	// Tenant clusters may use a different etcd cluster than the operator wide
+	// one, see key.AnnotationEtcdEndpoints.
+	etcdEndpoints, err := key.EtcdEndpoints(customObject)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if len(etcdEndpoints) == 0 {
+		etcdEndpoints = r.etcdEndpoints
+	}
+
+	// flanneld only reads its etcd certificates on startup. The secret the
+	// network pods mount is reconciled by the secret resource before, so that
+	// its checksum reflects rotated certificates of the current loop. The
+	// secret is gone on deletion, in which case the pods are not annotated.
+	var certsChecksum string
+	{
+		secret, err := r.k8sClient.CoreV1().Secrets(key.NetworkNamespace(customObject)).Get(key.EtcdCertsSecretName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			// fall through
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		} else {
+			certsChecksum = key.EtcdCertsChecksum(secret.Data)
+		}
+	}
+
+	daemonSet := newDaemonSet(customObject, etcdEndpoints, options, probes, endpoints, certsChecksum)
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", "computed the desired daemon set")
+
+	return daemonSet, nil
+}
+
+type probes struct {
+	Liveness  Probe
+	Readiness Probe
+}
+
+// endpoints describes where the health endpoints of the network containers
+// listen and where the kubelet probes them.
+type endpoints struct {
+	HealthPort int
+	// ListenIP is the IP the health endpoints bind to.
+	ListenIP string
+	// ProbeHost is the host the kubelet sends probes to. Empty means the pod IP,
+	// which is the node IP for host network pods.
+	ProbeHost string
+}
+
+func (e endpoints) healthListenAddress() string {
+	return "http://" + e.ListenIP + ":" + strconv.Itoa(e.HealthPort)
+}
+
+// flanneldReadinessProbe checks the flanneld health endpoint in case it is
+// enabled. Otherwise flanneld is considered ready as soon as the network health
+// endpoint reports the flannel device to be set up.
+func flanneldReadinessProbe(p Probe, options Options, endpoints endpoints) *corev1.Probe {
+	if options.HealthzPort != 0 {
+		return newHTTPProbe(p, endpoints.ProbeHost, options.HealthzPort)
+	}
+
+	return newHTTPProbe(p, endpoints.ProbeHost, endpoints.HealthPort)
+}
+
+// flanneldPorts declares the flanneld health endpoint in case it is enabled so
+// that it can be referenced by name.
+func flanneldPorts(options Options) []corev1.ContainerPort {
+	if options.HealthzPort == 0 {
+		return nil
+	}
+
+	ports := []corev1.ContainerPort{
+		{
+			Name:          key.PortNameHealthz,
+			ContainerPort: int32(options.HealthzPort),
+			Protocol:      corev1.ProtocolTCP,
+		},
+	}
+
+	return ports
+}
+
+// flanneldArgs returns the arguments of the flanneld container. Values taken
+// from the custom object are escaped, see escapeArg.
+func flanneldArgs(customObject v1alpha1.FlannelConfig, etcdEndpoints []string, options Options, endpoints endpoints) []string {
+	args := []string{
+		"--etcd-endpoints=" + escapeArg(strings.Join(etcdEndpoints, ",")),
+		"--etcd-cafile=" + key.EtcdCAFilePath(),
+		"--etcd-certfile=" + key.EtcdCrtFilePath(),
+		"--etcd-keyfile=" + key.EtcdKeyFilePath(),
+		"--etcd-prefix=" + escapeArg(key.EtcdPrefix(customObject)),
+		"--iface=" + escapeArg(key.NetworkInterfaceName(customObject)),
+		"--subnet-file=" + escapeArg(key.NetworkEnvFilePath(customObject)),
+	}
+
+	return append(args, options.args(endpoints.ListenIP)...)
+}
+
+// podAnnotations returns the annotations of the network pods. The checksum of
+// the mounted etcd certificates is recorded, so that rotating them rolls the
+// network pods.
+func podAnnotations(certsChecksum string) map[string]string {
+	if certsChecksum == "" {
+		return nil
+	}
+
+	annotations := map[string]string{
+		key.AnnotationEtcdCertsChecksum: certsChecksum,
+	}
+
+	return annotations
+}
+
+func newDaemonSet(customObject v1alpha1.FlannelConfig, etcdEndpoints []string, options Options, probes probes, endpoints endpoints, certsChecksum string) *appsv1.DaemonSet {
+	daemonSet := &appsv1.DaemonSet{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "DaemonSet",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.NetworkID,
+			Namespace: key.NetworkNamespace(customObject),
+			Annotations: map[string]string{
+				VersionBundleVersionAnnotation: key.VersionBundleVersion(customObject),
+			},
+			Labels: map[string]string{
+				"app":      key.NetworkID,
+				"cluster":  key.ClusterID(customObject),
+				"customer": key.ClusterCustomer(customObject),
+			},
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app":     key.NetworkID,
+					"cluster": key.ClusterID(customObject),
+				},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					GenerateName: key.NetworkID,
+					Annotations:  podAnnotations(certsChecksum),
+					Labels: map[string]string{
+						"app":      key.NetworkID,
+						"cluster":  key.ClusterID(customObject),
+						"customer": key.ClusterCustomer(customObject),
+					},
+				},
+				Spec: corev1.PodSpec{
+					HostNetwork: true,
+					HostPID:     true,
+					Containers: []corev1.Container{
+						{
+							Name:            "flanneld",
+							Image:           key.FlannelDockerImage,
+							ImagePullPolicy: corev1.PullAlways,
+							Command: []string{
+								"/opt/bin/flanneld",
+							},
+							Args:  flanneldArgs(customObject, etcdEndpoints, options, endpoints),
+							Ports: flanneldPorts(options),
+							Env: []corev1.EnvVar{
+								{
+									Name: envHostIP,
+									ValueFrom: &corev1.EnvVarSource{
+										FieldRef: &corev1.ObjectFieldSelector{
+											FieldPath: "status.hostIP",
+										},
+									},
+								},
+								{
+									Name:  "ETCD_CA",
+									Value: key.EtcdCAFilePath(),
+								},
+								{
+									Name:  "ETCD_CRT",
+									Value: key.EtcdCrtFilePath(),
+								},
+								{
+									Name:  "ETCD_KEY",
+									Value: key.EtcdKeyFilePath(),
+								},
+								{
+									Name:  "ETCD_PREFIX",
+									Value: key.EtcdPrefix(customObject),
+								},
+								{
+									Name:  "NETWORK_BRIDGE_NAME",
+									Value: key.NetworkBridgeName(customObject),
+								},
+								{
+									Name:  "NETWORK_ENV_FILE_PATH",
+									Value: key.NetworkEnvFilePath(customObject),
+								},
+								{
+									Name:  "NETWORK_INTERFACE_NAME",
+									Value: key.NetworkInterfaceName(customObject),
+								},
+							},
+							LivenessProbe:  newHTTPProbe(probes.Liveness, endpoints.ProbeHost, endpoints.HealthPort),
+							ReadinessProbe: flanneldReadinessProbe(probes.Readiness, options, endpoints),
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "etcd-certs",
+									MountPath: key.EtcdCertsMountPath,
+									ReadOnly:  true,
+								},
+								{
+									Name:      "flannel",
+									MountPath: "/run/flannel",
+								},
+								{
+									Name:      "ssl",
+									MountPath: "/etc/ssl/certs",
+								},
+							},
+							SecurityContext: &corev1.SecurityContext{
+								Privileged: &containersPrivileged,
+							},
+						},
+						{
+							Name:            "k8s-network-bridge",
+							Image:           key.NetworkBridgeDockerImage(customObject),
+							ImagePullPolicy: corev1.PullAlways,
+							Command: []string{
+								"/bin/sh",
+								"-c",
+								"while [ ! -f ${NETWORK_ENV_FILE_PATH} ]; do echo \"Waiting for ${NETWORK_ENV_FILE_PATH} to be created\"; sleep 1; done; /docker-entrypoint.sh create ${NETWORK_ENV_FILE_PATH} ${NETWORK_BRIDGE_NAME} ${NETWORK_INTERFACE_NAME} ${HOST_PRIVATE_NETWORK}",
+							},
+							Env: []corev1.EnvVar{
+								{
+									Name:  "HOST_PRIVATE_NETWORK",
+									Value: key.HostPrivateNetwork(customObject),
+								},
+								{
+									Name:  "NETWORK_BRIDGE_NAME",
+									Value: key.NetworkBridgeName(customObject),
+								},
+								{
+									Name:  "NETWORK_DNS_BLOCK",
+									Value: key.NetworkDNSBlock(customObject),
+								},
+								{
+									Name:  "NETWORK_ENV_FILE_PATH",
+									Value: key.NetworkEnvFilePath(customObject),
+								},
+								{
+									Name:  "NETWORK_FLANNEL_DEVICE",
+									Value: key.NetworkFlannelDevice(customObject),
+								},
+								{
+									Name:  "NETWORK_INTERFACE_NAME",
+									Value: key.NetworkInterfaceName(customObject),
+								},
+								{
+									Name:  "NETWORK_NTP_BLOCK",
+									Value: key.NetworkNTPBlock(customObject),
+								},
+								{
+									Name:  "NETWORK_TAP_NAME",
+									Value: key.NetworkTapName(customObject),
+								},
+							},
+							LivenessProbe:  newHTTPProbe(probes.Liveness, endpoints.ProbeHost, endpoints.HealthPort),
+							ReadinessProbe: newHTTPProbe(probes.Readiness, endpoints.ProbeHost, endpoints.HealthPort),
+							SecurityContext: &corev1.SecurityContext{
+								Privileged: &containersPrivileged,
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "cgroup",
+									MountPath: "/sys/fs/cgroup",
+								},
+								{
+									Name:      "dbus",
+									MountPath: "/var/run/dbus",
+								},
+								{
+									Name:      "environment",
+									MountPath: "/etc/environment",
+								},
+								{
+									Name:      "etc-systemd",
+									MountPath: "/etc/systemd/",
+								},
+								{
+									Name:      "flannel",
+									MountPath: "/run/flannel",
+								},
+								{
+									Name:      "systemd",
+									MountPath: "/run/systemd",
+								},
+								{
+									Name:      "sys-class-net",
+									MountPath: "/sys/class/net/",
+								},
+							},
+						},
+						{
+							Name:            "flannel-network-health",
+							Image:           key.NetworkHealthDockerImage(customObject),
+							ImagePullPolicy: corev1.PullAlways,
+							ReadinessProbe:  newHTTPProbe(probes.Readiness, endpoints.ProbeHost, endpoints.HealthPort),
+							Ports: []corev1.ContainerPort{
+								{
+									Name:          key.PortNameMetrics,
+									ContainerPort: int32(endpoints.HealthPort),
+									Protocol:      corev1.ProtocolTCP,
+								},
+							},
+							Env: []corev1.EnvVar{
+								{
+									Name: envHostIP,
+									ValueFrom: &corev1.EnvVarSource{
+										FieldRef: &corev1.ObjectFieldSelector{
+											FieldPath: "status.hostIP",
+										},
+									},
+								},
+								{
+									Name:  "LISTEN_ADDRESS",
+									Value: endpoints.healthListenAddress(),
+								},
+								{
+									Name:  "NETWORK_BRIDGE_NAME",
+									Value: key.NetworkBridgeName(customObject),
+								},
+								{
+									Name:  "NETWORK_ENV_FILE_PATH",
+									Value: key.NetworkEnvFilePath(customObject),
+								},
+								{
+									Name:  "NETWORK_FLANNEL_DEVICE",
+									Value: key.NetworkFlannelDevice(customObject),
+								},
+							},
+							SecurityContext: &corev1.SecurityContext{
+								Privileged: &containersPrivileged,
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "flannel",
+									MountPath: "/run/flannel",
+								},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "cgroup",
+							VolumeSource: corev1.VolumeSource{
+								HostPath: &corev1.HostPathVolumeSource{
+									Path: "/sys/fs/cgroup",
+								},
+							},
+						},
+						{
+							Name: "dbus",
+							VolumeSource: corev1.VolumeSource{
+								HostPath: &corev1.HostPathVolumeSource{
+									Path: "/var/run/dbus",
+								},
+							},
+						},
+						{
+							Name: "environment",
+							VolumeSource: corev1.VolumeSource{
+								HostPath: &corev1.HostPathVolumeSource{
+									Path: "/etc/environment",
+								},
+							},
+						},
+						{
+							Name: "etcd-certs",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{
+									SecretName: key.EtcdCertsSecretName,
+								},
+							},
+						},
+						{
+							Name: "etc-systemd",
+							VolumeSource: corev1.VolumeSource{
+								HostPath: &corev1.HostPathVolumeSource{
+									Path: "/etc/systemd/",
+								},
+							},
+						},
+						{
+							Name: "flannel",
+							VolumeSource: corev1.VolumeSource{
+								HostPath: &corev1.HostPathVolumeSource{
+									Path: key.FlannelRunDir(customObject),
+								},
+							},
+						},
+						{
+							Name: "ssl",
+							VolumeSource: corev1.VolumeSource{
+								HostPath: &corev1.HostPathVolumeSource{
+									Path: "/etc/ssl/certs",
+								},
+							},
+						},
+						{
+							Name: "systemd",
+							VolumeSource: corev1.VolumeSource{
+								HostPath: &corev1.HostPathVolumeSource{
+									Path: "/run/systemd",
+								},
+							},
+						},
+						{
+							Name: "sys-class-net",
+							VolumeSource: corev1.VolumeSource{
+								HostPath: &corev1.HostPathVolumeSource{
+									Path: "/sys/class/net/",
+								},
+							},
+						},
+					},
+					ServiceAccountName: key.ServiceAccountName(customObject),
+				},
+			},
+			UpdateStrategy: appsv1.DaemonSetUpdateStrategy{
+				Type: appsv1.RollingUpdateDaemonSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDaemonSet{
+					MaxUnavailable: key.MaxUnavailable(),
+				},
+			},
+		},
+	}
+	ownership.Set(daemonSet, customObject)
+
+	return daemonSet
+}
@@ -0,0 +1,157 @@
+package flanneld
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/flannel-operator/service/controller/v4/key"
+)
+
+const (
+	// PublicIPHost is the special value of Options.PublicIP which makes flanneld
+	// use the IP of the node it is running on as public IP. The IP is injected
+	// into the flanneld container using the downward API.
+	PublicIPHost = "host"
+
+	maxSubnetLeaseRenewMargin = 24*60 - 1
+	maxVerbosity              = 10
+)
+
+// Options is the set of additional flanneld command line options. Operator wide
+// defaults are configured via flags and can be overridden per tenant cluster
+// using the key.AnnotationFlanneld* annotations on the FlannelConfig.
+type Options struct {
+	// HealthzPort is the port flanneld serves its health endpoint on. Zero
+	// disables the endpoint. The port is only preferred when allocating the
+	// host port of the endpoint, see portallocator.Config.
+	HealthzPort int
+	// IfaceRegex is a regular expression flanneld uses to find the interface it
+	// binds to, in case the configured interface cannot be found.
+	IfaceRegex string
+	// IPMasq enables IP masquerading for traffic leaving the flannel network.
+	IPMasq bool
+	// PublicIP is either empty, PublicIPHost or an IP address flanneld announces
+	// to its peers.
+	PublicIP string
+	// SubnetLeaseRenewMargin is the number of minutes before the subnet lease
+	// expires at which flanneld renews it. Zero uses the flanneld default.
+	SubnetLeaseRenewMargin int
+	// Verbosity is the flanneld log level.
+	Verbosity int
+}
+
+// Validate returns an invalidConfigError in case any option holds a value
+// flanneld would not accept.
+func (o Options) Validate() error {
+	if o.HealthzPort < 0 || o.HealthzPort > 65535 {
+		return microerror.Maskf(invalidConfigError, "flanneld healthz port must be between 0 and 65535, got %d", o.HealthzPort)
+	}
+	if o.IfaceRegex != "" {
+		_, err := regexp.Compile(o.IfaceRegex)
+		if err != nil {
+			return microerror.Maskf(invalidConfigError, "flanneld iface regex %#q must be a valid regular expression: %s", o.IfaceRegex, err)
+		}
+		if strings.ContainsAny(o.IfaceRegex, "\x00\n\r") {
+			return microerror.Maskf(invalidConfigError, "flanneld iface regex %#q must not contain control characters", o.IfaceRegex)
+		}
+	}
+	if o.PublicIP != "" && o.PublicIP != PublicIPHost && net.ParseIP(o.PublicIP) == nil {
+		return microerror.Maskf(invalidConfigError, "flanneld public IP must be empty, %#q or a valid IP address, got %#q", PublicIPHost, o.PublicIP)
+	}
+	if o.SubnetLeaseRenewMargin < 0 || o.SubnetLeaseRenewMargin > maxSubnetLeaseRenewMargin {
+		return microerror.Maskf(invalidConfigError, "flanneld subnet lease renew margin must be between 0 and %d minutes, got %d", maxSubnetLeaseRenewMargin, o.SubnetLeaseRenewMargin)
+	}
+	if o.Verbosity < 0 || o.Verbosity > maxVerbosity {
+		return microerror.Maskf(invalidConfigError, "flanneld verbosity must be between 0 and %d, got %d", maxVerbosity, o.Verbosity)
+	}
+
+	return nil
+}
+
+// args renders the options as flanneld command line arguments. The arguments
+// are passed to the container without any shell being involved. Kubernetes
+// still expands $(VAR) references in container arguments, which is why
+// user provided values are escaped. The health endpoint binds to the given IP.
+func (o Options) args(healthzIP string) []string {
+	var args []string
+
+	if o.HealthzPort != 0 {
+		args = append(args, "--healthz-ip="+healthzIP)
+		args = append(args, "--healthz-port="+strconv.Itoa(o.HealthzPort))
+	}
+	if o.IfaceRegex != "" {
+		args = append(args, "--iface-regex="+escapeArg(o.IfaceRegex))
+	}
+	if o.IPMasq {
+		args = append(args, "--ip-masq")
+	}
+	if o.PublicIP == PublicIPHost {
+		args = append(args, "--public-ip=$("+envHostIP+")")
+	} else if o.PublicIP != "" {
+		args = append(args, "--public-ip="+o.PublicIP)
+	}
+	if o.SubnetLeaseRenewMargin != 0 {
+		args = append(args, "--subnet-lease-renew-margin="+strconv.Itoa(o.SubnetLeaseRenewMargin))
+	}
+	args = append(args, fmt.Sprintf("-v=%d", o.Verbosity))
+
+	return args
+}
+
+// withOverrides returns a copy of the options with the per cluster overrides
+// of the given custom object applied. The result is validated.
+func (o Options) withOverrides(customObject v1alpha1.FlannelConfig) (Options, error) {
+	var err error
+
+	annotations := customObject.GetAnnotations()
+
+	if v, ok := annotations[key.AnnotationFlanneldHealthzPort]; ok {
+		o.HealthzPort, err = strconv.Atoi(v)
+		if err != nil {
+			return Options{}, microerror.Maskf(invalidConfigError, "annotation %#q must be an integer, got %#q", key.AnnotationFlanneldHealthzPort, v)
+		}
+	}
+	if v, ok := annotations[key.AnnotationFlanneldIfaceRegex]; ok {
+		o.IfaceRegex = v
+	}
+	if v, ok := annotations[key.AnnotationFlanneldIPMasq]; ok {
+		o.IPMasq, err = strconv.ParseBool(v)
+		if err != nil {
+			return Options{}, microerror.Maskf(invalidConfigError, "annotation %#q must be a boolean, got %#q", key.AnnotationFlanneldIPMasq, v)
+		}
+	}
+	if v, ok := annotations[key.AnnotationFlanneldPublicIP]; ok {
+		o.PublicIP = v
+	}
+	if v, ok := annotations[key.AnnotationFlanneldSubnetLeaseRenewMargin]; ok {
+		o.SubnetLeaseRenewMargin, err = strconv.Atoi(v)
+		if err != nil {
+			return Options{}, microerror.Maskf(invalidConfigError, "annotation %#q must be an integer, got %#q", key.AnnotationFlanneldSubnetLeaseRenewMargin, v)
+		}
+	}
+	if v, ok := annotations[key.AnnotationFlanneldVerbosity]; ok {
+		o.Verbosity, err = strconv.Atoi(v)
+		if err != nil {
+			return Options{}, microerror.Maskf(invalidConfigError, "annotation %#q must be an integer, got %#q", key.AnnotationFlanneldVerbosity, v)
+		}
+	}
+
+	err = o.Validate()
+	if err != nil {
+		return Options{}, microerror.Mask(err)
+	}
+
+	return o, nil
+}
+
+// escapeArg escapes Kubernetes variable references so that the given value is
+// passed to flanneld verbatim.
+func escapeArg(s string) string {
+	return strings.Replace(s, "$", "$$", -1)
+}
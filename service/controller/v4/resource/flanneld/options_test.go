@@ -0,0 +1,128 @@
+package flanneld
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_Resource_Flanneld_Options_withOverrides(t *testing.T) {
+	testCases := []struct {
+		Name         string
+		Options      Options
+		Annotations  map[string]string
+		ExpectedArgs []string
+		ErrorMatcher func(error) bool
+	}{
+		{
+			Name:         "case 0: defaults render only the verbosity",
+			Options:      Options{},
+			ExpectedArgs: []string{"-v=0"},
+		},
+		{
+			Name: "case 1: operator wide options are rendered",
+			Options: Options{
+				IfaceRegex:             "^eth[0-9]+$",
+				IPMasq:                 true,
+				PublicIP:               PublicIPHost,
+				SubnetLeaseRenewMargin: 60,
+				Verbosity:              1,
+			},
+			ExpectedArgs: []string{
+				"--iface-regex=^eth[0-9]+$$",
+				"--ip-masq",
+				"--public-ip=$(HOST_IP)",
+				"--subnet-lease-renew-margin=60",
+				"-v=1",
+			},
+		},
+		{
+			Name:    "case 2: annotations override operator wide options",
+			Options: Options{IPMasq: true},
+			Annotations: map[string]string{
+				"flannel-operator.giantswarm.io/flanneld-healthz-port": "21001",
+				"flannel-operator.giantswarm.io/flanneld-ip-masq":      "false",
+				"flannel-operator.giantswarm.io/flanneld-public-ip":    "10.0.0.1",
+				"flannel-operator.giantswarm.io/flanneld-verbosity":    "5",
+			},
+			ExpectedArgs: []string{
+				"--healthz-ip=127.0.0.1",
+				"--healthz-port=21001",
+				"--public-ip=10.0.0.1",
+				"-v=5",
+			},
+		},
+		{
+			Name: "case 3: shell meta characters are passed verbatim",
+			Annotations: map[string]string{
+				"flannel-operator.giantswarm.io/flanneld-iface-regex": "eth0; rm -rf / $(whoami)",
+			},
+			ExpectedArgs: []string{
+				"--iface-regex=eth0; rm -rf / $$(whoami)",
+				"-v=0",
+			},
+		},
+		{
+			Name: "case 4: invalid public IP is rejected",
+			Annotations: map[string]string{
+				"flannel-operator.giantswarm.io/flanneld-public-ip": "10.0.0.1 --ip-masq",
+			},
+			ErrorMatcher: IsInvalidConfig,
+		},
+		{
+			Name: "case 5: invalid iface regex is rejected",
+			Annotations: map[string]string{
+				"flannel-operator.giantswarm.io/flanneld-iface-regex": "eth[",
+			},
+			ErrorMatcher: IsInvalidConfig,
+		},
+		{
+			Name: "case 6: out of range verbosity is rejected",
+			Annotations: map[string]string{
+				"flannel-operator.giantswarm.io/flanneld-verbosity": "11",
+			},
+			ErrorMatcher: IsInvalidConfig,
+		},
+		{
+			Name: "case 7: non numeric healthz port is rejected",
+			Annotations: map[string]string{
+				"flannel-operator.giantswarm.io/flanneld-healthz-port": "http",
+			},
+			ErrorMatcher: IsInvalidConfig,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			customObject := v1alpha1.FlannelConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: tc.Annotations,
+				},
+			}
+
+			options, err := tc.Options.withOverrides(customObject)
+
+			switch {
+			case err == nil && tc.ErrorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.ErrorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.ErrorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.ErrorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if tc.ErrorMatcher != nil {
+				return
+			}
+
+			args := options.args(probeHost)
+			if !reflect.DeepEqual(tc.ExpectedArgs, args) {
+				t.Fatalf("expected %#v got %#v", tc.ExpectedArgs, args)
+			}
+		})
+	}
+}
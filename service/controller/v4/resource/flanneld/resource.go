@@ -0,0 +1,126 @@
+package flanneld
+
+import (
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/giantswarm/flannel-operator/pkg/apply"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/portallocator"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/workloadgate"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "flanneldv4"
+)
+
+// Config represents the configuration used to create a new cloud config
+// resource.
+type Config struct {
+	Applier       apply.Interface
+	EtcdEndpoints []string
+	K8sClient     kubernetes.Interface
+	Logger        micrologger.Logger
+	PortAllocator portallocator.Interface
+	WorkloadGate  workloadgate.Interface
+
+	// MonitoringEnabled exposes the health endpoints of the network containers
+	// on the host IP so that they can be scraped by Prometheus.
+	MonitoringEnabled bool
+	// LivenessProbe and ReadinessProbe configure the probes of the network
+	// containers.
+	LivenessProbe  Probe
+	ReadinessProbe Probe
+	// Options are the operator wide flanneld options. They can be overridden
+	// per tenant cluster, see Options.
+	Options Options
+}
+
+// Resource implements the cloud config resource.
+type Resource struct {
+	applier       apply.Interface
+	etcdEndpoints []string
+	k8sClient     kubernetes.Interface
+	logger        micrologger.Logger
+	portAllocator portallocator.Interface
+	workloadGate  workloadgate.Interface
+
+	livenessProbe     Probe
+	monitoringEnabled bool
+	readinessProbe    Probe
+	options           Options
+}
+
+// New creates a new configured cloud config resource.
+func New(config Config) (*Resource, error) {
+	if config.Applier == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Applier must not be empty", config)
+	}
+	if len(config.EtcdEndpoints) == 0 {
+		return nil, microerror.Maskf(invalidConfigError, "config.EtcdEndpoints must not be empty")
+	}
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.PortAllocator == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.PortAllocator must not be empty", config)
+	}
+	if config.WorkloadGate == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.WorkloadGate must not be empty", config)
+	}
+
+	err := config.LivenessProbe.Validate()
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.LivenessProbe must be valid: %s", config, err)
+	}
+	// Kubernetes only accepts a success threshold of 1 for liveness probes.
+	if config.LivenessProbe.SuccessThreshold != 1 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.LivenessProbe.SuccessThreshold must be 1", config)
+	}
+	err = config.ReadinessProbe.Validate()
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ReadinessProbe must be valid: %s", config, err)
+	}
+	err = config.Options.Validate()
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Options must be valid: %s", config, err)
+	}
+
+	r := &Resource{
+		applier:       config.Applier,
+		etcdEndpoints: config.EtcdEndpoints,
+		k8sClient:     config.K8sClient,
+		logger:        config.Logger,
+		portAllocator: config.PortAllocator,
+		workloadGate:  config.WorkloadGate,
+
+		livenessProbe:     config.LivenessProbe,
+		monitoringEnabled: config.MonitoringEnabled,
+		readinessProbe:    config.ReadinessProbe,
+		options:           config.Options,
+	}
+
+	return r, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
+
+func toDaemonSet(v interface{}) (*appsv1.DaemonSet, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	daemonSet, ok := v.(*appsv1.DaemonSet)
+	if !ok {
+		return nil, microerror.Maskf(wrongTypeError, "expected '%T', got '%T'", &appsv1.DaemonSet{}, v)
+	}
+
+	return daemonSet, nil
+}
@@ -0,0 +1,71 @@
+package flanneld
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/resource/crud"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+func (r *Resource) ApplyUpdateChange(ctx context.Context, obj, updateChange interface{}) error {
+	daemonSetToUpdate, err := toDaemonSet(updateChange)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if daemonSetToUpdate != nil {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "updating the daemon set in the Kubernetes API")
+
+		err = r.applier.Apply(ctx, daemonSetToUpdate)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "updated the daemon set in the Kubernetes API")
+	} else {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "the daemon set does not need to be updated in the Kubernetes API")
+	}
+
+	return nil
+}
+
+func (r *Resource) NewUpdatePatch(ctx context.Context, obj, currentState, desiredState interface{}) (*crud.Patch, error) {
+	create, err := r.newCreateChange(ctx, obj, currentState, desiredState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	update, err := r.newUpdateChange(ctx, obj, currentState, desiredState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	patch := crud.NewPatch()
+	patch.SetCreateChange(create)
+	patch.SetUpdateChange(update)
+
+	return patch, nil
+}
+
+// newUpdateChange returns the desired daemon set in case the daemon set
+// exists. It is server side applied, which makes the API server correct drift
+// of the fields we manage and leaves fields of other managers alone. Applying
+// an unchanged daemon set does not cause a rollout.
+func (r *Resource) newUpdateChange(ctx context.Context, obj, currentState, desiredState interface{}) (interface{}, error) {
+	currentDaemonSet, err := toDaemonSet(currentState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	desiredDaemonSet, err := toDaemonSet(desiredState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var daemonSetToUpdate *appsv1.DaemonSet
+	if currentDaemonSet != nil {
+		daemonSetToUpdate = desiredDaemonSet
+	}
+
+	return daemonSetToUpdate, nil
+}
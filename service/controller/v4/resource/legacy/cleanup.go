@@ -0,0 +1,241 @@
+package legacy
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/giantswarm/flannel-operator/service/controller/v4/key"
+)
+
+// The states of the bridge cleanup of a single node.
+const (
+	cleanupStatePending   = "pending"
+	cleanupStateSucceeded = "succeeded"
+	cleanupStateFailed    = "failed"
+	// cleanupStateGone means the node was removed from the cluster before its
+	// cleanup succeeded. There is nothing left to clean up then.
+	cleanupStateGone = "gone"
+)
+
+const (
+	// maxCleanupAttempts is the number of cleanup jobs created for a single
+	// node before its cleanup is considered failed.
+	maxCleanupAttempts = 3
+	// maxCleanupMessageLength limits the length of the termination messages
+	// recorded in the cleanup nodes annotation.
+	maxCleanupMessageLength = 256
+)
+
+// cleanupNode tracks the bridge cleanup of a single node. The cleanup nodes of
+// a FlannelConfig are recorded in the cleanup nodes annotation. ExitCode and
+// Message hold the result of the latest finished cleanup attempt.
+type cleanupNode struct {
+	Name     string `json:"name"`
+	Attempts int    `json:"attempts"`
+	State    string `json:"state"`
+	ExitCode *int32 `json:"exitCode,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// cleanupResult is the outcome of a single cleanup attempt.
+type cleanupResult struct {
+	ExitCode *int32
+	Message  string
+}
+
+// String returns a human readable description of the result used in events
+// and logs.
+func (r cleanupResult) String() string {
+	if r.ExitCode == nil && r.Message == "" {
+		return "no result reported"
+	}
+	if r.ExitCode == nil {
+		return r.Message
+	}
+	if r.Message == "" {
+		return fmt.Sprintf("exit code %d", *r.ExitCode)
+	}
+
+	return fmt.Sprintf("exit code %d: %s", *r.ExitCode, r.Message)
+}
+
+func cleanupNodesFromAnnotation(customObject v1alpha1.FlannelConfig) ([]cleanupNode, error) {
+	v, ok := customObject.GetAnnotations()[key.AnnotationCleanupNodes]
+	if !ok || v == "" {
+		return nil, nil
+	}
+
+	var nodes []cleanupNode
+	err := json.Unmarshal([]byte(v), &nodes)
+	if err != nil {
+		return nil, microerror.Maskf(invalidAnnotationError, "%#q: %s", key.AnnotationCleanupNodes, err)
+	}
+
+	return nodes, nil
+}
+
+func cleanupNodesToAnnotation(nodes []cleanupNode) (string, error) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+
+	b, err := json.Marshal(nodes)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return string(b), nil
+}
+
+// addNewNodes appends all given nodes which are not tracked yet as pending
+// cleanup nodes. All nodes are targeted, including cordoned ones, assuming
+// that master nodes run kubelets.
+func addNewNodes(tracked []cleanupNode, nodes []corev1.Node) []cleanupNode {
+	known := map[string]bool{}
+	for _, n := range tracked {
+		known[n.Name] = true
+	}
+
+	for _, n := range nodes {
+		if known[n.GetName()] {
+			continue
+		}
+
+		tracked = append(tracked, cleanupNode{
+			Name:     n.GetName(),
+			Attempts: 1,
+			State:    cleanupStatePending,
+		})
+	}
+
+	return tracked
+}
+
+// isCleanupDone returns whether the cleanup of all tracked nodes is over.
+func isCleanupDone(nodes []cleanupNode) bool {
+	for _, n := range nodes {
+		if n.State == cleanupStatePending || n.State == cleanupStateFailed {
+			return false
+		}
+	}
+
+	return true
+}
+
+// blockedReason returns why the teardown cannot proceed, which is the case
+// when the cleanup failed on any node. The failed cleanup of a node is retried
+// when its entry is removed from the cleanup nodes annotation, which is why the
+// reason points to it. The reason is empty when the teardown is not blocked.
+func blockedReason(nodes []cleanupNode) string {
+	var failed []string
+	for _, n := range nodes {
+		if n.State != cleanupStateFailed {
+			continue
+		}
+
+		failed = append(failed, fmt.Sprintf("%s (%s)", n.Name, cleanupResult{ExitCode: n.ExitCode, Message: n.Message}))
+	}
+
+	if len(failed) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(
+		"network bridge cleanup failed on nodes %s; clean up the nodes and remove them from the cluster or from annotation %s to retry",
+		strings.Join(failed, ", "), key.AnnotationCleanupNodes,
+	)
+}
+
+// nodesInState returns the names of the tracked nodes in the given state.
+func nodesInState(nodes []cleanupNode, state string) []string {
+	var names []string
+	for _, n := range nodes {
+		if n.State == state {
+			names = append(names, n.Name)
+		}
+	}
+
+	return names
+}
+
+// jobResult returns the result of the given cleanup job. It is taken from the
+// cleanup container of the job pod which terminated last. In case no container
+// terminated, e.g. because the pod could not start before the job deadline, the
+// message of the failed job condition is used.
+func jobResult(job *batchv1.Job, pods []corev1.Pod) cleanupResult {
+	var latest *corev1.ContainerStateTerminated
+	for _, p := range pods {
+		for _, c := range p.Status.ContainerStatuses {
+			if c.Name != cleanupContainerName {
+				continue
+			}
+
+			t := c.State.Terminated
+			if t == nil {
+				t = c.LastTerminationState.Terminated
+			}
+			if t == nil {
+				continue
+			}
+			if latest == nil || latest.FinishedAt.Before(&t.FinishedAt) {
+				latest = t
+			}
+		}
+	}
+
+	if latest != nil {
+		exitCode := latest.ExitCode
+		message := latest.Message
+		if message == "" {
+			message = latest.Reason
+		}
+
+		return cleanupResult{
+			ExitCode: &exitCode,
+			Message:  truncateMessage(message),
+		}
+	}
+
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+			return cleanupResult{
+				Message: truncateMessage(c.Message),
+			}
+		}
+	}
+
+	return cleanupResult{}
+}
+
+// truncateMessage trims the given termination message and keeps its end, which
+// is where docker-entrypoint.sh reports the error it stopped at.
+func truncateMessage(m string) string {
+	m = strings.TrimSpace(m)
+	if len(m) > maxCleanupMessageLength {
+		i := len(m) - maxCleanupMessageLength
+		for i < len(m) && !utf8.RuneStart(m[i]) {
+			i++
+		}
+		m = "..." + m[i:]
+	}
+
+	return m
+}
+
+func isJobFailed(job *batchv1.Job) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+
+	return false
+}
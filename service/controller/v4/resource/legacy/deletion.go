@@ -0,0 +1,469 @@
+package legacy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller/context/finalizerskeptcontext"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/flannel-operator/pkg/event"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/key"
+)
+
+// newDeleteChange tears down the network of a deleted tenant cluster. The
+// teardown is split into the phases defined in the key package. Every
+// reconciliation executes the step of the current phase once and never
+// blocks. The reached phase is recorded in the deletion phase annotation of the
+// custom object. Recording it causes an update event, which is how the next
+// phase gets picked up without waiting for the resync period. Until the
+// teardown is finalized, the finalizers are kept and the reconciliation is
+// canceled so that the resources after this one do not remove anything the
+// teardown still needs.
+//
+// Steps which still have to wait, e.g. for workloads, terminating namespaces
+// or running cleanup jobs, stay in their phase and record nothing. No event is
+// caused then and the step is only executed again with the next resync of the
+// controller, which is operatorkit's controller.DefaultResyncPeriod of five
+// minutes since the network controller does not configure it. Returning an
+// error instead would not help, since operatorkit does not requeue failed
+// reconciliations either. So every step which is still waiting delays the
+// teardown by up to one resync period. With the waiting steps for pods and
+// namespaces, for the cleanup jobs and for the destroyer namespace, the
+// teardown takes up to about 15 minutes longer than the objects waited for
+// need to go away.
+func (r *Resource) newDeleteChange(ctx context.Context, obj, currentState, desiredState interface{}) (interface{}, error) {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	phase := key.DeletionPhase(customObject)
+
+	var next string
+	switch phase {
+	case "", key.DeletionPhaseWaitingForPods:
+		next, err = r.waitForPods(ctx, customObject)
+	case key.DeletionPhaseNamespacesDeleted:
+		next, err = r.scheduleCleanup(ctx, customObject)
+	case key.DeletionPhaseCleanupScheduled:
+		next, err = r.waitForCleanup(ctx, customObject)
+	case key.DeletionPhaseCleanupDone:
+		next, err = r.finalize(ctx, customObject)
+	case key.DeletionPhaseFinalized:
+		next = key.DeletionPhaseFinalized
+	default:
+		return nil, microerror.Maskf(unknownDeletionPhaseError, "%#q", phase)
+	}
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	if next != phase {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("advancing deletion from phase %#q to phase %#q", phase, next))
+
+		err := r.statusWriter.Write(ctx, customObject, map[string]string{key.AnnotationDeletionPhase: next})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("advanced deletion from phase %#q to phase %#q", phase, next))
+	}
+
+	if next != key.DeletionPhaseFinalized {
+		finalizerskeptcontext.SetKept(ctx)
+		r.logger.LogCtx(ctx, "level", "debug", "message", "keeping finalizers")
+
+		reconciliationcanceledcontext.SetCanceled(ctx)
+		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling reconciliation")
+
+		return nil, nil
+	}
+
+	// The namespaces are gone once the teardown is finalized. So the resources
+	// consulting the workload gate after this one find no workloads anymore
+	// and the state the gate keeps for the FlannelConfig can be dropped.
+	r.workloadGate.Forget(customObject)
+
+	r.logger.Log("info", "finished flannel cleanup for cluster", "cluster", key.ClusterID(customObject))
+
+	return nil, nil
+}
+
+// waitForPods implements the waiting-for-pods phase. In case a cluster
+// deletion happens, we want to delete the guest cluster network. We still need
+// to use the network for resource creation in order to drain nodes on KVM
+// though. So as long as the workload gate holds the deletion we delay the
+// deletion of the network. Afterwards the phase is left as soon as the cluster
+// and network namespaces are gone.
+func (r *Resource) waitForPods(ctx context.Context, customObject v1alpha1.FlannelConfig) (string, error) {
+	{
+		wait, err := r.workloadGate.Wait(ctx, customObject)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+		if wait {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "cannot finish deletion of network due to existing workloads")
+			return key.DeletionPhaseWaitingForPods, nil
+		}
+	}
+
+	// We delete extensions/v1beta1 daemon sets we find. They were once managed
+	// with the legacy resource implementation. The new approach is apps/v1 daemon
+	// sets managed by the flanneld resource implementation. When there is no
+	// daemon set to delete here, the other resource implementation will take
+	// over.
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "deleting the legacy daemon set in the Kubernetes API")
+
+		b := metav1.DeletePropagationBackground
+		o := &metav1.DeleteOptions{
+			PropagationPolicy: &b,
+		}
+
+		err := r.k8sClient.ExtensionsV1beta1().DaemonSets(key.NetworkNamespace(customObject)).Delete(key.NetworkID, o)
+		if apierrors.IsNotFound(err) {
+			// fall through
+		} else if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "deleted the legacy daemon set in the Kubernetes API")
+	}
+
+	// Delete the service account for the daemonset
+	{
+		serviceAccountName := serviceAccountName(customObject.Spec)
+		err := r.k8sClient.CoreV1().ServiceAccounts(key.NetworkNamespace(customObject)).Delete(serviceAccountName, &metav1.DeleteOptions{})
+		if apierrors.IsNotFound(err) {
+			// fall through
+		} else if err != nil {
+			return "", microerror.Mask(err)
+		}
+	}
+
+	for _, n := range []string{key.ClusterNamespace(customObject), key.NetworkNamespace(customObject)} {
+		exists, err := r.namespaceExists(n)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+		if exists {
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("waiting for namespace %#q to be deleted", n))
+			return key.DeletionPhaseWaitingForPods, nil
+		}
+	}
+
+	return key.DeletionPhaseNamespacesDeleted, nil
+}
+
+// scheduleCleanup implements the namespaces-deleted phase. It creates the
+// destroyer namespace, its service account and a bridge cleanup job for every
+// node. The targeted nodes are recorded in the cleanup nodes annotation.
+func (r *Resource) scheduleCleanup(ctx context.Context, customObject v1alpha1.FlannelConfig) (string, error) {
+	spec := customObject.Spec
+
+	// Create namespace for the cleanup job.
+	{
+		ns := newNamespace(customObject, destroyerNamespace(spec))
+		_, err := r.k8sClient.CoreV1().Namespaces().Create(ns)
+		if apierrors.IsAlreadyExists(err) {
+			namespace, err := r.k8sClient.CoreV1().Namespaces().Get(ns.GetName(), metav1.GetOptions{})
+			if err != nil {
+				return "", microerror.Mask(err)
+			}
+
+			if namespace.Status.Phase == corev1.NamespaceTerminating {
+				r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("destroyer namespace is in phase %#q", namespace.Status.Phase))
+				return key.DeletionPhaseNamespacesDeleted, nil
+			}
+		} else if err != nil {
+			return "", microerror.Mask(err)
+		}
+	}
+
+	// Create a service account for the cleanup job.
+	{
+		serviceAccount := newServiceAccount(customObject, key.ClusterID(customObject), destroyerNamespace(spec))
+		err := r.applier.Apply(ctx, serviceAccount)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+	}
+
+	var tracked []cleanupNode
+	{
+		nodes, err := r.k8sClient.CoreV1().Nodes().List(metav1.ListOptions{})
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		tracked = addNewNodes(nil, nodes.Items)
+	}
+
+	for _, n := range tracked {
+		err := r.createJob(ctx, customObject, n)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+	}
+
+	err := r.writeCleanupNodes(ctx, customObject, tracked, "")
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	r.logger.Log("debug", fmt.Sprintf("network bridge cleanup scheduled on %d nodes", len(tracked)), "cluster", spec.Cluster.ID)
+
+	return key.DeletionPhaseCleanupScheduled, nil
+}
+
+// waitForCleanup implements the cleanup-scheduled phase. The cleanup of every
+// tracked node is checked and the result of finished attempts is recorded.
+// Failed cleanup jobs are replaced until maxCleanupAttempts is reached. Nodes
+// failing their last attempt block the teardown, see blockedReason. Nodes which joined the cluster in the
+// meantime are added and nodes which left the cluster are skipped. The phase is
+// left as soon as the cleanup succeeded on all nodes. The destroyer namespace is
+// deleted then.
+func (r *Resource) waitForCleanup(ctx context.Context, customObject v1alpha1.FlannelConfig) (string, error) {
+	spec := customObject.Spec
+
+	tracked, err := cleanupNodesFromAnnotation(customObject)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+	if len(tracked) == 0 {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "network bridge cleanup does not track any node")
+		return key.DeletionPhaseNamespacesDeleted, nil
+	}
+
+	existing := map[string]bool{}
+	{
+		nodes, err := r.k8sClient.CoreV1().Nodes().List(metav1.ListOptions{})
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		for _, n := range nodes.Items {
+			existing[n.GetName()] = true
+		}
+
+		known := len(tracked)
+		tracked = addNewNodes(tracked, nodes.Items)
+
+		// Nodes tracked before may have been removed from the cleanup nodes
+		// annotation in order to retry their cleanup. Their old jobs are removed
+		// so that the retry starts over.
+		for _, t := range tracked[known:] {
+			err := r.deleteJobs(ctx, customObject, t.Name)
+			if err != nil {
+				return "", microerror.Mask(err)
+			}
+		}
+	}
+
+	for i := range tracked {
+		n := &tracked[i]
+
+		if n.State != cleanupStatePending && n.State != cleanupStateFailed {
+			continue
+		}
+		if !existing[n.Name] {
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("node %#q left the cluster before its network bridge cleanup succeeded", n.Name))
+			n.State = cleanupStateGone
+			continue
+		}
+		if n.State == cleanupStateFailed {
+			continue
+		}
+
+		job, err := r.k8sClient.BatchV1().Jobs(destroyerNamespace(spec)).Get(jobName(n.Name, n.Attempts), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			err := r.createJob(ctx, customObject, *n)
+			if err != nil {
+				return "", microerror.Mask(err)
+			}
+			continue
+		} else if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		if job.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if job.Status.Succeeded == 0 && !isJobFailed(job) {
+			continue
+		}
+
+		var result cleanupResult
+		{
+			pods, err := r.k8sClient.CoreV1().Pods(destroyerNamespace(spec)).List(metav1.ListOptions{
+				LabelSelector: fmt.Sprintf("%s=%s", jobNameLabel, job.GetName()),
+			})
+			if err != nil {
+				return "", microerror.Mask(err)
+			}
+
+			result = jobResult(job, pods.Items)
+			n.ExitCode = result.ExitCode
+			n.Message = result.Message
+		}
+
+		if job.Status.Succeeded > 0 {
+			n.State = cleanupStateSucceeded
+			r.eventRecorder.Eventf(&customObject, corev1.EventTypeNormal, event.ReasonNetworkCleanupSucceeded, "network bridge cleanup succeeded on node %q", n.Name)
+		} else if n.Attempts < maxCleanupAttempts {
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("network bridge cleanup attempt %d failed on node %#q with %s", n.Attempts, n.Name, result))
+			r.eventRecorder.Eventf(&customObject, corev1.EventTypeWarning, event.ReasonNetworkCleanupRetried, "network bridge cleanup attempt %d failed on node %q with %s", n.Attempts, n.Name, result)
+
+			n.Attempts++
+			err := r.createJob(ctx, customObject, *n)
+			if err != nil {
+				return "", microerror.Mask(err)
+			}
+		} else {
+			r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("network bridge cleanup failed on node %#q after %d attempts with %s", n.Name, n.Attempts, result))
+			r.eventRecorder.Eventf(&customObject, corev1.EventTypeWarning, event.ReasonNetworkCleanupFailed, "network bridge cleanup failed on node %q after %d attempts with %s", n.Name, n.Attempts, result)
+			n.State = cleanupStateFailed
+		}
+	}
+
+	blocked := blockedReason(tracked)
+	if blocked != "" && blocked != customObject.GetAnnotations()[key.AnnotationDeletionBlocked] {
+		r.eventRecorder.Event(&customObject, corev1.EventTypeWarning, event.ReasonDeletionBlocked, blocked)
+	}
+
+	err = r.writeCleanupNodes(ctx, customObject, tracked, blocked)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	if !isCleanupDone(tracked) {
+		r.logger.Log(
+			"debug", "waiting for network bridge cleanup to complete",
+			"cluster", spec.Cluster.ID,
+			"pending", strings.Join(nodesInState(tracked, cleanupStatePending), ","),
+			"failed", strings.Join(nodesInState(tracked, cleanupStateFailed), ","),
+		)
+		return key.DeletionPhaseCleanupScheduled, nil
+	}
+
+	r.logger.Log("debug", fmt.Sprintf("network bridge cleanup finished on %d nodes", len(nodesInState(tracked, cleanupStateSucceeded))), "cluster", spec.Cluster.ID)
+
+	err = r.deleteDestroyerNamespace(ctx, customObject)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return key.DeletionPhaseCleanupDone, nil
+}
+
+func (r *Resource) createJob(ctx context.Context, customObject v1alpha1.FlannelConfig, n cleanupNode) error {
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("creating network bridge cleanup job for node %#q", n.Name))
+
+	job := newJob(customObject, n.Name, n.Attempts)
+
+	_, err := r.k8sClient.BatchV1().Jobs(destroyerNamespace(customObject.Spec)).Create(job)
+	if apierrors.IsAlreadyExists(err) {
+		// fall through
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("created network bridge cleanup job for node %#q", n.Name))
+
+	return nil
+}
+
+// deleteJobs deletes all cleanup jobs of the given node including their pods.
+func (r *Resource) deleteJobs(ctx context.Context, customObject v1alpha1.FlannelConfig, node string) error {
+	b := metav1.DeletePropagationBackground
+	o := &metav1.DeleteOptions{
+		PropagationPolicy: &b,
+	}
+
+	for attempt := 1; attempt <= maxCleanupAttempts; attempt++ {
+		err := r.k8sClient.BatchV1().Jobs(destroyerNamespace(customObject.Spec)).Delete(jobName(node, attempt), o)
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("deleted network bridge cleanup job %#q of node %#q", jobName(node, attempt), node))
+	}
+
+	return nil
+}
+
+// writeCleanupNodes records the given cleanup nodes and the reason why the
+// teardown is blocked. An empty reason removes the deletion blocked annotation.
+func (r *Resource) writeCleanupNodes(ctx context.Context, customObject v1alpha1.FlannelConfig, nodes []cleanupNode, blocked string) error {
+	v, err := cleanupNodesToAnnotation(nodes)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	annotations := map[string]string{
+		key.AnnotationCleanupNodes:    v,
+		key.AnnotationDeletionBlocked: blocked,
+	}
+
+	err = r.statusWriter.Write(ctx, customObject, annotations)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// finalize implements the cleanup-done phase. The phase is left as soon as the
+// destroyer namespace is gone.
+func (r *Resource) finalize(ctx context.Context, customObject v1alpha1.FlannelConfig) (string, error) {
+	n, err := r.k8sClient.CoreV1().Namespaces().Get(destroyerNamespace(customObject.Spec), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return key.DeletionPhaseFinalized, nil
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	if n.Status.Phase != corev1.NamespaceTerminating {
+		err = r.deleteDestroyerNamespace(ctx, customObject)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", "waiting for the destroyer namespace to be deleted")
+
+	return key.DeletionPhaseCleanupDone, nil
+}
+
+func (r *Resource) deleteDestroyerNamespace(ctx context.Context, customObject v1alpha1.FlannelConfig) error {
+	r.logger.Log("debug", "removing cleanup resources", "cluster", key.ClusterID(customObject))
+
+	err := r.k8sClient.CoreV1().Namespaces().Delete(destroyerNamespace(customObject.Spec), &metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		// fall through
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (r *Resource) namespaceExists(name string) (bool, error) {
+	_, err := r.k8sClient.CoreV1().Namespaces().Get(name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return true, nil
+}
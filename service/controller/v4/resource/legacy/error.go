@@ -0,0 +1,48 @@
+package legacy
+
+import "github.com/giantswarm/microerror"
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var notFoundError = &microerror.Error{
+	Kind: "notFoundError",
+}
+
+// IsNotFound asserts notFoundError.
+func IsNotFound(err error) bool {
+	return microerror.Cause(err) == notFoundError
+}
+
+var wrongTypeError = &microerror.Error{
+	Kind: "wrongTypeError",
+}
+
+// IsWrongTypeError asserts wrongTypeError.
+func IsWrongTypeError(err error) bool {
+	return microerror.Cause(err) == wrongTypeError
+}
+
+var unknownDeletionPhaseError = &microerror.Error{
+	Kind: "unknownDeletionPhaseError",
+}
+
+// IsUnknownDeletionPhase asserts unknownDeletionPhaseError.
+func IsUnknownDeletionPhase(err error) bool {
+	return microerror.Cause(err) == unknownDeletionPhaseError
+}
+
+var invalidAnnotationError = &microerror.Error{
+	Kind: "invalidAnnotationError",
+}
+
+// IsInvalidAnnotation asserts invalidAnnotationError.
+func IsInvalidAnnotation(err error) bool {
+	return microerror.Cause(err) == invalidAnnotationError
+}
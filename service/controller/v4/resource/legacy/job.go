@@ -0,0 +1,259 @@
+package legacy
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	apismetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/flannel-operator/pkg/ownership"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/key"
+)
+
+const (
+	// annotationNode holds the name of the node a bridge cleanup job runs on.
+	annotationNode = "flannel-operator.giantswarm.io/node"
+
+	// cleanupContainerName is the name of the container running
+	// docker-entrypoint.sh delete. Its termination state is reported as the
+	// result of a cleanup attempt.
+	cleanupContainerName = "k8s-network-bridge"
+
+	// jobNameLabel is the label the job controller puts on the pods of a job.
+	jobNameLabel = "job-name"
+
+	// jobActiveDeadlineSeconds limits the time a single cleanup attempt may
+	// take, e.g. in case the pod cannot start on a node which is not ready.
+	jobActiveDeadlineSeconds = int64(600)
+	// jobBackoffLimit is the number of pod retries within a single cleanup
+	// attempt.
+	jobBackoffLimit = int32(3)
+)
+
+// jobName returns the name of the bridge cleanup job of the given node and
+// attempt. Node names may exceed the length limit of job names, which is why
+// the node name is hashed.
+func jobName(node string, attempt int) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(node))
+
+	return fmt.Sprintf("%s-%08x-%d", destroyerApp, h.Sum32(), attempt)
+}
+
+// newJob returns the bridge cleanup job of the given node. The pod is bound to
+// the node directly, bypassing the scheduler, so that cordoned nodes are
+// cleaned up too. All taints are tolerated for the same reason. Every cleanup
+// attempt of a node gets its own job, see jobName.
+func newJob(customObject v1alpha1.FlannelConfig, node string, attempt int) *batchv1.Job {
+	privileged := true
+
+	app := destroyerApp
+
+	labels := map[string]string{
+		"cluster":  key.ClusterID(customObject),
+		"customer": key.ClusterCustomer(customObject),
+		"app":      app,
+	}
+
+	parallelism := int32(1)
+	completions := int32(1)
+	backoffLimit := jobBackoffLimit
+	activeDeadlineSeconds := jobActiveDeadlineSeconds
+
+	job := &batchv1.Job{
+		TypeMeta: apismetav1.TypeMeta{
+			Kind:       "Job",
+			APIVersion: "batch/v1",
+		},
+		ObjectMeta: apismetav1.ObjectMeta{
+			Name:   jobName(node, attempt),
+			Labels: labels,
+			Annotations: map[string]string{
+				annotationNode: node,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			Parallelism:           &parallelism,
+			Completions:           &completions,
+			BackoffLimit:          &backoffLimit,
+			ActiveDeadlineSeconds: &activeDeadlineSeconds,
+			Template: apiv1.PodTemplateSpec{
+				ObjectMeta: apismetav1.ObjectMeta{
+					GenerateName: app,
+					Labels:       labels,
+				},
+				Spec: apiv1.PodSpec{
+					NodeName:           node,
+					ServiceAccountName: serviceAccountName(customObject.Spec),
+					Tolerations: []apiv1.Toleration{
+						{
+							Operator: apiv1.TolerationOpExists,
+						},
+					},
+					// Failed containers are not restarted in place so that every
+					// failed pod keeps the exit code and termination message of
+					// its cleanup attempt.
+					RestartPolicy: apiv1.RestartPolicyNever,
+					HostNetwork:   true,
+					HostPID:       true,
+					Volumes: []apiv1.Volume{
+						{
+							Name: "cgroup",
+							VolumeSource: apiv1.VolumeSource{
+								HostPath: &apiv1.HostPathVolumeSource{
+									Path: "/sys/fs/cgroup",
+								},
+							},
+						},
+						{
+							Name: "dbus",
+							VolumeSource: apiv1.VolumeSource{
+								HostPath: &apiv1.HostPathVolumeSource{
+									Path: "/var/run/dbus",
+								},
+							},
+						},
+						{
+							Name: "environment",
+							VolumeSource: apiv1.VolumeSource{
+								HostPath: &apiv1.HostPathVolumeSource{
+									Path: "/etc/environment",
+								},
+							},
+						},
+						{
+							Name: "etcd-certs",
+							VolumeSource: apiv1.VolumeSource{
+								HostPath: &apiv1.HostPathVolumeSource{
+									Path: "/etc/kubernetes/ssl/etcd/",
+								},
+							},
+						},
+						{
+							Name: "etc-systemd",
+							VolumeSource: apiv1.VolumeSource{
+								HostPath: &apiv1.HostPathVolumeSource{
+									Path: "/etc/systemd/",
+								},
+							},
+						},
+						{
+							Name: "flannel",
+							VolumeSource: apiv1.VolumeSource{
+								HostPath: &apiv1.HostPathVolumeSource{
+									Path: flannelRunDir(customObject.Spec),
+								},
+							},
+						},
+						{
+							Name: "ssl",
+							VolumeSource: apiv1.VolumeSource{
+								HostPath: &apiv1.HostPathVolumeSource{
+									Path: "/etc/ssl/certs",
+								},
+							},
+						},
+						{
+							Name: "systemd",
+							VolumeSource: apiv1.VolumeSource{
+								HostPath: &apiv1.HostPathVolumeSource{
+									Path: "/run/systemd",
+								},
+							},
+						},
+						{
+							Name: "sys-class-net",
+							VolumeSource: apiv1.VolumeSource{
+								HostPath: &apiv1.HostPathVolumeSource{
+									Path: "/sys/class/net/",
+								},
+							},
+						},
+					},
+					Containers: []apiv1.Container{
+						{
+							Name:            cleanupContainerName,
+							Image:           networkBridgeDockerImage(customObject.Spec),
+							ImagePullPolicy: apiv1.PullAlways,
+							Command: []string{
+								"/bin/sh",
+								"-c",
+								"/docker-entrypoint.sh delete ${NETWORK_ENV_FILE_PATH} ${NETWORK_BRIDGE_NAME} ${NETWORK_INTERFACE_NAME} ${HOST_PRIVATE_NETWORK}",
+							},
+							Env: []apiv1.EnvVar{
+								{
+									Name:  "HOST_PRIVATE_NETWORK",
+									Value: hostPrivateNetwork(customObject.Spec),
+								},
+								{
+									Name:  "NETWORK_BRIDGE_NAME",
+									Value: networkBridgeName(customObject.Spec),
+								},
+								{
+									Name:  "NETWORK_DNS_BLOCK",
+									Value: networkDNSBlock(customObject.Spec),
+								},
+								{
+									Name:  "NETWORK_ENV_FILE_PATH",
+									Value: networkEnvFilePath(customObject.Spec),
+								},
+								{
+									Name:  "NETWORK_FLANNEL_DEVICE",
+									Value: networkFlannelDevice(customObject.Spec),
+								},
+								{
+									Name:  "NETWORK_INTERFACE_NAME",
+									Value: networkInterfaceName(customObject.Spec),
+								},
+								{
+									Name:  "NETWORK_NTP_BLOCK",
+									Value: networkNTPBlock(customObject.Spec),
+								},
+							},
+							SecurityContext: &apiv1.SecurityContext{
+								Privileged: &privileged,
+							},
+							TerminationMessagePolicy: apiv1.TerminationMessageFallbackToLogsOnError,
+							VolumeMounts: []apiv1.VolumeMount{
+								{
+									Name:      "cgroup",
+									MountPath: "/sys/fs/cgroup",
+								},
+								{
+									Name:      "dbus",
+									MountPath: "/var/run/dbus",
+								},
+								{
+									Name:      "environment",
+									MountPath: "/etc/environment",
+								},
+								{
+									Name:      "etc-systemd",
+									MountPath: "/etc/systemd/",
+								},
+								{
+									Name:      "flannel",
+									MountPath: "/run/flannel",
+								},
+								{
+									Name:      "systemd",
+									MountPath: "/run/systemd",
+								},
+								{
+									Name:      "sys-class-net",
+									MountPath: "/sys/class/net/",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	ownership.Set(job, customObject)
+
+	return job
+}
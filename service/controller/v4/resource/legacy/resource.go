@@ -0,0 +1,183 @@
+package legacy
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/operatorkit/resource/crud"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/giantswarm/flannel-operator/pkg/apply"
+	"github.com/giantswarm/flannel-operator/pkg/status"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/key"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/workloadgate"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "legacyv4"
+)
+
+// Config represents the configuration used to create a new config map resource.
+type Config struct {
+	Applier       apply.Interface
+	EventRecorder record.EventRecorder
+	K8sClient     kubernetes.Interface
+	Logger        micrologger.Logger
+	StatusWriter  status.Interface
+	WorkloadGate  workloadgate.Interface
+
+	EtcdCAFile  string
+	EtcdCrtFile string
+	EtcdKeyFile string
+}
+
+// DefaultConfig provides a default configuration to create a new config map
+// resource by best effort.
+func DefaultConfig() Config {
+	return Config{
+		Applier:       nil,
+		EventRecorder: nil,
+		K8sClient:     nil,
+		Logger:        nil,
+		StatusWriter:  nil,
+		WorkloadGate:  nil,
+
+		EtcdCAFile:  "",
+		EtcdCrtFile: "",
+		EtcdKeyFile: "",
+	}
+}
+
+// Resource implements the config map resource.
+type Resource struct {
+	applier       apply.Interface
+	eventRecorder record.EventRecorder
+	k8sClient     kubernetes.Interface
+	logger        micrologger.Logger
+	statusWriter  status.Interface
+	workloadGate  workloadgate.Interface
+
+	etcdCAFile  string
+	etcdCrtFile string
+	etcdKeyFile string
+}
+
+// New creates a new configured config map resource.
+func New(config Config) (*Resource, error) {
+	if config.Applier == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Applier must not be empty")
+	}
+	if config.EventRecorder == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.EventRecorder must not be empty")
+	}
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.K8sClient must not be empty")
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
+	}
+	if config.StatusWriter == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.StatusWriter must not be empty")
+	}
+	if config.WorkloadGate == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.WorkloadGate must not be empty")
+	}
+
+	newResource := &Resource{
+		applier:       config.Applier,
+		eventRecorder: config.EventRecorder,
+		k8sClient:     config.K8sClient,
+		logger: config.Logger.With(
+			"resource", Name,
+		),
+		statusWriter: config.StatusWriter,
+		workloadGate: config.WorkloadGate,
+
+		etcdCAFile:  config.EtcdCAFile,
+		etcdCrtFile: config.EtcdCrtFile,
+		etcdKeyFile: config.EtcdKeyFile,
+	}
+
+	return newResource, nil
+}
+
+func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (r *Resource) newCreateChange(ctx context.Context, obj, currentState, desiredState interface{}) (interface{}, error) {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	// Create a service account for the daemonset
+	{
+		serviceAccount := newServiceAccount(customObject, serviceAccountName(customObject.Spec), key.NetworkNamespace(customObject))
+		err := r.applier.Apply(ctx, serviceAccount)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	r.logger.Log("info", "started flanneld", "event", "add", "cluster", customObject.Spec.Cluster.ID)
+
+	return nil, nil
+}
+
+func (r *Resource) NewDeletePatch(ctx context.Context, obj, currentState, desiredState interface{}) (*crud.Patch, error) {
+	delete, err := r.newDeleteChange(ctx, obj, currentState, desiredState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	patch := crud.NewPatch()
+	patch.SetDeleteChange(delete)
+
+	return patch, nil
+}
+
+func (r *Resource) NewUpdatePatch(ctx context.Context, obj, currentState, desiredState interface{}) (*crud.Patch, error) {
+	create, err := r.newCreateChange(ctx, obj, currentState, desiredState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	update, err := r.newUpdateChange(ctx, obj, currentState, desiredState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	patch := crud.NewPatch()
+	patch.SetCreateChange(create)
+	patch.SetUpdateChange(update)
+
+	return patch, nil
+}
+
+func (r *Resource) newUpdateChange(ctx context.Context, obj, currentState, desiredState interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
+
+func (r *Resource) ApplyCreateChange(ctx context.Context, obj, createChange interface{}) error {
+	return nil
+}
+
+func (r *Resource) ApplyDeleteChange(ctx context.Context, obj, deleteChange interface{}) error {
+	return nil
+}
+
+func (r *Resource) ApplyUpdateChange(ctx context.Context, obj, updateChange interface{}) error {
+	return nil
+}
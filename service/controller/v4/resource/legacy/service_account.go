@@ -0,0 +1,31 @@
+package legacy
+
+import (
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	api "k8s.io/api/core/v1"
+	apismeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/flannel-operator/pkg/ownership"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/key"
+)
+
+func newServiceAccount(customObject v1alpha1.FlannelConfig, name, namespace string) *api.ServiceAccount {
+	serviceAccount := &api.ServiceAccount{
+		TypeMeta: apismeta.TypeMeta{
+			Kind:       "ServiceAccount",
+			APIVersion: "v1",
+		},
+		ObjectMeta: apismeta.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app":         networkApp,
+				"cluster-id":  key.ClusterID(customObject),
+				"customer-id": key.ClusterCustomer(customObject),
+			},
+		},
+	}
+	ownership.Set(serviceAccount, customObject)
+
+	return serviceAccount
+}
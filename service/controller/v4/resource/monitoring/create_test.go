@@ -0,0 +1,133 @@
+package monitoring
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type portAllocatorMock struct{}
+
+func (p portAllocatorMock) FlanneldHealthzPort(ctx context.Context, customObject v1alpha1.FlannelConfig) (int, error) {
+	return 21500, nil
+}
+
+func (p portAllocatorMock) HealthPort(ctx context.Context, customObject v1alpha1.FlannelConfig) (int, error) {
+	return 21026, nil
+}
+
+func Test_Resource_Monitoring_EnsureCreated(t *testing.T) {
+	customObject := v1alpha1.FlannelConfig{
+		Spec: v1alpha1.FlannelConfigSpec{
+			Cluster: v1alpha1.FlannelConfigSpecCluster{
+				Customer: "giantswarm",
+				ID:       "al9qy",
+			},
+		},
+	}
+
+	testCases := []struct {
+		Name                   string
+		ServiceMonitorServed   bool
+		ExistingService        *corev1.Service
+		ExpectedServiceMonitor bool
+	}{
+		{
+			Name:                   "case 0: service and service monitor are created",
+			ServiceMonitorServed:   true,
+			ExpectedServiceMonitor: true,
+		},
+		{
+			Name:                   "case 1: service monitor is skipped without Prometheus operator CRDs",
+			ServiceMonitorServed:   false,
+			ExpectedServiceMonitor: false,
+		},
+		{
+			Name:                 "case 2: outdated service is updated",
+			ServiceMonitorServed: true,
+			ExistingService: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "flannel-network",
+					Namespace: "flannel-network-al9qy",
+				},
+				Spec: corev1.ServiceSpec{
+					ClusterIP: corev1.ClusterIPNone,
+					Ports: []corev1.ServicePort{
+						{
+							Name: "metrics",
+							Port: 21000,
+						},
+					},
+				},
+			},
+			ExpectedServiceMonitor: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var objects []runtime.Object
+			if tc.ExistingService != nil {
+				objects = append(objects, tc.ExistingService)
+			}
+			k8sClient := fake.NewSimpleClientset(objects...)
+			if tc.ServiceMonitorServed {
+				k8sClient.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+					{
+						GroupVersion: "monitoring.coreos.com/v1",
+						APIResources: []metav1.APIResource{
+							{Name: "servicemonitors", Namespaced: true, Kind: "ServiceMonitor"},
+						},
+					},
+				}
+			}
+			dynClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+
+			c := Config{
+				DynClient:     dynClient,
+				K8sClient:     k8sClient,
+				Logger:        microloggertest.New(),
+				PortAllocator: portAllocatorMock{},
+			}
+			r, err := New(c)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			err = r.EnsureCreated(context.Background(), &customObject)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			service, err := k8sClient.CoreV1().Services("flannel-network-al9qy").Get("flannel-network", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			if service.Spec.ClusterIP != corev1.ClusterIPNone {
+				t.Fatalf("expected headless service, got cluster IP %#q", service.Spec.ClusterIP)
+			}
+			if len(service.Spec.Ports) != 2 || service.Spec.Ports[0].Port != 21500 || service.Spec.Ports[1].Port != 21026 {
+				t.Fatalf("expected ports 21500 and 21026, got %#v", service.Spec.Ports)
+			}
+			if service.Labels["cluster"] != "al9qy" || service.Labels["customer"] != "giantswarm" {
+				t.Fatalf("expected cluster and customer labels, got %#v", service.Labels)
+			}
+
+			_, err = dynClient.Resource(serviceMonitorResource).Namespace("flannel-network-al9qy").Get("flannel-network", metav1.GetOptions{})
+			if tc.ExpectedServiceMonitor && err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			if !tc.ExpectedServiceMonitor && err == nil {
+				t.Fatal("expected service monitor not to be created")
+			}
+		})
+	}
+}
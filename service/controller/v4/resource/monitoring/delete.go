@@ -0,0 +1,11 @@
+package monitoring
+
+import (
+	"context"
+)
+
+// EnsureDeleted does nothing. The service and the ServiceMonitor live in the
+// network namespace and are deleted together with it.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	return nil
+}
@@ -0,0 +1,64 @@
+package monitoring
+
+import (
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/giantswarm/flannel-operator/pkg/ownership"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/key"
+)
+
+func labels(customObject v1alpha1.FlannelConfig) map[string]string {
+	return map[string]string{
+		"app":      key.NetworkID,
+		"cluster":  key.ClusterID(customObject),
+		"customer": key.ClusterCustomer(customObject),
+	}
+}
+
+func selector(customObject v1alpha1.FlannelConfig) map[string]string {
+	return map[string]string{
+		"app":     key.NetworkID,
+		"cluster": key.ClusterID(customObject),
+	}
+}
+
+// newService returns the headless service selecting the network pods. The
+// network pods run in the host network, which is why the endpoints of the
+// service are the node IPs and the allocated host ports.
+func newService(customObject v1alpha1.FlannelConfig, healthPort, flanneldHealthzPort int) *corev1.Service {
+	service := &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.NetworkID,
+			Namespace: key.NetworkNamespace(customObject),
+			Labels:    labels(customObject),
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       key.PortNameHealthz,
+					Port:       int32(flanneldHealthzPort),
+					Protocol:   corev1.ProtocolTCP,
+					TargetPort: intstr.FromInt(flanneldHealthzPort),
+				},
+				{
+					Name:       key.PortNameMetrics,
+					Port:       int32(healthPort),
+					Protocol:   corev1.ProtocolTCP,
+					TargetPort: intstr.FromInt(healthPort),
+				},
+			},
+			Selector: selector(customObject),
+		},
+	}
+	ownership.Set(service, customObject)
+
+	return service
+}
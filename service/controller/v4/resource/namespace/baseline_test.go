@@ -0,0 +1,182 @@
+package namespace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	apiv1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	apismetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/flannel-operator/pkg/apply/applytest"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/workloadgate/workloadgatetest"
+)
+
+func Test_Resource_Namespace_ensureBaseline(t *testing.T) {
+	customObject := v1alpha1.FlannelConfig{
+		Spec: v1alpha1.FlannelConfigSpec{
+			Cluster: v1alpha1.FlannelConfigSpecCluster{
+				ID: "al9qy",
+			},
+		},
+	}
+
+	testCases := []struct {
+		Name          string
+		Baseline      Baseline
+		Existing      []runtime.Object
+		ExpectedKinds []string
+		ExpectedGone  bool
+		ErrorMatcher  func(error) bool
+	}{
+		{
+			Name:          "case 0: nothing is applied by default",
+			Baseline:      Baseline{},
+			ExpectedKinds: nil,
+		},
+		{
+			Name: "case 1: all baseline objects are applied",
+			Baseline: Baseline{
+				NetworkPolicy: true,
+				ResourceQuota: ResourceQuota{Pods: "100"},
+				LimitRange:    LimitRange{DefaultRequestMemory: "64Mi"},
+			},
+			ExpectedKinds: []string{"NetworkPolicy", "ResourceQuota", "LimitRange"},
+		},
+		{
+			Name:     "case 2: disabled baseline objects are deleted",
+			Baseline: Baseline{},
+			Existing: []runtime.Object{
+				&networkingv1.NetworkPolicy{
+					ObjectMeta: apismetav1.ObjectMeta{
+						Name:      "flannel-network-baseline",
+						Namespace: "flannel-network-al9qy",
+					},
+				},
+				&apiv1.ResourceQuota{
+					ObjectMeta: apismetav1.ObjectMeta{
+						Name:      "flannel-network-baseline",
+						Namespace: "flannel-network-al9qy",
+					},
+				},
+			},
+			ExpectedGone: true,
+		},
+		{
+			Name: "case 3: invalid quantities are rejected",
+			Baseline: Baseline{
+				ResourceQuota: ResourceQuota{CPU: "lots"},
+			},
+			ErrorMatcher: IsInvalidConfig,
+		},
+		{
+			Name: "case 4: cpu quotas without default cpu limit are rejected",
+			Baseline: Baseline{
+				ResourceQuota: ResourceQuota{CPU: "2"},
+				LimitRange:    LimitRange{DefaultRequestCPU: "100m"},
+			},
+			ErrorMatcher: IsInvalidConfig,
+		},
+		{
+			Name: "case 5: memory quotas without default memory limit are rejected",
+			Baseline: Baseline{
+				ResourceQuota: ResourceQuota{Memory: "1Gi"},
+			},
+			ErrorMatcher: IsInvalidConfig,
+		},
+		{
+			Name: "case 6: cpu and memory quotas with default limits are applied",
+			Baseline: Baseline{
+				ResourceQuota: ResourceQuota{CPU: "2", Memory: "1Gi"},
+				LimitRange:    LimitRange{DefaultCPU: "100m", DefaultMemory: "128Mi"},
+			},
+			ExpectedKinds: []string{"ResourceQuota", "LimitRange"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			applier := applytest.New()
+			k8sClient := fake.NewSimpleClientset(tc.Existing...)
+
+			c := Config{
+				Applier:      applier,
+				K8sClient:    k8sClient,
+				Logger:       microloggertest.New(),
+				WorkloadGate: workloadgatetest.New(false),
+
+				Baseline: tc.Baseline,
+			}
+
+			r, err := New(c)
+
+			switch {
+			case err == nil && tc.ErrorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.ErrorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.ErrorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.ErrorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if tc.ErrorMatcher != nil {
+				return
+			}
+
+			err = r.ensureBaseline(context.TODO(), customObject)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			var kinds []string
+			for _, o := range applier.Applied() {
+				kinds = append(kinds, o.GetObjectKind().GroupVersionKind().Kind)
+			}
+			if len(kinds) != len(tc.ExpectedKinds) {
+				t.Fatalf("expected %#v got %#v", tc.ExpectedKinds, kinds)
+			}
+			for i := range kinds {
+				if kinds[i] != tc.ExpectedKinds[i] {
+					t.Fatalf("expected %#v got %#v", tc.ExpectedKinds, kinds)
+				}
+			}
+
+			if tc.ExpectedGone {
+				list, err := k8sClient.NetworkingV1().NetworkPolicies("flannel-network-al9qy").List(apismetav1.ListOptions{})
+				if err != nil {
+					t.Fatal("expected", nil, "got", err)
+				}
+				if len(list.Items) != 0 {
+					t.Fatalf("expected network policy to be deleted")
+				}
+				quotas, err := k8sClient.CoreV1().ResourceQuotas("flannel-network-al9qy").List(apismetav1.ListOptions{})
+				if err != nil {
+					t.Fatal("expected", nil, "got", err)
+				}
+				if len(quotas.Items) != 0 {
+					t.Fatalf("expected resource quota to be deleted")
+				}
+			}
+		})
+	}
+}
+
+func Test_Resource_Namespace_newResourceQuota(t *testing.T) {
+	q := newResourceQuota(v1alpha1.FlannelConfig{}, ResourceQuota{CPU: "2", Pods: "100"})
+	if q == nil {
+		t.Fatal("expected resource quota")
+	}
+	if len(q.Spec.Hard) != 2 {
+		t.Fatalf("expected 2 hard limits got %#v", q.Spec.Hard)
+	}
+	if !q.Spec.Hard[apiv1.ResourceLimitsCPU].Equal(resource.MustParse("2")) {
+		t.Fatalf("expected cpu limit 2 got %#v", q.Spec.Hard)
+	}
+}
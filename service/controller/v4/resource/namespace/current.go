@@ -0,0 +1,96 @@
+package namespace
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller/context/finalizerskeptcontext"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+	"github.com/giantswarm/operatorkit/controller/context/resourcecanceledcontext"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/flannel-operator/pkg/ownership"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/key"
+)
+
+func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interface{}, error) {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", "looking for the namespace in the Kubernetes API")
+
+	var namespace *corev1.Namespace
+	{
+		manifest, err := r.k8sClient.CoreV1().Namespaces().Get(key.NetworkNamespace(customObject), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "did not find the namespace in the Kubernetes API")
+			// fall through
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		} else {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "found the namespace in the Kubernetes API")
+			namespace = manifest
+		}
+	}
+
+	// The namespace might be owned by another FlannelConfig or might not be
+	// managed by the operator at all. We must not touch it then.
+	if namespace != nil && ownership.IsForeign(namespace, customObject) {
+		r.logger.LogCtx(ctx, "level", "warning", "message", "namespace is not owned by this FlannelConfig")
+
+		resourcecanceledcontext.SetCanceled(ctx)
+		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+
+		return nil, nil
+	}
+
+	// In case the namespace is already terminating we do not need to do any
+	// further work. Then we cancel the reconciliation to prevent the current and
+	// any further resource from being processed. On deletion only this resource
+	// is canceled. The legacy resource after it has to run, since its deletion
+	// phases decide when the finalizers are released. Canceling the
+	// reconciliation here would release them before the network cleanup ran.
+	if namespace != nil && namespace.Status.Phase == corev1.NamespaceTerminating {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "namespace is in state 'Terminating'")
+
+		if key.IsDeleted(customObject) {
+			resourcecanceledcontext.SetCanceled(ctx)
+			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+
+			return nil, nil
+		}
+
+		reconciliationcanceledcontext.SetCanceled(ctx)
+		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling reconciliation")
+
+		return nil, nil
+	}
+
+	// In case a cluster deletion happens, we want to delete the guest cluster
+	// namespace. We still need to use the namespace for resource creation in
+	// order to drain nodes on KVM though. So as long as the workload gate holds
+	// the deletion we delay the deletion of the namespace here in order to still
+	// be able to create resources in it. As soon as the draining was done and the
+	// workloads got removed, the gate opens after the delete event got replayed.
+	// Then we just remove the namespace as usual.
+	if key.IsDeleted(customObject) {
+		wait, err := r.workloadGate.Wait(ctx, customObject)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		if wait {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "cannot finish deletion of namespace due to existing workloads")
+			resourcecanceledcontext.SetCanceled(ctx)
+			finalizerskeptcontext.SetKept(ctx)
+			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+
+			return nil, nil
+		}
+	}
+
+	return namespace, nil
+}
@@ -0,0 +1,91 @@
+package namespace
+
+import (
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/giantswarm/flannel-operator/pkg/apply"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/workloadgate"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "namespacev4"
+)
+
+// Config represents the configuration used to create a new cloud config resource.
+type Config struct {
+	// Dependencies.
+	Applier      apply.Interface
+	K8sClient    kubernetes.Interface
+	Logger       micrologger.Logger
+	WorkloadGate workloadgate.Interface
+
+	// Baseline configures the network policy, resource quota and limit range
+	// managed alongside the namespace.
+	Baseline Baseline
+}
+
+// Resource implements the cloud config resource.
+type Resource struct {
+	// Dependencies.
+	applier      apply.Interface
+	k8sClient    kubernetes.Interface
+	logger       micrologger.Logger
+	workloadGate workloadgate.Interface
+
+	baseline Baseline
+}
+
+// New creates a new configured cloud config resource.
+func New(config Config) (*Resource, error) {
+	// Dependencies.
+	if config.Applier == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Applier must not be empty", config)
+	}
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.WorkloadGate == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.WorkloadGate must not be empty", config)
+	}
+
+	err := config.Baseline.Validate()
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Baseline must be valid: %s", config, err)
+	}
+
+	newService := &Resource{
+		// Dependencies.
+		applier:      config.Applier,
+		k8sClient:    config.K8sClient,
+		logger:       config.Logger,
+		workloadGate: config.WorkloadGate,
+
+		baseline: config.Baseline,
+	}
+
+	return newService, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
+
+func toNamespace(v interface{}) (*apiv1.Namespace, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	namespace, ok := v.(*apiv1.Namespace)
+	if !ok {
+		return nil, microerror.Maskf(wrongTypeError, "expected '%T', got '%T'", &apiv1.Namespace{}, v)
+	}
+
+	return namespace, nil
+}
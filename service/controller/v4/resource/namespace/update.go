@@ -0,0 +1,82 @@
+package namespace
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/resource/crud"
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/giantswarm/flannel-operator/service/controller/v4/key"
+)
+
+func (r *Resource) ApplyUpdateChange(ctx context.Context, obj, updateChange interface{}) error {
+	namespaceToUpdate, err := toNamespace(updateChange)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if namespaceToUpdate != nil {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "updating the namespace in the Kubernetes API")
+
+		err = r.applier.Apply(ctx, namespaceToUpdate)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "updated the namespace in the Kubernetes API")
+
+		customObject, err := key.ToCustomObject(obj)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		err = r.ensureBaseline(ctx, customObject)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	} else {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "the namespace does not need to be updated in the Kubernetes API")
+	}
+
+	return nil
+}
+
+func (r *Resource) NewUpdatePatch(ctx context.Context, obj, currentState, desiredState interface{}) (*crud.Patch, error) {
+	create, err := r.newCreateChange(ctx, obj, currentState, desiredState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	update, err := r.newUpdateChange(ctx, obj, currentState, desiredState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	patch := crud.NewPatch()
+	patch.SetCreateChange(create)
+	patch.SetUpdateChange(update)
+
+	return patch, nil
+}
+
+// newUpdateChange returns the desired namespace in case the namespace exists.
+// It is server side applied, which makes the API server correct drift of the
+// fields we manage and leaves fields of other managers alone.
+func (r *Resource) newUpdateChange(ctx context.Context, obj, currentState, desiredState interface{}) (interface{}, error) {
+	currentNamespace, err := toNamespace(currentState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	desiredNamespace, err := toNamespace(desiredState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var namespaceToUpdate *apiv1.Namespace
+	if currentNamespace != nil {
+		namespaceToUpdate = desiredNamespace
+	}
+
+	return namespaceToUpdate, nil
+}
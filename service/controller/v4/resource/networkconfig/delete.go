@@ -0,0 +1,117 @@
+package networkconfig
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller/context/finalizerskeptcontext"
+	"github.com/giantswarm/operatorkit/resource/crud"
+
+	"github.com/giantswarm/flannel-operator/service/controller/v4/etcd"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/key"
+)
+
+// ApplyDeleteChange removes the network path including the subnet leases of the
+// tenant cluster network from etcd. The finalizers are kept until it is
+// verified that nothing is left under the network path.
+func (r *Resource) ApplyDeleteChange(ctx context.Context, obj, deleteChange interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	networkConfigToDelete, err := toNetworkConfig(deleteChange)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	var emptyNetworkConfig NetworkConfig
+	if networkConfigToDelete == emptyNetworkConfig {
+		return nil
+	}
+
+	store, err := r.storeFor(ctx, customObject)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if store == nil {
+		r.storeCache.Delete(key.ClusterID(customObject))
+		return nil
+	}
+
+	p := key.EtcdNetworkPath(customObject)
+
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("deleting network path %#q in etcd", p))
+
+		err = store.Delete(ctx, p)
+		if etcd.IsNotFound(err) {
+			// fall through
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	{
+		exists, err := store.Exists(ctx, p)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		if exists {
+			r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("network path %#q still exists in etcd", p))
+			finalizerskeptcontext.SetKept(ctx)
+			r.logger.LogCtx(ctx, "level", "debug", "message", "keeping finalizers")
+			return nil
+		}
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("deleted network path %#q in etcd", p))
+
+	// The network state is gone, which means the Store of a tenant cluster
+	// overriding its etcd cluster is not needed anymore.
+	r.storeCache.Delete(key.ClusterID(customObject))
+
+	return nil
+}
+
+// NewDeletePatch only deletes the network state once the legacy resource
+// finished the bridge cleanup. flanneld as well as the bridge cleanup on the
+// nodes use the network state until then. The legacy resource holds the
+// finalizers as well, which is why keeping them here just makes the order
+// explicit.
+func (r *Resource) NewDeletePatch(ctx context.Context, obj, currentState, desiredState interface{}) (*crud.Patch, error) {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	if !key.DeletionPhaseReached(customObject, key.DeletionPhaseCleanupDone) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("not deleting network state in etcd before deletion phase %#q", key.DeletionPhaseCleanupDone))
+		finalizerskeptcontext.SetKept(ctx)
+		r.logger.LogCtx(ctx, "level", "debug", "message", "keeping finalizers")
+		return nil, nil
+	}
+
+	delete, err := r.newDeleteChange(ctx, obj, currentState, desiredState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	patch := crud.NewPatch()
+	patch.SetDeleteChange(delete)
+
+	return patch, nil
+}
+
+// newDeleteChange returns the desired network config no matter whether the
+// current network config exists. Subnet leases may be left under the network
+// path even when the network config is gone.
+func (r *Resource) newDeleteChange(ctx context.Context, obj, currentState, desiredState interface{}) (interface{}, error) {
+	desiredNetworkConfig, err := toNetworkConfig(desiredState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return desiredNetworkConfig, nil
+}
@@ -0,0 +1,140 @@
+package networkconfig
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/giantswarm/flannel-operator/service/controller/v4/etcd"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/key"
+)
+
+const (
+	// BackendType is the backend type the flannel network is configured with.
+	BackendType = "vxlan"
+	// Name is the identifier of the resource.
+	Name = "networkconfigv4"
+)
+
+// Config represents the configuration used to create a new network config
+// resource.
+type Config struct {
+	K8sClient  kubernetes.Interface
+	Logger     micrologger.Logger
+	Store      etcd.Store
+	StoreCache *etcd.StoreCache
+
+	// EtcdClientConfig is the operator wide etcd client configuration Store
+	// was created with. Tenant clusters overriding their etcd endpoints or
+	// certificates get a Store from StoreCache based on it, see
+	// key.AnnotationEtcdEndpoints and key.AnnotationEtcdSecret.
+	EtcdClientConfig etcd.ClientConfig
+}
+
+// Resource implements the network config resource.
+type Resource struct {
+	k8sClient  kubernetes.Interface
+	logger     micrologger.Logger
+	store      etcd.Store
+	storeCache *etcd.StoreCache
+
+	etcdClientConfig etcd.ClientConfig
+}
+
+// New creates a new configured network config resource.
+func New(config Config) (*Resource, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.Store == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Store must not be empty", config)
+	}
+	if config.StoreCache == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.StoreCache must not be empty", config)
+	}
+
+	r := &Resource{
+		k8sClient:  config.K8sClient,
+		logger:     config.Logger,
+		store:      config.Store,
+		storeCache: config.StoreCache,
+
+		etcdClientConfig: config.EtcdClientConfig,
+	}
+
+	return r, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
+
+// storeFor returns the Store of the etcd cluster the network state of the
+// given tenant cluster lives in. A missing etcd secret of a deleted tenant
+// cluster cannot show up anymore, e.g. because it got deleted together with
+// the tenant cluster. No Store is returned then, so that the deletion can
+// progress. The network state is left behind in the etcd cluster in that case.
+func (r *Resource) storeFor(ctx context.Context, customObject v1alpha1.FlannelConfig) (etcd.Store, error) {
+	endpoints, err := key.EtcdEndpoints(customObject)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	secretName := key.EtcdSecretName(customObject)
+
+	if len(endpoints) == 0 && secretName == "" {
+		return r.store, nil
+	}
+
+	c := r.etcdClientConfig
+	if len(endpoints) != 0 {
+		c.Endpoints = endpoints
+	}
+	if secretName != "" {
+		secret, err := r.k8sClient.CoreV1().Secrets(customObject.GetNamespace()).Get(secretName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) && key.IsDeleted(customObject) {
+			r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("etcd secret %#q does not exist, skipping network state in etcd", customObject.GetNamespace()+"/"+secretName))
+			return nil, nil
+		} else if apierrors.IsNotFound(err) {
+			return nil, microerror.Maskf(notFoundError, "etcd secret %#q", customObject.GetNamespace()+"/"+secretName)
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		data, err := key.EtcdSecretData(secret)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		c.CAFile = ""
+		c.CrtFile = ""
+		c.KeyFile = ""
+		c.CAData = data[key.EtcdCAFileName]
+		c.CrtData = data[key.EtcdCrtFileName]
+		c.KeyData = data[key.EtcdKeyFileName]
+	}
+
+	store, err := r.storeCache.Store(key.ClusterID(customObject), c)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return store, nil
+}
+
+func toNetworkConfig(v interface{}) (NetworkConfig, error) {
+	networkConfig, ok := v.(NetworkConfig)
+	if !ok {
+		return NetworkConfig{}, microerror.Maskf(wrongTypeError, "expected '%T', got '%T'", &NetworkConfig{}, v)
+	}
+
+	return networkConfig, nil
+}
@@ -0,0 +1,202 @@
+package networkconfig
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/flannel-operator/service/controller/v4/etcd"
+	etcdfake "github.com/giantswarm/flannel-operator/service/controller/v4/etcd/fake"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/key"
+)
+
+type recordingStore struct {
+	*etcdfake.Fake
+
+	config etcd.ClientConfig
+}
+
+func newRecordingStore(config etcd.ClientConfig) (etcd.Store, error) {
+	return &recordingStore{Fake: etcdfake.New(), config: config}, nil
+}
+
+func Test_Resource_storeFor(t *testing.T) {
+	defaultConfig := etcd.ClientConfig{
+		Endpoints: []string{"https://127.0.0.1:2379"},
+		CAFile:    "/etc/kubernetes/ssl/etcd/etcd-ca.pem",
+		CrtFile:   "/etc/kubernetes/ssl/etcd/etcd.pem",
+		KeyFile:   "/etc/kubernetes/ssl/etcd/etcd-key.pem",
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "al9qy-etcd",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			key.EtcdCAFileName:  []byte("ca"),
+			key.EtcdCrtFileName: []byte("crt"),
+			key.EtcdKeyFileName: []byte("key"),
+		},
+	}
+	incompleteSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "al9qy-incomplete",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			key.EtcdCrtFileName: []byte("crt"),
+		},
+	}
+
+	testCases := []struct {
+		Name                 string
+		Annotations          map[string]string
+		Deleted              bool
+		Secrets              []runtime.Object
+		ExpectedDefaultStore bool
+		ExpectedNoStore      bool
+		ExpectedConfig       etcd.ClientConfig
+		ErrorMatcher         func(err error) bool
+	}{
+		{
+			Name:                 "case 0: tenant clusters use the operator wide store by default",
+			Annotations:          nil,
+			ExpectedDefaultStore: true,
+			ErrorMatcher:         nil,
+		},
+		{
+			Name: "case 1: endpoints are overridden",
+			Annotations: map[string]string{
+				key.AnnotationEtcdEndpoints: "https://etcd-1.example.com:2379, https://etcd-2.example.com:2379",
+			},
+			ExpectedConfig: etcd.ClientConfig{
+				Endpoints: []string{"https://etcd-1.example.com:2379", "https://etcd-2.example.com:2379"},
+				CAFile:    defaultConfig.CAFile,
+				CrtFile:   defaultConfig.CrtFile,
+				KeyFile:   defaultConfig.KeyFile,
+			},
+			ErrorMatcher: nil,
+		},
+		{
+			Name: "case 2: endpoints and certificates are overridden",
+			Annotations: map[string]string{
+				key.AnnotationEtcdEndpoints: "https://etcd-1.example.com:2379",
+				key.AnnotationEtcdSecret:    "al9qy-etcd",
+			},
+			Secrets: []runtime.Object{secret},
+			ExpectedConfig: etcd.ClientConfig{
+				Endpoints: []string{"https://etcd-1.example.com:2379"},
+				CAData:    []byte("ca"),
+				CrtData:   []byte("crt"),
+				KeyData:   []byte("key"),
+			},
+			ErrorMatcher: nil,
+		},
+		{
+			Name: "case 3: invalid endpoints are rejected",
+			Annotations: map[string]string{
+				key.AnnotationEtcdEndpoints: "etcd-1.example.com:2379",
+			},
+			ErrorMatcher: key.IsInvalidConfig,
+		},
+		{
+			Name: "case 4: missing secrets are reported",
+			Annotations: map[string]string{
+				key.AnnotationEtcdSecret: "al9qy-etcd",
+			},
+			ErrorMatcher: IsNotFound,
+		},
+		{
+			Name: "case 5: incomplete secrets are rejected",
+			Annotations: map[string]string{
+				key.AnnotationEtcdSecret: "al9qy-incomplete",
+			},
+			Secrets:      []runtime.Object{incompleteSecret},
+			ErrorMatcher: key.IsInvalidConfig,
+		},
+		{
+			Name: "case 6: missing secrets are skipped on deletion",
+			Annotations: map[string]string{
+				key.AnnotationEtcdSecret: "al9qy-etcd",
+			},
+			Deleted:         true,
+			ExpectedNoStore: true,
+			ErrorMatcher:    nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			defaultStore := etcdfake.New()
+
+			c := Config{
+				K8sClient:  k8sfake.NewSimpleClientset(tc.Secrets...),
+				Logger:     microloggertest.New(),
+				Store:      defaultStore,
+				StoreCache: etcd.NewStoreCache(newRecordingStore),
+
+				EtcdClientConfig: defaultConfig,
+			}
+
+			r, err := New(c)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			customObject := v1alpha1.FlannelConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "al9qy",
+					Namespace:   "default",
+					Annotations: tc.Annotations,
+				},
+			}
+			customObject.Spec.Cluster.ID = "al9qy"
+			if tc.Deleted {
+				customObject.SetDeletionTimestamp(&metav1.Time{})
+			}
+
+			store, err := r.storeFor(context.Background(), customObject)
+
+			switch {
+			case err == nil && tc.ErrorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.ErrorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.ErrorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.ErrorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if tc.ErrorMatcher != nil {
+				return
+			}
+
+			if tc.ExpectedNoStore {
+				if store != nil {
+					t.Fatalf("expected no store got %#v", store)
+				}
+				return
+			}
+			if tc.ExpectedDefaultStore {
+				if store != etcd.Store(defaultStore) {
+					t.Fatalf("expected the operator wide store got %#v", store)
+				}
+				return
+			}
+
+			config := store.(*recordingStore).config
+			if !reflect.DeepEqual(config, tc.ExpectedConfig) {
+				t.Fatalf("expected client config %#v got %#v", tc.ExpectedConfig, config)
+			}
+		})
+	}
+}
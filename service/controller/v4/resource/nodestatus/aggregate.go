@@ -0,0 +1,117 @@
+package nodestatus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// nodeConditionTaintPrefix prefixes the taints Kubernetes puts on nodes
+	// based on their conditions, e.g. node.kubernetes.io/not-ready.
+	nodeConditionTaintPrefix = "node.kubernetes.io/"
+)
+
+type nodeStatus struct {
+	Node     string
+	Ready    bool
+	Restarts int32
+}
+
+// aggregate returns the status of the network pods per node, sorted by node
+// name. A node is ready when all containers of all network pods scheduled to
+// it are ready. Nodes the network pods are expected on but which do not run
+// any are not ready, see expectsNetworkPod. Pods which are not scheduled yet
+// are ignored.
+func aggregate(nodes []corev1.Node, pods []corev1.Pod) []nodeStatus {
+	byNode := map[string]*nodeStatus{}
+	for _, n := range nodes {
+		if !expectsNetworkPod(n) {
+			continue
+		}
+
+		byNode[n.GetName()] = &nodeStatus{
+			Node:  n.GetName(),
+			Ready: false,
+		}
+	}
+
+	scheduled := map[string]bool{}
+	for _, p := range pods {
+		if p.Spec.NodeName == "" {
+			continue
+		}
+
+		n, ok := byNode[p.Spec.NodeName]
+		if !ok {
+			n = &nodeStatus{
+				Node: p.Spec.NodeName,
+			}
+			byNode[p.Spec.NodeName] = n
+		}
+		if !scheduled[p.Spec.NodeName] {
+			n.Ready = true
+			scheduled[p.Spec.NodeName] = true
+		}
+
+		if len(p.Status.ContainerStatuses) == 0 || len(p.Status.ContainerStatuses) < len(p.Spec.Containers) {
+			n.Ready = false
+		}
+		for _, c := range p.Status.ContainerStatuses {
+			if !c.Ready {
+				n.Ready = false
+			}
+			n.Restarts += c.RestartCount
+		}
+	}
+
+	var statuses []nodeStatus
+	for _, n := range byNode {
+		statuses = append(statuses, *n)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Node < statuses[j].Node
+	})
+
+	return statuses
+}
+
+// expectsNetworkPod returns whether the network daemon set schedules a pod to
+// the given node. Its pods do not tolerate any taints except the node
+// condition taints the daemon set controller tolerates on its own, e.g. of
+// cordoned or not ready nodes. Nodes tainted otherwise, e.g. masters, do not
+// run network pods.
+func expectsNetworkPod(node corev1.Node) bool {
+	for _, t := range node.Spec.Taints {
+		if t.Effect != corev1.TaintEffectNoSchedule && t.Effect != corev1.TaintEffectNoExecute {
+			continue
+		}
+		if strings.HasPrefix(t.Key, nodeConditionTaintPrefix) {
+			continue
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// summary renders the given node status in a human readable way, e.g.
+// "3/5 nodes ready; failing: node-a, node-c".
+func summary(nodes []nodeStatus) string {
+	var failing []string
+	for _, n := range nodes {
+		if !n.Ready {
+			failing = append(failing, n.Node)
+		}
+	}
+
+	s := fmt.Sprintf("%d/%d nodes ready", len(nodes)-len(failing), len(nodes))
+	if len(failing) != 0 {
+		s += "; failing: " + strings.Join(failing, ", ")
+	}
+
+	return s
+}
@@ -0,0 +1,97 @@
+package nodestatus
+
+import (
+	"sync"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/giantswarm/flannel-operator/pkg/status"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "nodestatusv4"
+)
+
+// Config represents the configuration used to create a new node status
+// resource.
+type Config struct {
+	K8sClient    kubernetes.Interface
+	Logger       micrologger.Logger
+	StatusWriter status.Interface
+}
+
+// Resource implements the node status resource. It aggregates the readiness
+// and restarts of the network pods of a tenant cluster per node, exposes them
+// as metrics and records a summary on the FlannelConfig.
+type Resource struct {
+	k8sClient    kubernetes.Interface
+	logger       micrologger.Logger
+	statusWriter status.Interface
+
+	// nodes tracks the nodes metrics were emitted for per cluster ID, so that
+	// series of nodes which went away can be removed.
+	nodes      map[string]map[string]bool
+	nodesMutex sync.Mutex
+}
+
+// New creates a new configured node status resource.
+func New(config Config) (*Resource, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.StatusWriter == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.StatusWriter must not be empty", config)
+	}
+
+	r := &Resource{
+		k8sClient:    config.K8sClient,
+		logger:       config.Logger,
+		statusWriter: config.StatusWriter,
+
+		nodes: map[string]map[string]bool{},
+	}
+
+	return r, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
+
+// updateMetrics sets the gauges of the given cluster and removes the series of
+// nodes which are not part of the given node list anymore.
+func (r *Resource) updateMetrics(clusterID string, nodes []nodeStatus) {
+	r.nodesMutex.Lock()
+	defer r.nodesMutex.Unlock()
+
+	current := map[string]bool{}
+	for _, n := range nodes {
+		current[n.Node] = true
+
+		var ready float64
+		if n.Ready {
+			ready = 1
+		}
+		nodeReadyGauge.WithLabelValues(clusterID, n.Node).Set(ready)
+		nodeRestartsGauge.WithLabelValues(clusterID, n.Node).Set(float64(n.Restarts))
+	}
+
+	for node := range r.nodes[clusterID] {
+		if !current[node] {
+			nodeReadyGauge.DeleteLabelValues(clusterID, node)
+			nodeRestartsGauge.DeleteLabelValues(clusterID, node)
+		}
+	}
+
+	if len(current) == 0 {
+		delete(r.nodes, clusterID)
+	} else {
+		r.nodes[clusterID] = current
+	}
+}
@@ -0,0 +1,22 @@
+package paused
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/flannel-operator/pkg/metric"
+)
+
+const (
+	PrometheusNamespace = "flannel_operator"
+	PrometheusSubsystem = "paused_resource"
+)
+
+var pausedGauge = metric.MustRegisterGaugeVec(prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: PrometheusNamespace,
+		Subsystem: PrometheusSubsystem,
+		Name:      "paused",
+		Help:      "Whether the reconciliation of the FlannelConfig of a tenant cluster is paused.",
+	},
+	[]string{"cluster"},
+))
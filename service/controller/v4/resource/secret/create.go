@@ -0,0 +1,55 @@
+package secret
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func (r *Resource) ApplyCreateChange(ctx context.Context, obj, createChange interface{}) error {
+	secretToCreate, err := toSecret(createChange)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if secretToCreate != nil {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "creating the secret in the Kubernetes API")
+
+		_, err = r.k8sClient.CoreV1().Secrets(secretToCreate.GetNamespace()).Create(secretToCreate)
+		if apierrors.IsAlreadyExists(err) {
+			// fall through
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "created the secret in the Kubernetes API")
+	} else {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "the secret does not need to be created in the Kubernetes API")
+	}
+
+	return nil
+}
+
+func (r *Resource) newCreateChange(ctx context.Context, obj, currentState, desiredState interface{}) (interface{}, error) {
+	currentSecret, err := toSecret(currentState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	desiredSecret, err := toSecret(desiredState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", "finding out if the secret has to be created")
+
+	var secretToCreate *corev1.Secret
+	if currentSecret == nil {
+		secretToCreate = desiredSecret
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", "found out if the secret has to be created")
+
+	return secretToCreate, nil
+}
@@ -0,0 +1,39 @@
+package secret
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/flannel-operator/service/controller/v4/key"
+)
+
+func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interface{}, error) {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var currentSecret *corev1.Secret
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "looking for the secret in the Kubernetes API")
+
+		namespace := key.NetworkNamespace(customObject)
+		manifest, err := r.k8sClient.CoreV1().Secrets(namespace).Get(key.EtcdCertsSecretName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			// fall through
+			r.logger.LogCtx(ctx, "level", "debug", "message", "did not find the secret in the Kubernetes API")
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		} else {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "found the secret in the Kubernetes API")
+
+			currentSecret = manifest
+		}
+	}
+
+	return currentSecret, nil
+}
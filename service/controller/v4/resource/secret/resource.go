@@ -0,0 +1,85 @@
+package secret
+
+import (
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "secretv4"
+)
+
+// Config represents the configuration used to create a new secret resource.
+type Config struct {
+	K8sClient kubernetes.Interface
+	Logger    micrologger.Logger
+
+	// EtcdCAFile, EtcdCrtFile and EtcdKeyFile are the paths of the etcd
+	// certificates the operator itself uses. Their content is copied into the
+	// secret mounted by the flanneld daemon set.
+	EtcdCAFile  string
+	EtcdCrtFile string
+	EtcdKeyFile string
+}
+
+// Resource implements the secret resource. It manages the secret holding the
+// etcd client certificates flanneld uses to connect to etcd.
+type Resource struct {
+	k8sClient kubernetes.Interface
+	logger    micrologger.Logger
+
+	etcdCAFile  string
+	etcdCrtFile string
+	etcdKeyFile string
+}
+
+// New creates a new configured secret resource.
+func New(config Config) (*Resource, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.EtcdCAFile == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.EtcdCAFile must not be empty", config)
+	}
+	if config.EtcdCrtFile == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.EtcdCrtFile must not be empty", config)
+	}
+	if config.EtcdKeyFile == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.EtcdKeyFile must not be empty", config)
+	}
+
+	r := &Resource{
+		k8sClient: config.K8sClient,
+		logger:    config.Logger,
+
+		etcdCAFile:  config.EtcdCAFile,
+		etcdCrtFile: config.EtcdCrtFile,
+		etcdKeyFile: config.EtcdKeyFile,
+	}
+
+	return r, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
+
+func toSecret(v interface{}) (*corev1.Secret, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	secret, ok := v.(*corev1.Secret)
+	if !ok {
+		return nil, microerror.Maskf(wrongTypeError, "expected '%T', got '%T'", &corev1.Secret{}, v)
+	}
+
+	return secret, nil
+}
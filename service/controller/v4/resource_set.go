@@ -0,0 +1,476 @@
+package v4
+
+import (
+	"time"
+
+	"github.com/giantswarm/k8sclient"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/operatorkit/controller"
+	"github.com/giantswarm/operatorkit/resource"
+	"github.com/giantswarm/operatorkit/resource/crud"
+	"github.com/giantswarm/operatorkit/resource/wrapper/metricsresource"
+	"github.com/giantswarm/operatorkit/resource/wrapper/retryresource"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/giantswarm/flannel-operator/pkg/apply"
+	"github.com/giantswarm/flannel-operator/pkg/shard"
+	"github.com/giantswarm/flannel-operator/pkg/status"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/etcd"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/key"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/portallocator"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/resource/clusterrolebindings"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/resource/deletionprotection"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/resource/flanneld"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/resource/legacy"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/resource/monitoring"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/resource/namespace"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/resource/networkconfig"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/resource/nodestatus"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/resource/paused"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/resource/role"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/resource/secret"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/workloadgate"
+)
+
+type ResourceSetConfig struct {
+	EventRecorder record.EventRecorder
+	K8sClient     k8sclient.Interface
+	Logger        micrologger.Logger
+	// Shard decides which tenant clusters are reconciled by this operator
+	// replica.
+	Shard shard.Interface
+
+	CAFile        string
+	CrtFile       string
+	EtcdEndpoints []string
+	KeyFile       string
+
+	// FlanneldOptions are the operator wide flanneld options applied to every
+	// tenant cluster network.
+	FlanneldOptions flanneld.Options
+	// HealthPortMax, HealthPortMin and HealthPortReserved configure the host
+	// ports health endpoints of tenant cluster networks are allocated from.
+	HealthPortMax      int
+	HealthPortMin      int
+	HealthPortReserved []int
+	// LivenessProbe and ReadinessProbe configure the probes of the network
+	// containers.
+	LivenessProbe  flanneld.Probe
+	ReadinessProbe flanneld.Probe
+	// MonitoringEnabled exposes the health endpoints of tenant cluster networks
+	// to Prometheus.
+	MonitoringEnabled bool
+	// NetworkNamespaceBaseline configures the network policy, resource quota
+	// and limit range managed in the network namespaces.
+	NetworkNamespaceBaseline namespace.Baseline
+	// WorkloadLabelSelector and WorkloadMaxWait configure the workload gate
+	// holding the network teardown of deleted tenant clusters.
+	WorkloadLabelSelector string
+	WorkloadMaxWait       time.Duration
+}
+
+func NewResourceSet(config ResourceSetConfig) (*controller.ResourceSet, error) {
+	if config.EventRecorder == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.EventRecorder must not be empty")
+	}
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.K8sClient must not be empty")
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
+	}
+	if config.Shard == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Shard must not be empty")
+	}
+
+	if config.CrtFile == "" {
+		return nil, microerror.Maskf(invalidConfigError, "config.CrtFile must not be empty")
+	}
+	if len(config.EtcdEndpoints) == 0 {
+		return nil, microerror.Maskf(invalidConfigError, "config.EtcdEndpoints must not be empty")
+	}
+	if config.KeyFile == "" {
+		return nil, microerror.Maskf(invalidConfigError, "config.KeyFile must not be empty")
+	}
+
+	var err error
+
+	etcdClientConfig := etcd.ClientConfig{
+		Endpoints: config.EtcdEndpoints,
+
+		CAFile:  config.CAFile,
+		CrtFile: config.CrtFile,
+		KeyFile: config.KeyFile,
+	}
+
+	var storageService etcd.Store
+	{
+		storageService, err = etcd.NewStore(etcdClientConfig)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var portAllocator portallocator.Interface
+	{
+		c := portallocator.Config{
+			G8sClient: config.K8sClient.G8sClient(),
+			Logger:    config.Logger,
+
+			FlanneldHealthzPort: config.FlanneldOptions.HealthzPort,
+			Max:                 config.HealthPortMax,
+			Min:                 config.HealthPortMin,
+			Reserved:            config.HealthPortReserved,
+		}
+
+		portAllocator, err = portallocator.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var applier apply.Interface
+	{
+		c := apply.Config{
+			DynClient: config.K8sClient.DynClient(),
+			Logger:    config.Logger,
+		}
+
+		applier, err = apply.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var statusWriter status.Interface
+	{
+		c := status.Config{
+			G8sClient: config.K8sClient.G8sClient(),
+			Logger:    config.Logger,
+		}
+
+		statusWriter, err = status.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var workloadGate workloadgate.Interface
+	{
+		c := workloadgate.Config{
+			EventRecorder: config.EventRecorder,
+			K8sClient:     config.K8sClient.K8sClient(),
+			Logger:        config.Logger,
+			StatusWriter:  statusWriter,
+
+			LabelSelector: config.WorkloadLabelSelector,
+			MaxWait:       config.WorkloadMaxWait,
+		}
+
+		workloadGate, err = workloadgate.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var clusterRoleBindingsResource resource.Interface
+	{
+		c := clusterrolebindings.Config{
+			Applier:   applier,
+			K8sClient: config.K8sClient.K8sClient(),
+			Logger:    config.Logger,
+		}
+
+		clusterRoleBindingsResource, err = clusterrolebindings.NewResource(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var deletionProtectionResource resource.Interface
+	{
+		c := deletionprotection.Config{
+			EventRecorder: config.EventRecorder,
+			Logger:        config.Logger,
+			StatusWriter:  statusWriter,
+		}
+
+		deletionProtectionResource, err = deletionprotection.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var flanneldResource resource.Interface
+	{
+		c := flanneld.Config{
+			Applier:       applier,
+			EtcdEndpoints: config.EtcdEndpoints,
+			K8sClient:     config.K8sClient.K8sClient(),
+			Logger:        config.Logger,
+			PortAllocator: portAllocator,
+			WorkloadGate:  workloadGate,
+
+			MonitoringEnabled: config.MonitoringEnabled,
+			LivenessProbe:     config.LivenessProbe,
+			ReadinessProbe:    config.ReadinessProbe,
+			Options:           config.FlanneldOptions,
+		}
+
+		ops, err := flanneld.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		flanneldResource, err = toCRUDResource(config.Logger, ops)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var legacyResource resource.Interface
+	{
+		legacyConfig := legacy.DefaultConfig()
+
+		legacyConfig.Applier = applier
+		legacyConfig.EventRecorder = config.EventRecorder
+		legacyConfig.K8sClient = config.K8sClient.K8sClient()
+		legacyConfig.Logger = config.Logger
+		legacyConfig.StatusWriter = statusWriter
+		legacyConfig.WorkloadGate = workloadGate
+
+		legacyConfig.EtcdCAFile = config.CAFile
+		legacyConfig.EtcdCrtFile = config.CrtFile
+		legacyConfig.EtcdKeyFile = config.KeyFile
+
+		ops, err := legacy.New(legacyConfig)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		legacyResource, err = toCRUDResource(config.Logger, ops)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var monitoringResource resource.Interface
+	{
+		c := monitoring.Config{
+			DynClient:     config.K8sClient.DynClient(),
+			K8sClient:     config.K8sClient.K8sClient(),
+			Logger:        config.Logger,
+			PortAllocator: portAllocator,
+		}
+
+		monitoringResource, err = monitoring.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var networkConfigResource resource.Interface
+	{
+		c := networkconfig.Config{
+			K8sClient:  config.K8sClient.K8sClient(),
+			Logger:     config.Logger,
+			Store:      storageService,
+			StoreCache: etcd.NewStoreCache(etcd.NewStore),
+
+			EtcdClientConfig: etcdClientConfig,
+		}
+
+		ops, err := networkconfig.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		networkConfigResource, err = toCRUDResource(config.Logger, ops)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var nodeStatusResource resource.Interface
+	{
+		c := nodestatus.Config{
+			K8sClient:    config.K8sClient.K8sClient(),
+			Logger:       config.Logger,
+			StatusWriter: statusWriter,
+		}
+
+		nodeStatusResource, err = nodestatus.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var pausedResource resource.Interface
+	{
+		c := paused.Config{
+			EventRecorder: config.EventRecorder,
+			Logger:        config.Logger,
+		}
+
+		pausedResource, err = paused.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var roleResource resource.Interface
+	{
+		c := role.Config{
+			Applier: applier,
+			Logger:  config.Logger,
+		}
+
+		roleResource, err = role.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var secretResource resource.Interface
+	{
+		c := secret.Config{
+			K8sClient: config.K8sClient.K8sClient(),
+			Logger:    config.Logger,
+
+			EtcdCAFile:  config.CAFile,
+			EtcdCrtFile: config.CrtFile,
+			EtcdKeyFile: config.KeyFile,
+		}
+
+		ops, err := secret.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		secretResource, err = toCRUDResource(config.Logger, ops)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var namespaceResource resource.Interface
+	{
+		c := namespace.Config{
+			Applier:      applier,
+			K8sClient:    config.K8sClient.K8sClient(),
+			Logger:       config.Logger,
+			WorkloadGate: workloadGate,
+
+			Baseline: config.NetworkNamespaceBaseline,
+		}
+
+		ops, err := namespace.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		namespaceResource, err = toCRUDResource(config.Logger, ops)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	// The paused resource has to run first. It cancels the reconciliation of
+	// paused FlannelConfigs before any other resource touches the network.
+	// The deletionprotection resource has to run next. It cancels the
+	// reconciliation of deleted FlannelConfigs which are protected before any
+	// other resource starts tearing down the network.
+	// The clusterrolebindings resource has to run after the legacy resource. The
+	// destroyer pods scheduled by the legacy resource on deletion need the
+	// bindings until the network cleanup is done.
+	// The networkconfig resource runs before the other network resources on
+	// creation. On deletion it waits for the legacy resource to finish the
+	// network cleanup before it removes the network state from etcd.
+	resources := []resource.Interface{
+		pausedResource,
+		deletionProtectionResource,
+		networkConfigResource,
+		namespaceResource,
+		secretResource,
+		roleResource,
+		legacyResource,
+		clusterRoleBindingsResource,
+		flanneldResource,
+		nodeStatusResource,
+	}
+
+	// The monitoring resource is optional. It has to run after the flanneld
+	// resource which allocates the ports the network pods listen on.
+	if config.MonitoringEnabled {
+		resources = append(resources, monitoringResource)
+	}
+
+	{
+		c := retryresource.WrapConfig{
+			Logger: config.Logger,
+		}
+
+		resources, err = retryresource.Wrap(resources, c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	{
+		c := metricsresource.WrapConfig{}
+
+		resources, err = metricsresource.Wrap(resources, c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	handlesFunc := func(obj interface{}) bool {
+		customObject, err := key.ToCustomObject(obj)
+		if err != nil {
+			return false
+		}
+
+		if key.VersionBundleVersion(customObject) != VersionBundle().Version {
+			return false
+		}
+		// FlannelConfigs of tenant clusters owned by other shards are reconciled
+		// by other operator replicas.
+		if !config.Shard.Owns(key.ClusterID(customObject)) {
+			return false
+		}
+
+		return true
+	}
+
+	var resourceSet *controller.ResourceSet
+	{
+		c := controller.ResourceSetConfig{
+			Handles:   handlesFunc,
+			Logger:    config.Logger,
+			Resources: resources,
+		}
+
+		resourceSet, err = controller.NewResourceSet(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	return resourceSet, nil
+}
+
+func toCRUDResource(logger micrologger.Logger, ops crud.Interface) (resource.Interface, error) {
+	c := crud.ResourceConfig{
+		CRUD:   ops,
+		Logger: logger,
+	}
+
+	r, err := crud.NewResource(c)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return r, nil
+}
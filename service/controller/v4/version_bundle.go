@@ -0,0 +1,25 @@
+package v4
+
+import (
+	"github.com/giantswarm/versionbundle"
+)
+
+func VersionBundle() versionbundle.Bundle {
+	return versionbundle.Bundle{
+		Changelogs: []versionbundle.Changelog{
+			{
+				Component:   "flannel",
+				Description: "Flannel version updated to 0.12.0.",
+				Kind:        "changed",
+			},
+		},
+		Components: []versionbundle.Component{
+			{
+				Name:    "flannel",
+				Version: "0.12.0",
+			},
+		},
+		Name:    "flannel-operator",
+		Version: "0.3.0",
+	}
+}
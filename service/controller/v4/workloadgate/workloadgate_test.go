@@ -0,0 +1,246 @@
+package workloadgate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	g8sfake "github.com/giantswarm/apiextensions/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/micrologger/microloggertest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/giantswarm/flannel-operator/pkg/status"
+	"github.com/giantswarm/flannel-operator/service/controller/v4/key"
+)
+
+func newPod(name string, phase corev1.PodPhase, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "al9qy",
+			Labels:    labels,
+		},
+		Status: corev1.PodStatus{
+			Phase: phase,
+		},
+	}
+}
+
+func Test_Gate_Wait(t *testing.T) {
+	deleted := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		Name           string
+		Objects        []runtime.Object
+		LabelSelector  string
+		MaxWait        time.Duration
+		WaitStarted    string
+		Elapsed        time.Duration
+		ExpectedWait   bool
+		ExpectedEvents int
+		// ExpectedWaitStarted is the annotation the FlannelConfig is expected
+		// to carry afterwards.
+		ExpectedWaitStarted string
+	}{
+		{
+			Name:         "case 0: no pods do not hold the teardown",
+			Objects:      nil,
+			ExpectedWait: false,
+		},
+		{
+			Name: "case 1: running pods hold the teardown",
+			Objects: []runtime.Object{
+				newPod("worker-1", corev1.PodRunning, nil),
+			},
+			ExpectedWait: true,
+		},
+		{
+			Name: "case 2: completed and evicted pods do not hold the teardown",
+			Objects: []runtime.Object{
+				newPod("cleanup-job-x7k2p", corev1.PodSucceeded, nil),
+				newPod("worker-1", corev1.PodFailed, nil),
+			},
+			ExpectedWait: false,
+		},
+		{
+			Name: "case 3: pods not matching the label selector do not hold the teardown",
+			Objects: []runtime.Object{
+				newPod("sidecar", corev1.PodRunning, map[string]string{"app": "sidecar"}),
+			},
+			LabelSelector: "app=worker",
+			ExpectedWait:  false,
+		},
+		{
+			Name: "case 4: pods matching the label selector hold the teardown",
+			Objects: []runtime.Object{
+				newPod("worker-1", corev1.PodRunning, map[string]string{"app": "worker"}),
+			},
+			LabelSelector: "app=worker",
+			ExpectedWait:  true,
+		},
+		{
+			Name: "case 5: pods hold the teardown up to the maximum wait",
+			Objects: []runtime.Object{
+				newPod("worker-1", corev1.PodRunning, nil),
+			},
+			MaxWait:             time.Hour,
+			WaitStarted:         deleted.Format(time.RFC3339),
+			Elapsed:             59 * time.Minute,
+			ExpectedWait:        true,
+			ExpectedWaitStarted: deleted.Format(time.RFC3339),
+		},
+		{
+			Name: "case 6: the teardown proceeds with a warning after the maximum wait",
+			Objects: []runtime.Object{
+				newPod("worker-1", corev1.PodRunning, nil),
+			},
+			MaxWait:             time.Hour,
+			WaitStarted:         deleted.Format(time.RFC3339),
+			Elapsed:             time.Hour,
+			ExpectedWait:        false,
+			ExpectedEvents:      1,
+			ExpectedWaitStarted: deleted.Format(time.RFC3339),
+		},
+		{
+			Name: "case 7: pods hold the teardown forever without maximum wait",
+			Objects: []runtime.Object{
+				newPod("worker-1", corev1.PodRunning, nil),
+			},
+			MaxWait:      0,
+			Elapsed:      24 * time.Hour,
+			ExpectedWait: true,
+		},
+		{
+			Name: "case 8: the maximum wait is counted from the first time the teardown is held",
+			Objects: []runtime.Object{
+				newPod("worker-1", corev1.PodRunning, nil),
+			},
+			MaxWait:             time.Hour,
+			Elapsed:             24 * time.Hour,
+			ExpectedWait:        true,
+			ExpectedWaitStarted: deleted.Add(24 * time.Hour).Format(time.RFC3339),
+		},
+		{
+			Name: "case 9: an invalid wait start restarts the wait",
+			Objects: []runtime.Object{
+				newPod("worker-1", corev1.PodRunning, nil),
+			},
+			MaxWait:             time.Hour,
+			WaitStarted:         "yesterday",
+			Elapsed:             24 * time.Hour,
+			ExpectedWait:        true,
+			ExpectedWaitStarted: deleted.Add(24 * time.Hour).Format(time.RFC3339),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			customObject := v1alpha1.FlannelConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "al9qy",
+					Namespace:         "default",
+					UID:               "uid-1",
+					Annotations:       map[string]string{},
+					DeletionTimestamp: &metav1.Time{Time: deleted},
+				},
+				Spec: v1alpha1.FlannelConfigSpec{
+					Cluster: v1alpha1.FlannelConfigSpecCluster{
+						ID:        "al9qy",
+						Namespace: "al9qy",
+					},
+				},
+			}
+			if tc.WaitStarted != "" {
+				customObject.Annotations[key.AnnotationWorkloadWaitStarted] = tc.WaitStarted
+			}
+
+			g8sClient := g8sfake.NewSimpleClientset(&customObject)
+			recorder := record.NewFakeRecorder(10)
+
+			var statusWriter status.Interface
+			{
+				c := status.Config{
+					G8sClient: g8sClient,
+					Logger:    microloggertest.New(),
+				}
+
+				w, err := status.New(c)
+				if err != nil {
+					t.Fatal("expected", nil, "got", err)
+				}
+				statusWriter = w
+			}
+
+			c := Config{
+				EventRecorder: recorder,
+				K8sClient:     fake.NewSimpleClientset(tc.Objects...),
+				Logger:        microloggertest.New(),
+				StatusWriter:  statusWriter,
+
+				LabelSelector: tc.LabelSelector,
+				MaxWait:       tc.MaxWait,
+			}
+
+			g, err := New(c)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			g.now = func() time.Time { return deleted.Add(tc.Elapsed) }
+
+			// The gate is asked twice, like it is by the resources sharing it,
+			// to verify the warning is only emitted once.
+			for i := 0; i < 2; i++ {
+				wait, err := g.Wait(context.Background(), customObject)
+				if err != nil {
+					t.Fatal("expected", nil, "got", err)
+				}
+				if wait != tc.ExpectedWait {
+					t.Fatalf("expected wait %t got %t", tc.ExpectedWait, wait)
+				}
+			}
+
+			if len(recorder.Events) != tc.ExpectedEvents {
+				t.Fatalf("expected %d events got %d", tc.ExpectedEvents, len(recorder.Events))
+			}
+
+			updated, err := g8sClient.CoreV1alpha1().FlannelConfigs("default").Get("al9qy", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			if updated.Annotations[key.AnnotationWorkloadWaitStarted] != tc.ExpectedWaitStarted {
+				t.Fatalf("expected wait started %#q got %#q", tc.ExpectedWaitStarted, updated.Annotations[key.AnnotationWorkloadWaitStarted])
+			}
+
+			g.Forget(customObject)
+			if len(g.expired) != 0 || len(g.started) != 0 {
+				t.Fatalf("expected no state after forgetting got %d expired and %d started", len(g.expired), len(g.started))
+			}
+		})
+	}
+}
+
+func Test_Gate_New_InvalidLabelSelector(t *testing.T) {
+	statusWriter, err := status.New(status.Config{G8sClient: g8sfake.NewSimpleClientset(), Logger: microloggertest.New()})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	c := Config{
+		EventRecorder: record.NewFakeRecorder(10),
+		K8sClient:     fake.NewSimpleClientset(),
+		Logger:        microloggertest.New(),
+		StatusWriter:  statusWriter,
+
+		LabelSelector: "app in (",
+	}
+
+	_, err = New(c)
+	if !IsInvalidConfig(err) {
+		t.Fatalf("expected invalid config error got %#v", err)
+	}
+}
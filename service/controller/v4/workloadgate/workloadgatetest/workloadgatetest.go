@@ -0,0 +1,26 @@
+// Package workloadgatetest provides a workloadgate.Interface implementation
+// for tests.
+package workloadgatetest
+
+import (
+	"context"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+)
+
+type Gate struct {
+	wait bool
+}
+
+// New returns a gate which always returns the given result.
+func New(wait bool) *Gate {
+	return &Gate{
+		wait: wait,
+	}
+}
+
+func (g *Gate) Wait(ctx context.Context, customObject v1alpha1.FlannelConfig) (bool, error) {
+	return g.wait, nil
+}
+
+func (g *Gate) Forget(customObject v1alpha1.FlannelConfig) {}
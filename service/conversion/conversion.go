@@ -0,0 +1,234 @@
+// Package conversion implements the conversion webhook of the FlannelConfig
+// CRD. The Kubernetes API calls it to convert FlannelConfigs between the
+// served API versions, so that v1alpha1 and v1alpha2 clients see the same
+// objects. Other than the controller, the webhook runs on every operator
+// replica, because the Kubernetes API may call any of them.
+package conversion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/giantswarm/flannel-operator/pkg/apis/core/v1alpha2"
+)
+
+const (
+	// Path is the path the webhook serves ConversionReviews on.
+	Path = "/convert"
+)
+
+type Config struct {
+	Logger micrologger.Logger
+
+	// Enabled enables the webhook. Boot returns right away otherwise.
+	Enabled bool
+	// CrtFile and KeyFile are the TLS certificate and key the webhook serves
+	// with. The Kubernetes API only calls webhooks via HTTPS.
+	CrtFile string
+	KeyFile string
+	// ListenAddress is the address the webhook listens on, e.g. ":8443".
+	ListenAddress string
+}
+
+type Webhook struct {
+	logger micrologger.Logger
+
+	enabled       bool
+	crtFile       string
+	keyFile       string
+	listenAddress string
+}
+
+func New(config Config) (*Webhook, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.Enabled {
+		if config.CrtFile == "" {
+			return nil, microerror.Maskf(invalidConfigError, "%T.CrtFile must not be empty", config)
+		}
+		if config.KeyFile == "" {
+			return nil, microerror.Maskf(invalidConfigError, "%T.KeyFile must not be empty", config)
+		}
+		if config.ListenAddress == "" {
+			return nil, microerror.Maskf(invalidConfigError, "%T.ListenAddress must not be empty", config)
+		}
+	}
+
+	w := &Webhook{
+		logger: config.Logger,
+
+		enabled:       config.Enabled,
+		crtFile:       config.CrtFile,
+		keyFile:       config.KeyFile,
+		listenAddress: config.ListenAddress,
+	}
+
+	return w, nil
+}
+
+// Boot serves the webhook until the given context is done.
+func (w *Webhook) Boot(ctx context.Context) {
+	if !w.enabled {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(Path, w)
+
+	server := &http.Server{
+		Addr:    w.listenAddress,
+		Handler: mux,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		err := server.Shutdown(shutdownCtx)
+		if err != nil {
+			w.logger.LogCtx(ctx, "level", "error", "message", "failed to shut down conversion webhook", "stack", fmt.Sprintf("%#v", err))
+		}
+	}()
+
+	w.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("serving conversion webhook on %#q", w.listenAddress))
+
+	err := server.ListenAndServeTLS(w.crtFile, w.keyFile)
+	if err != nil && err != http.ErrServerClosed {
+		w.logger.LogCtx(ctx, "level", "error", "message", "failed to serve conversion webhook", "stack", fmt.Sprintf("%#v", err))
+	}
+}
+
+// ServeHTTP implements http.Handler. It answers ConversionReviews. Objects
+// which cannot be converted fail the whole review, as the Kubernetes API
+// expects.
+func (w *Webhook) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Method != http.MethodPost {
+		http.Error(rw, fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var review apiextensionsv1.ConversionReview
+	err = json.Unmarshal(body, &review)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(rw, "conversion review must contain a request", http.StatusBadRequest)
+		return
+	}
+
+	response := &apiextensionsv1.ConversionResponse{
+		UID: review.Request.UID,
+	}
+
+	for _, o := range review.Request.Objects {
+		converted, err := Convert(o.Raw, review.Request.DesiredAPIVersion)
+		if err != nil {
+			w.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("failed to convert object to %#q", review.Request.DesiredAPIVersion), "stack", fmt.Sprintf("%#v", err))
+
+			response.ConvertedObjects = nil
+			response.Result = metav1.Status{
+				Status:  metav1.StatusFailure,
+				Message: err.Error(),
+			}
+			break
+		}
+
+		response.ConvertedObjects = append(response.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+
+	if response.Result.Status == "" {
+		response.Result = metav1.Status{
+			Status: metav1.StatusSuccess,
+		}
+	}
+
+	review.Request = nil
+	review.Response = response
+
+	b, err := json.Marshal(review)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	_, err = rw.Write(b)
+	if err != nil {
+		w.logger.LogCtx(ctx, "level", "error", "message", "failed to write conversion review", "stack", fmt.Sprintf("%#v", err))
+	}
+}
+
+// Convert converts the given JSON encoded FlannelConfig to the desired API
+// version. FlannelConfigs which already have the desired API version are
+// returned as they are.
+func Convert(raw []byte, desiredAPIVersion string) ([]byte, error) {
+	var typeMeta metav1.TypeMeta
+	err := json.Unmarshal(raw, &typeMeta)
+	if err != nil {
+		return nil, microerror.Maskf(invalidRequestError, "%s", err)
+	}
+
+	if typeMeta.APIVersion == desiredAPIVersion {
+		return raw, nil
+	}
+
+	var converted interface{}
+	switch {
+	case typeMeta.APIVersion == v1alpha1.SchemeGroupVersion.String() && desiredAPIVersion == v1alpha2.SchemeGroupVersion.String():
+		var customObject v1alpha1.FlannelConfig
+		err := json.Unmarshal(raw, &customObject)
+		if err != nil {
+			return nil, microerror.Maskf(invalidRequestError, "%s", err)
+		}
+
+		converted, err = v1alpha2.ConvertFromV1alpha1(&customObject)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	case typeMeta.APIVersion == v1alpha2.SchemeGroupVersion.String() && desiredAPIVersion == v1alpha1.SchemeGroupVersion.String():
+		var customObject v1alpha2.FlannelConfig
+		err := json.Unmarshal(raw, &customObject)
+		if err != nil {
+			return nil, microerror.Maskf(invalidRequestError, "%s", err)
+		}
+
+		converted, err = v1alpha2.ConvertToV1alpha1(&customObject)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	default:
+		return nil, microerror.Maskf(unsupportedVersionError, "cannot convert %#q to %#q", typeMeta.APIVersion, desiredAPIVersion)
+	}
+
+	b, err := json.Marshal(converted)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return b, nil
+}
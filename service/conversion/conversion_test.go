@@ -0,0 +1,195 @@
+package conversion
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/giantswarm/flannel-operator/pkg/apis/core/v1alpha2"
+)
+
+const (
+	v1alpha1Object = `{"apiVersion":"core.giantswarm.io/v1alpha1","kind":"FlannelConfig","metadata":{"name":"al9qy","namespace":"default","annotations":{"flannel-operator.giantswarm.io/health-port":"21397"}},"spec":{"flannel":{"spec":{"network":"10.1.0.0/16","vni":26}},"cluster":{"id":"al9qy"}}}`
+	v1alpha2Object = `{"apiVersion":"core.giantswarm.io/v1alpha2","kind":"FlannelConfig","metadata":{"name":"al9qy","namespace":"default"},"spec":{"backend":{"type":"host-gw","vni":26},"network":{"cidr":"10.1.0.0/16"},"cluster":{"id":"al9qy"}}}`
+)
+
+func Test_Convert(t *testing.T) {
+	testCases := []struct {
+		Name               string
+		Raw                string
+		DesiredAPIVersion  string
+		ExpectedAPIVersion string
+		ErrorMatcher       func(err error) bool
+	}{
+		{
+			Name:               "case 0: v1alpha1 is converted to v1alpha2",
+			Raw:                v1alpha1Object,
+			DesiredAPIVersion:  "core.giantswarm.io/v1alpha2",
+			ExpectedAPIVersion: "core.giantswarm.io/v1alpha2",
+			ErrorMatcher:       nil,
+		},
+		{
+			Name:               "case 1: v1alpha2 is converted to v1alpha1",
+			Raw:                v1alpha2Object,
+			DesiredAPIVersion:  "core.giantswarm.io/v1alpha1",
+			ExpectedAPIVersion: "core.giantswarm.io/v1alpha1",
+			ErrorMatcher:       nil,
+		},
+		{
+			Name:               "case 2: objects of the desired version are returned as they are",
+			Raw:                v1alpha2Object,
+			DesiredAPIVersion:  "core.giantswarm.io/v1alpha2",
+			ExpectedAPIVersion: "core.giantswarm.io/v1alpha2",
+			ErrorMatcher:       nil,
+		},
+		{
+			Name:              "case 3: unknown versions are rejected",
+			Raw:               v1alpha1Object,
+			DesiredAPIVersion: "core.giantswarm.io/v1beta1",
+			ErrorMatcher:      IsUnsupportedVersion,
+		},
+		{
+			Name:              "case 4: invalid objects are rejected",
+			Raw:               `{`,
+			DesiredAPIVersion: "core.giantswarm.io/v1alpha2",
+			ErrorMatcher:      IsInvalidRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			b, err := Convert([]byte(tc.Raw), tc.DesiredAPIVersion)
+
+			switch {
+			case err == nil && tc.ErrorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.ErrorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.ErrorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.ErrorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if tc.ErrorMatcher != nil {
+				return
+			}
+
+			var typeMeta metav1.TypeMeta
+			err = json.Unmarshal(b, &typeMeta)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			if typeMeta.APIVersion != tc.ExpectedAPIVersion {
+				t.Fatalf("expected api version %#q got %#q", tc.ExpectedAPIVersion, typeMeta.APIVersion)
+			}
+		})
+	}
+}
+
+func Test_Webhook_ServeHTTP(t *testing.T) {
+	testCases := []struct {
+		Name               string
+		Objects            []string
+		ExpectedStatus     string
+		ExpectedObjects    int
+		ExpectedHealthPort int
+	}{
+		{
+			Name:               "case 0: all objects are converted",
+			Objects:            []string{v1alpha1Object, v1alpha1Object},
+			ExpectedStatus:     metav1.StatusSuccess,
+			ExpectedObjects:    2,
+			ExpectedHealthPort: 21397,
+		},
+		{
+			Name:            "case 1: a single invalid object fails the review",
+			Objects:         []string{v1alpha1Object, `{"apiVersion":"core.giantswarm.io/v1beta1"}`},
+			ExpectedStatus:  metav1.StatusFailure,
+			ExpectedObjects: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			w, err := New(Config{Logger: microloggertest.New()})
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			review := apiextensionsv1.ConversionReview{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "apiextensions.k8s.io/v1",
+					Kind:       "ConversionReview",
+				},
+				Request: &apiextensionsv1.ConversionRequest{
+					UID:               "f1c2d3",
+					DesiredAPIVersion: "core.giantswarm.io/v1alpha2",
+				},
+			}
+			for _, o := range tc.Objects {
+				review.Request.Objects = append(review.Request.Objects, runtime.RawExtension{Raw: []byte(o)})
+			}
+
+			b, err := json.Marshal(review)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			rec := httptest.NewRecorder()
+			w.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, Path, bytes.NewReader(b)))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status code %d got %d", http.StatusOK, rec.Code)
+			}
+
+			var response apiextensionsv1.ConversionReview
+			err = json.Unmarshal(rec.Body.Bytes(), &response)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			if response.Response.UID != "f1c2d3" {
+				t.Fatalf("expected uid %#q got %#q", "f1c2d3", response.Response.UID)
+			}
+			if response.Response.Result.Status != tc.ExpectedStatus {
+				t.Fatalf("expected status %#q got %#q", tc.ExpectedStatus, response.Response.Result.Status)
+			}
+			if len(response.Response.ConvertedObjects) != tc.ExpectedObjects {
+				t.Fatalf("expected %d objects got %d", tc.ExpectedObjects, len(response.Response.ConvertedObjects))
+			}
+
+			for _, o := range response.Response.ConvertedObjects {
+				var customObject v1alpha2.FlannelConfig
+				err := json.Unmarshal(o.Raw, &customObject)
+				if err != nil {
+					t.Fatal("expected", nil, "got", err)
+				}
+				if customObject.Status.HealthPort != tc.ExpectedHealthPort {
+					t.Fatalf("expected health port %d got %d", tc.ExpectedHealthPort, customObject.Status.HealthPort)
+				}
+			}
+		})
+	}
+}
+
+func Test_New_InvalidConfig(t *testing.T) {
+	c := Config{
+		Logger: microloggertest.New(),
+
+		Enabled:       true,
+		ListenAddress: ":8443",
+	}
+
+	_, err := New(c)
+	if !IsInvalidConfig(err) {
+		t.Fatalf("expected invalid config error got %#v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package healthcheck
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/giantswarm/microendpoint/service/healthz"
+	"github.com/giantswarm/microerror"
+)
+
+const (
+	// CertificateDescription describes the certificate health check.
+	CertificateDescription = "Ensure the certificates the operator uses are valid."
+	// CertificateName identifies the certificate health check.
+	CertificateName = "certificate"
+)
+
+type CertificateConfig struct {
+	// Files are the paths of PEM encoded certificate files.
+	Files []string
+}
+
+// Certificate checks the expiry of certificate files. Files are read on every
+// check, so that rotated certificates are picked up.
+type Certificate struct {
+	files []string
+	now   func() time.Time
+}
+
+func NewCertificate(config CertificateConfig) (*Certificate, error) {
+	if len(config.Files) == 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Files must not be empty", config)
+	}
+	for _, f := range config.Files {
+		if f == "" {
+			return nil, microerror.Maskf(invalidConfigError, "%T.Files must not contain empty paths", config)
+		}
+	}
+
+	c := &Certificate{
+		files: config.Files,
+		now:   time.Now,
+	}
+
+	return c, nil
+}
+
+// GetHealthz implements healthz.Service. It fails in case any certificate is
+// not valid at the moment. Otherwise the earliest expiry is reported.
+func (c *Certificate) GetHealthz(ctx context.Context) (healthz.Response, error) {
+	r := healthz.Response{
+		Description: CertificateDescription,
+		Name:        CertificateName,
+	}
+
+	now := c.now()
+
+	var earliest *x509.Certificate
+	var earliestFile string
+	for _, f := range c.files {
+		certs, err := readCertificates(f)
+		if err != nil {
+			r.Failed = true
+			r.Message = fmt.Sprintf("failed to read certificates from %#q: %s", f, err)
+			return r, nil
+		}
+
+		for _, cert := range certs {
+			if now.Before(cert.NotBefore) {
+				r.Failed = true
+				r.Message = fmt.Sprintf("certificate %#q in %#q is not valid before %s", cert.Subject.CommonName, f, cert.NotBefore.UTC().Format(time.RFC3339))
+				return r, nil
+			}
+			if now.After(cert.NotAfter) {
+				r.Failed = true
+				r.Message = fmt.Sprintf("certificate %#q in %#q expired at %s", cert.Subject.CommonName, f, cert.NotAfter.UTC().Format(time.RFC3339))
+				return r, nil
+			}
+
+			if earliest == nil || cert.NotAfter.Before(earliest.NotAfter) {
+				earliest = cert
+				earliestFile = f
+			}
+		}
+	}
+
+	r.Message = fmt.Sprintf("certificate %#q in %#q expires first at %s", earliest.Subject.CommonName, earliestFile, earliest.NotAfter.UTC().Format(time.RFC3339))
+
+	return r, nil
+}
+
+func readCertificates(file string) ([]*x509.Certificate, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, b = pem.Decode(b)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		return nil, microerror.Maskf(invalidConfigError, "file must hold PEM encoded certificates")
+	}
+
+	return certs, nil
+}
@@ -0,0 +1,153 @@
+package healthcheck
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Certificate_GetHealthz(t *testing.T) {
+	now := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	dir, err := ioutil.TempDir("", "healthcheck")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	defer os.RemoveAll(dir)
+
+	valid := writeCertificate(t, dir, "valid", now.Add(-time.Hour), now.Add(30*24*time.Hour))
+	soon := writeCertificate(t, dir, "soon", now.Add(-time.Hour), now.Add(24*time.Hour))
+	expired := writeCertificate(t, dir, "expired", now.Add(-48*time.Hour), now.Add(-24*time.Hour))
+	future := writeCertificate(t, dir, "future", now.Add(time.Hour), now.Add(48*time.Hour))
+
+	garbage := filepath.Join(dir, "garbage.pem")
+	err = ioutil.WriteFile(garbage, []byte("garbage"), 0600)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	testCases := []struct {
+		Name            string
+		Files           []string
+		ExpectedFailed  bool
+		ExpectedMessage string
+	}{
+		{
+			Name:            "case 0: valid certificates are healthy",
+			Files:           []string{valid, soon},
+			ExpectedFailed:  false,
+			ExpectedMessage: "`soon`",
+		},
+		{
+			Name:            "case 1: expired certificates are unhealthy",
+			Files:           []string{valid, expired},
+			ExpectedFailed:  true,
+			ExpectedMessage: "expired",
+		},
+		{
+			Name:            "case 2: certificates which are not valid yet are unhealthy",
+			Files:           []string{future},
+			ExpectedFailed:  true,
+			ExpectedMessage: "not valid before",
+		},
+		{
+			Name:            "case 3: missing files are unhealthy",
+			Files:           []string{filepath.Join(dir, "missing.pem")},
+			ExpectedFailed:  true,
+			ExpectedMessage: "failed to read",
+		},
+		{
+			Name:            "case 4: files without certificates are unhealthy",
+			Files:           []string{garbage},
+			ExpectedFailed:  true,
+			ExpectedMessage: "failed to read",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			c, err := NewCertificate(CertificateConfig{Files: tc.Files})
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			c.now = func() time.Time { return now }
+
+			r, err := c.GetHealthz(context.Background())
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			if r.Failed != tc.ExpectedFailed {
+				t.Fatalf("expected failed %t got %t: %s", tc.ExpectedFailed, r.Failed, r.Message)
+			}
+			if !strings.Contains(r.Message, tc.ExpectedMessage) {
+				t.Fatalf("expected message containing %#q got %#q", tc.ExpectedMessage, r.Message)
+			}
+		})
+	}
+}
+
+func Test_NewCertificate_InvalidConfig(t *testing.T) {
+	testCases := []struct {
+		Name  string
+		Files []string
+	}{
+		{
+			Name:  "case 0: files must be given",
+			Files: nil,
+		},
+		{
+			Name:  "case 1: files must not be empty",
+			Files: []string{"/etc/kubernetes/ssl/etcd/etcd.pem", ""},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			_, err := NewCertificate(CertificateConfig{Files: tc.Files})
+			if !IsInvalidConfig(err) {
+				t.Fatalf("expected invalid config error got %#v", err)
+			}
+		})
+	}
+}
+
+func writeCertificate(t *testing.T, dir, commonName string, notBefore, notAfter time.Time) string {
+	t.Helper()
+
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &k.PublicKey, k)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	file := filepath.Join(dir, commonName+".pem")
+	err = ioutil.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	return file
+}
@@ -0,0 +1,4 @@
+// Package healthcheck implements the dependency checks of the readiness
+// endpoint. Every check implements the healthz service of microendpoint and
+// never returns an error. Failures are reported in the response instead.
+package healthcheck
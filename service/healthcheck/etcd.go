@@ -0,0 +1,66 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/microendpoint/service/healthz"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/flannel-operator/service/controller/v3/etcd"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
+)
+
+const (
+	// EtcdDescription describes the etcd health check.
+	EtcdDescription = "Ensure the etcd cluster holding the flannel network state is reachable."
+	// EtcdName identifies the etcd health check.
+	EtcdName = "etcd"
+)
+
+type EtcdConfig struct {
+	Store etcd.Store
+}
+
+// Etcd checks the operator wide etcd cluster. Tenant clusters using their own
+// etcd cluster are not covered.
+type Etcd struct {
+	store etcd.Store
+}
+
+func NewEtcd(config EtcdConfig) (*Etcd, error) {
+	if config.Store == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Store must not be empty", config)
+	}
+
+	e := &Etcd{
+		store: config.Store,
+	}
+
+	return e, nil
+}
+
+// GetHealthz implements healthz.Service. The flannel prefix is looked up with
+// a quorum read, so that a member which lost the quorum is not reported as
+// healthy.
+func (e *Etcd) GetHealthz(ctx context.Context) (healthz.Response, error) {
+	r := healthz.Response{
+		Description: EtcdDescription,
+		Name:        EtcdName,
+	}
+
+	exists, err := e.store.Exists(ctx, key.EtcdNetworksPath)
+	if err != nil {
+		r.Failed = true
+		r.Message = fmt.Sprintf("failed to look up %#q: %s", key.EtcdNetworksPath, err)
+		return r, nil
+	}
+
+	if exists {
+		r.Message = fmt.Sprintf("found %#q", key.EtcdNetworksPath)
+	} else {
+		r.Message = fmt.Sprintf("%#q does not exist yet", key.EtcdNetworksPath)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,54 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	etcdfake "github.com/giantswarm/flannel-operator/service/controller/v3/etcd/fake"
+)
+
+type failingStore struct {
+	*etcdfake.Fake
+}
+
+func (s *failingStore) Exists(ctx context.Context, key string) (bool, error) {
+	return false, errors.New("etcdserver: request timed out")
+}
+
+func Test_Etcd_GetHealthz(t *testing.T) {
+	testCases := []struct {
+		Name           string
+		Config         EtcdConfig
+		ExpectedFailed bool
+	}{
+		{
+			Name:           "case 0: reachable etcd clusters are healthy",
+			Config:         EtcdConfig{Store: etcdfake.New()},
+			ExpectedFailed: false,
+		},
+		{
+			Name:           "case 1: unreachable etcd clusters are unhealthy",
+			Config:         EtcdConfig{Store: &failingStore{Fake: etcdfake.New()}},
+			ExpectedFailed: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			e, err := NewEtcd(tc.Config)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			r, err := e.GetHealthz(context.Background())
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			if r.Failed != tc.ExpectedFailed {
+				t.Fatalf("expected failed %t got %t: %s", tc.ExpectedFailed, r.Failed, r.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package healthcheck
+
+import (
+	"context"
+
+	"github.com/giantswarm/microendpoint/service/healthz"
+	"github.com/giantswarm/microerror"
+)
+
+const (
+	// InformerDescription describes the informer health check.
+	InformerDescription = "Ensure the controller booted and a separate informer listing the FlannelConfigs the controller watches synced."
+	// InformerName identifies the informer health check.
+	InformerName = "informer"
+)
+
+// Leader is implemented by the leader elector.
+type Leader interface {
+	IsLeader() bool
+}
+
+// Syncer is implemented by the network controller. operatorkit does not expose
+// the sync state of the controller cache. The network controller runs a second
+// informer with the same selector instead, which syncs independently of the
+// controller cache. Its sync state only approximates the one of the controller.
+type Syncer interface {
+	HasSynced() bool
+}
+
+type InformerConfig struct {
+	Leader Leader
+	Syncer Syncer
+}
+
+// Informer checks the sync state of the controller. Only the leader runs the
+// controller, standby replicas are healthy as long as they stand by. The sync
+// state is approximated, see Syncer.
+type Informer struct {
+	leader Leader
+	syncer Syncer
+}
+
+func NewInformer(config InformerConfig) (*Informer, error) {
+	if config.Leader == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Leader must not be empty", config)
+	}
+	if config.Syncer == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Syncer must not be empty", config)
+	}
+
+	i := &Informer{
+		leader: config.Leader,
+		syncer: config.Syncer,
+	}
+
+	return i, nil
+}
+
+// GetHealthz implements healthz.Service.
+func (i *Informer) GetHealthz(ctx context.Context) (healthz.Response, error) {
+	r := healthz.Response{
+		Description: InformerDescription,
+		Name:        InformerName,
+	}
+
+	switch {
+	case !i.leader.IsLeader():
+		r.Message = "not running the controller while standing by"
+	case !i.syncer.HasSynced():
+		r.Failed = true
+		r.Message = "FlannelConfigs are not synced yet"
+	default:
+		r.Message = "FlannelConfigs are synced"
+	}
+
+	return r, nil
+}
@@ -0,0 +1,65 @@
+package healthcheck
+
+import (
+	"context"
+	"testing"
+)
+
+type testLeader bool
+
+func (l testLeader) IsLeader() bool { return bool(l) }
+
+type testSyncer bool
+
+func (s testSyncer) HasSynced() bool { return bool(s) }
+
+func Test_Informer_GetHealthz(t *testing.T) {
+	testCases := []struct {
+		Name           string
+		Leader         bool
+		Synced         bool
+		ExpectedFailed bool
+	}{
+		{
+			Name:           "case 0: standby replicas are healthy",
+			Leader:         false,
+			Synced:         false,
+			ExpectedFailed: false,
+		},
+		{
+			Name:           "case 1: leaders which did not sync yet are unhealthy",
+			Leader:         true,
+			Synced:         false,
+			ExpectedFailed: true,
+		},
+		{
+			Name:           "case 2: leaders which synced are healthy",
+			Leader:         true,
+			Synced:         true,
+			ExpectedFailed: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			c := InformerConfig{
+				Leader: testLeader(tc.Leader),
+				Syncer: testSyncer(tc.Synced),
+			}
+
+			i, err := NewInformer(c)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			r, err := i.GetHealthz(context.Background())
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			if r.Failed != tc.ExpectedFailed {
+				t.Fatalf("expected failed %t got %t: %s", tc.ExpectedFailed, r.Failed, r.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/microendpoint/service/healthz"
+	"github.com/giantswarm/microerror"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// KubernetesDescription describes the Kubernetes health check.
+	KubernetesDescription = "Ensure the Kubernetes API is reachable."
+	// KubernetesName identifies the Kubernetes health check.
+	KubernetesName = "kubernetes"
+)
+
+type KubernetesConfig struct {
+	K8sClient kubernetes.Interface
+}
+
+type Kubernetes struct {
+	k8sClient kubernetes.Interface
+}
+
+func NewKubernetes(config KubernetesConfig) (*Kubernetes, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+
+	k := &Kubernetes{
+		k8sClient: config.K8sClient,
+	}
+
+	return k, nil
+}
+
+// GetHealthz implements healthz.Service. The server version is requested,
+// which every authenticated client is allowed to read.
+func (k *Kubernetes) GetHealthz(ctx context.Context) (healthz.Response, error) {
+	r := healthz.Response{
+		Description: KubernetesDescription,
+		Name:        KubernetesName,
+	}
+
+	v, err := k.k8sClient.Discovery().ServerVersion()
+	if err != nil {
+		r.Failed = true
+		r.Message = fmt.Sprintf("failed to reach the Kubernetes API: %s", err)
+		return r, nil
+	}
+
+	r.Message = fmt.Sprintf("Kubernetes API %s is reachable", v.GitVersion)
+
+	return r, nil
+}
@@ -0,0 +1,224 @@
+// Package leaderelection makes sure only one of several operator replicas
+// reconciles tenant clusters at a time. Replicas compete for a Lease in the
+// Kubernetes API. The replica holding the Lease runs the controller and the
+// background workers, all others stand by and take over once the Lease
+// expires. A replica which loses its Lease exits, because the controller
+// cannot be stopped once it got booted.
+package leaderelection
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microendpoint/service/healthz"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+const (
+	// Description describes the healthz check of the leader election.
+	Description = "Ensure the leader election is not lost."
+	// Name identifies the healthz check of the leader election.
+	Name = "leaderelection"
+)
+
+type Config struct {
+	K8sClient kubernetes.Interface
+	Logger    micrologger.Logger
+
+	// Enabled enables the leader election. The elector considers itself the
+	// leader right away otherwise.
+	Enabled bool
+	// Identity identifies the replica holding the Lease, usually the pod name.
+	Identity string
+	// LeaseDuration is the time standby replicas wait before they take over
+	// a Lease which was not renewed.
+	LeaseDuration time.Duration
+	// LeaseName and Namespace locate the Lease replicas compete for.
+	LeaseName string
+	Namespace string
+	// RenewDeadline is the time the leader retries renewing the Lease before
+	// it gives up leadership.
+	RenewDeadline time.Duration
+	// RetryPeriod is the time between two attempts to acquire or renew the
+	// Lease.
+	RetryPeriod time.Duration
+}
+
+type Elector struct {
+	logger micrologger.Logger
+
+	enabled  bool
+	identity string
+	elector  *leaderelection.LeaderElector
+
+	// run is executed once leadership got acquired.
+	run func(ctx context.Context)
+
+	mutex   sync.Mutex
+	leading bool
+	lost    bool
+	exit    func()
+}
+
+func New(config Config) (*Elector, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	e := &Elector{
+		logger: config.Logger,
+
+		enabled:  config.Enabled,
+		identity: config.Identity,
+
+		exit: func() { os.Exit(1) },
+	}
+
+	if !config.Enabled {
+		return e, nil
+	}
+
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Identity == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Identity must not be empty", config)
+	}
+	if config.LeaseName == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.LeaseName must not be empty", config)
+	}
+	if config.Namespace == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Namespace must not be empty", config)
+	}
+
+	var err error
+
+	{
+		lock := &resourcelock.LeaseLock{
+			LeaseMeta: metav1.ObjectMeta{
+				Name:      config.LeaseName,
+				Namespace: config.Namespace,
+			},
+			Client: config.K8sClient.CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{
+				Identity: config.Identity,
+			},
+		}
+
+		c := leaderelection.LeaderElectionConfig{
+			Lock:          lock,
+			LeaseDuration: config.LeaseDuration,
+			RenewDeadline: config.RenewDeadline,
+			RetryPeriod:   config.RetryPeriod,
+			Callbacks: leaderelection.LeaderCallbacks{
+				OnStartedLeading: e.onStartedLeading,
+				OnStoppedLeading: e.onStoppedLeading,
+				OnNewLeader:      e.onNewLeader,
+			},
+			Name: config.LeaseName,
+		}
+
+		e.elector, err = leaderelection.NewLeaderElector(c)
+		if err != nil {
+			return nil, microerror.Maskf(invalidConfigError, "%s", err)
+		}
+	}
+
+	return e, nil
+}
+
+// Boot executes the given function once this replica becomes the leader. It
+// blocks until leadership is lost or the given context is done. In case the
+// leader election is disabled the given function is executed right away.
+func (e *Elector) Boot(ctx context.Context, run func(ctx context.Context)) {
+	e.run = run
+
+	if !e.enabled {
+		e.onStartedLeading(ctx)
+		return
+	}
+
+	e.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("standing by until %#q acquires the leader lease", e.identity))
+
+	e.elector.Run(ctx)
+}
+
+// GetHealthz implements healthz.Service. Standing by is healthy, only a lost
+// leader lease is reported as failure.
+func (e *Elector) GetHealthz(ctx context.Context) (healthz.Response, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	r := healthz.Response{
+		Description: Description,
+		Name:        Name,
+	}
+
+	switch {
+	case e.lost:
+		r.Failed = true
+		r.Message = fmt.Sprintf("%#q lost the leader lease", e.identity)
+	case e.leading:
+		r.Message = fmt.Sprintf("%#q is the leader", e.identity)
+	default:
+		r.Message = fmt.Sprintf("%#q is standing by", e.identity)
+	}
+
+	return r, nil
+}
+
+// IsLeader returns whether this replica currently reconciles tenant clusters.
+func (e *Elector) IsLeader() bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return e.leading
+}
+
+func (e *Elector) onNewLeader(identity string) {
+	if identity == e.identity {
+		return
+	}
+
+	e.logger.Log("level", "debug", "message", fmt.Sprintf("%#q holds the leader lease", identity))
+}
+
+func (e *Elector) onStartedLeading(ctx context.Context) {
+	e.mutex.Lock()
+	e.leading = true
+	e.mutex.Unlock()
+
+	isLeaderGauge.Set(1)
+
+	e.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("%#q acquired the leader lease", e.identity))
+
+	e.run(ctx)
+}
+
+func (e *Elector) onStoppedLeading() {
+	e.mutex.Lock()
+	wasLeading := e.leading
+	e.leading = false
+	e.lost = wasLeading
+	e.mutex.Unlock()
+
+	isLeaderGauge.Set(0)
+
+	if !wasLeading {
+		return
+	}
+
+	// The controller and the background workers cannot be stopped once they got
+	// booted. Exiting is the only way to make sure the replica which takes over
+	// does not race with them.
+	e.logger.Log("level", "error", "message", fmt.Sprintf("%#q lost the leader lease", e.identity))
+	e.exit()
+}
@@ -0,0 +1,159 @@
+package leaderelection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestConfig(k8sClient *fake.Clientset, identity string) Config {
+	return Config{
+		K8sClient: k8sClient,
+		Logger:    microloggertest.New(),
+
+		Enabled:       true,
+		Identity:      identity,
+		LeaseDuration: 2 * time.Second,
+		LeaseName:     "flannel-operator",
+		Namespace:     "giantswarm",
+		RenewDeadline: time.Second,
+		RetryPeriod:   100 * time.Millisecond,
+	}
+}
+
+func Test_Elector_Boot(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	k8sClient := fake.NewSimpleClientset()
+
+	leader, err := New(newTestConfig(k8sClient, "flannel-operator-1"))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	leader.exit = func() {}
+
+	started := make(chan struct{})
+	go leader.Boot(ctx, func(ctx context.Context) { close(started) })
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected leader to acquire the lease")
+	}
+
+	standby, err := New(newTestConfig(k8sClient, "flannel-operator-2"))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	standby.exit = func() {}
+
+	go standby.Boot(ctx, func(ctx context.Context) { t.Error("expected standby not to acquire the lease") })
+
+	// Give the standby replica a few attempts to acquire the lease.
+	time.Sleep(500 * time.Millisecond)
+
+	if !leader.IsLeader() {
+		t.Fatalf("expected %#q to be the leader", "flannel-operator-1")
+	}
+	if standby.IsLeader() {
+		t.Fatalf("expected %#q not to be the leader", "flannel-operator-2")
+	}
+
+	lease, err := k8sClient.CoordinationV1().Leases("giantswarm").Get("flannel-operator", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if *lease.Spec.HolderIdentity != "flannel-operator-1" {
+		t.Fatalf("expected lease holder %#q got %#q", "flannel-operator-1", *lease.Spec.HolderIdentity)
+	}
+
+	for _, e := range []*Elector{leader, standby} {
+		r, err := e.GetHealthz(ctx)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		if r.Failed {
+			t.Fatalf("expected healthz of %#q not to fail got %#q", e.identity, r.Message)
+		}
+	}
+}
+
+func Test_Elector_Boot_Disabled(t *testing.T) {
+	c := Config{
+		Logger: microloggertest.New(),
+
+		Enabled: false,
+	}
+
+	e, err := New(c)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	var started bool
+	e.Boot(context.Background(), func(ctx context.Context) { started = true })
+
+	if !started {
+		t.Fatal("expected run to be executed right away")
+	}
+	if !e.IsLeader() {
+		t.Fatal("expected elector to be the leader")
+	}
+	if testutil.ToFloat64(isLeaderGauge) != 1 {
+		t.Fatalf("expected is leader gauge %v got %v", 1, testutil.ToFloat64(isLeaderGauge))
+	}
+}
+
+func Test_Elector_LostLease(t *testing.T) {
+	c := Config{
+		Logger: microloggertest.New(),
+
+		Enabled:  false,
+		Identity: "flannel-operator-1",
+	}
+
+	e, err := New(c)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	var exited bool
+	e.exit = func() { exited = true }
+
+	e.Boot(context.Background(), func(ctx context.Context) {})
+	e.onStoppedLeading()
+
+	if !exited {
+		t.Fatal("expected elector to exit after losing the lease")
+	}
+	if e.IsLeader() {
+		t.Fatal("expected elector not to be the leader")
+	}
+	if testutil.ToFloat64(isLeaderGauge) != 0 {
+		t.Fatalf("expected is leader gauge %v got %v", 0, testutil.ToFloat64(isLeaderGauge))
+	}
+
+	r, err := e.GetHealthz(context.Background())
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if !r.Failed {
+		t.Fatal("expected healthz to fail after losing the lease")
+	}
+}
+
+func Test_Elector_New_InvalidDurations(t *testing.T) {
+	c := newTestConfig(fake.NewSimpleClientset(), "flannel-operator-1")
+	c.RenewDeadline = c.LeaseDuration
+
+	_, err := New(c)
+	if !IsInvalidConfig(err) {
+		t.Fatalf("expected invalid config error got %#v", err)
+	}
+}
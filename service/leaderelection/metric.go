@@ -0,0 +1,23 @@
+package leaderelection
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	PrometheusNamespace = "flannel_operator"
+	PrometheusSubsystem = "leader_election"
+)
+
+var isLeaderGauge = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: PrometheusNamespace,
+		Subsystem: PrometheusSubsystem,
+		Name:      "is_leader",
+		Help:      "Whether this operator replica currently holds the leader lease and reconciles tenant clusters.",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(isLeaderGauge)
+}
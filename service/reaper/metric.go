@@ -0,0 +1,35 @@
+package reaper
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	PrometheusNamespace = "flannel_operator"
+	PrometheusSubsystem = "reaper"
+)
+
+var orphansGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: PrometheusNamespace,
+		Subsystem: PrometheusSubsystem,
+		Name:      "orphans",
+		Help:      "Number of network artifacts without matching FlannelConfig found by the last inventory, labeled by kind.",
+	},
+	[]string{"kind"},
+)
+
+var reapedCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: PrometheusNamespace,
+		Subsystem: PrometheusSubsystem,
+		Name:      "reaped_total",
+		Help:      "Number of orphaned network artifacts deleted, labeled by kind.",
+	},
+	[]string{"kind"},
+)
+
+func init() {
+	prometheus.MustRegister(orphansGauge)
+	prometheus.MustRegister(reapedCounter)
+}
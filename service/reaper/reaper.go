@@ -0,0 +1,394 @@
+// Package reaper detects network artifacts left behind by interrupted
+// deletions of FlannelConfigs. Artifacts are identified by their ownership
+// labels, see the ownership package, as well as by the naming of the key
+// package. The naming covers objects created before ownership labels were
+// introduced and the network state in etcd. Orphans are always reported as
+// metrics. Deleting them is optional and only happens once they have been
+// orphaned for the configured grace age.
+package reaper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/giantswarm/flannel-operator/pkg/ownership"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/etcd"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/key"
+)
+
+// The kinds of network artifacts the reaper inventories.
+const (
+	kindClusterRoleBinding = "clusterrolebinding"
+	kindEtcdNetwork        = "etcdnetwork"
+	kindNamespace          = "namespace"
+)
+
+var kinds = []string{
+	kindClusterRoleBinding,
+	kindEtcdNetwork,
+	kindNamespace,
+}
+
+type Config struct {
+	G8sClient versioned.Interface
+	K8sClient kubernetes.Interface
+	Logger    micrologger.Logger
+	Store     etcd.Store
+
+	// CleanupEnabled enables the deletion of orphans. Orphans are only
+	// reported otherwise.
+	CleanupEnabled bool
+	// GraceAge is the minimum time an artifact has to be orphaned before it
+	// gets deleted.
+	GraceAge time.Duration
+	// Interval is the time between two inventories.
+	Interval time.Duration
+}
+
+type Reaper struct {
+	g8sClient versioned.Interface
+	k8sClient kubernetes.Interface
+	logger    micrologger.Logger
+	store     etcd.Store
+
+	cleanupEnabled bool
+	graceAge       time.Duration
+	interval       time.Duration
+
+	// orphanedSince holds the time every known orphan was found first. It is
+	// kept in memory, which means the grace age starts over when the operator
+	// restarts.
+	orphanedSince map[artifact]time.Time
+	now           func() time.Time
+}
+
+// artifact is a single network artifact of a tenant cluster. The cluster ID
+// is empty for managed objects which do not follow the naming.
+type artifact struct {
+	Kind      string
+	Name      string
+	ClusterID string
+}
+
+// state is what the inventory knows about an artifact.
+type state struct {
+	// Created is the creation time of the artifact, which is zero for the
+	// network state in etcd.
+	Created time.Time
+	// OwnerUID is the UID of the FlannelConfig owning the artifact according
+	// to its ownership labels. It is empty for artifacts without them.
+	OwnerUID types.UID
+	// Terminating is set for artifacts which are being deleted already.
+	Terminating bool
+}
+
+func New(config Config) (*Reaper, error) {
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.Store == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Store must not be empty", config)
+	}
+
+	if config.GraceAge < 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GraceAge must not be negative", config)
+	}
+	if config.Interval <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Interval must be greater than 0", config)
+	}
+
+	r := &Reaper{
+		g8sClient: config.G8sClient,
+		k8sClient: config.K8sClient,
+		logger:    config.Logger,
+		store:     config.Store,
+
+		cleanupEnabled: config.CleanupEnabled,
+		graceAge:       config.GraceAge,
+		interval:       config.Interval,
+
+		orphanedSince: map[artifact]time.Time{},
+		now:           time.Now,
+	}
+
+	return r, nil
+}
+
+// Boot reaps orphaned network artifacts periodically until the given context
+// is done.
+func (r *Reaper) Boot(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		err := r.Reap(ctx)
+		if err != nil {
+			r.logger.LogCtx(ctx, "level", "error", "message", "failed to reap orphaned network artifacts", "stack", fmt.Sprintf("%#v", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reap inventories all network artifacts, reports the ones without matching
+// FlannelConfig and deletes them in case the cleanup is enabled and they have
+// been orphaned for the grace age.
+func (r *Reaper) Reap(ctx context.Context) error {
+	r.logger.LogCtx(ctx, "level", "debug", "message", "reaping orphaned network artifacts")
+
+	// The artifacts are listed before the FlannelConfigs. This way the
+	// FlannelConfig of every artifact we see was created before the
+	// FlannelConfigs are listed, so that artifacts of new FlannelConfigs are
+	// never considered orphans.
+	artifacts, err := r.inventory(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	clusterIDs := map[string]bool{}
+	owners := map[types.UID]bool{}
+	{
+		list, err := r.g8sClient.CoreV1alpha1().FlannelConfigs(metav1.NamespaceAll).List(metav1.ListOptions{})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, c := range list.Items {
+			clusterIDs[key.ClusterID(c)] = true
+			owners[c.GetUID()] = true
+		}
+	}
+
+	// Artifacts carrying ownership labels are orphaned once their owner is
+	// gone, even if a FlannelConfig of the same cluster ID got created again.
+	// The owner labels of such artifacts are never updated, since they are
+	// foreign to the new FlannelConfig. All other artifacts are orphaned once
+	// no FlannelConfig of their cluster ID exists.
+	isOrphan := func(a artifact, st state) bool {
+		if st.OwnerUID != "" {
+			return !owners[st.OwnerUID]
+		}
+
+		return a.ClusterID != "" && !clusterIDs[a.ClusterID]
+	}
+
+	now := r.now()
+
+	orphans := map[artifact]time.Time{}
+	terminating := map[artifact]bool{}
+	for a, st := range artifacts {
+		if !isOrphan(a, st) {
+			continue
+		}
+		terminating[a] = st.Terminating
+
+		since, ok := r.orphanedSince[a]
+		if !ok {
+			since = now
+		}
+		// Artifacts recreated after they were found first are considered
+		// orphaned since their recreation.
+		if st.Created.After(since) {
+			since = st.Created
+		}
+		orphans[a] = since
+	}
+	r.orphanedSince = orphans
+
+	counts := map[string]int{}
+	for a := range orphans {
+		counts[a.Kind]++
+	}
+	for _, k := range kinds {
+		orphansGauge.WithLabelValues(k).Set(float64(counts[k]))
+	}
+
+	for a, since := range orphans {
+		r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("found orphaned %s %#q of cluster %#q", a.Kind, a.Name, a.ClusterID), "orphanedSince", since.Format(time.RFC3339))
+
+		if !r.cleanupEnabled {
+			continue
+		}
+		if terminating[a] {
+			continue
+		}
+		if now.Sub(since) < r.graceAge {
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("not deleting orphaned %s %#q before grace age %s", a.Kind, a.Name, r.graceAge))
+			continue
+		}
+
+		err := r.delete(ctx, a)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		reapedCounter.WithLabelValues(a.Kind).Inc()
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", "reaped orphaned network artifacts")
+
+	return nil
+}
+
+// inventory returns all network artifacts of tenant clusters. Namespaces and
+// cluster role bindings are artifacts in case they follow the naming or carry
+// the ownership labels of the operator.
+func (r *Reaper) inventory(ctx context.Context) (map[artifact]state, error) {
+	artifacts := map[artifact]state{}
+
+	{
+		list, err := r.k8sClient.CoreV1().Namespaces().List(metav1.ListOptions{})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		for _, n := range list.Items {
+			id := clusterIDFromNamespace(n.GetName())
+			if id == "" && !isOwned(&n) {
+				continue
+			}
+
+			a := artifact{Kind: kindNamespace, Name: n.GetName(), ClusterID: id}
+			artifacts[a] = state{
+				Created:     n.GetCreationTimestamp().Time,
+				OwnerUID:    ownerUID(&n),
+				Terminating: n.Status.Phase == corev1.NamespaceTerminating,
+			}
+		}
+	}
+
+	{
+		list, err := r.k8sClient.RbacV1().ClusterRoleBindings().List(metav1.ListOptions{})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		for _, b := range list.Items {
+			id := clusterIDFromClusterRoleBinding(b)
+			if id == "" && !isOwned(&b) {
+				continue
+			}
+
+			a := artifact{Kind: kindClusterRoleBinding, Name: b.GetName(), ClusterID: id}
+			artifacts[a] = state{
+				Created:     b.GetCreationTimestamp().Time,
+				OwnerUID:    ownerUID(&b),
+				Terminating: b.GetDeletionTimestamp() != nil,
+			}
+		}
+	}
+
+	{
+		directories, err := r.store.ListDirectories(ctx, key.EtcdNetworksPath)
+		if etcd.IsNotFound(err) {
+			// fall through
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		for _, d := range directories {
+			if !strings.HasPrefix(d, key.NetworkBridgePrefix) {
+				continue
+			}
+
+			a := artifact{Kind: kindEtcdNetwork, Name: key.EtcdNetworksPath + "/" + d, ClusterID: strings.TrimPrefix(d, key.NetworkBridgePrefix)}
+			artifacts[a] = state{}
+		}
+	}
+
+	return artifacts, nil
+}
+
+func (r *Reaper) delete(ctx context.Context, a artifact) error {
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("deleting orphaned %s %#q", a.Kind, a.Name))
+
+	var err error
+	switch a.Kind {
+	case kindClusterRoleBinding:
+		err = r.k8sClient.RbacV1().ClusterRoleBindings().Delete(a.Name, &metav1.DeleteOptions{})
+	case kindEtcdNetwork:
+		err = r.store.Delete(ctx, a.Name)
+	case kindNamespace:
+		err = r.k8sClient.CoreV1().Namespaces().Delete(a.Name, &metav1.DeleteOptions{})
+	}
+	if apierrors.IsNotFound(err) || etcd.IsNotFound(err) {
+		// fall through
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("deleted orphaned %s %#q", a.Kind, a.Name))
+
+	return nil
+}
+
+// isOwned returns whether the given object is managed by the operator on
+// behalf of a FlannelConfig.
+func isOwned(obj metav1.Object) bool {
+	return ownerUID(obj) != ""
+}
+
+// ownerUID returns the UID of the FlannelConfig owning the given object. It is
+// empty for objects not managed by the operator.
+func ownerUID(obj metav1.Object) types.UID {
+	if !ownership.IsManaged(obj) {
+		return ""
+	}
+
+	return ownership.OwnerUID(obj)
+}
+
+// clusterIDFromNamespace returns the cluster ID of the given network or
+// destroyer namespace, see key.NetworkNamespace and key.DestroyerNamespace. It
+// returns an empty string for any other namespace.
+func clusterIDFromNamespace(name string) string {
+	for _, prefix := range []string{key.NetworkID + "-", key.DestroyerID + "-"} {
+		if strings.HasPrefix(name, prefix) {
+			return strings.TrimPrefix(name, prefix)
+		}
+	}
+
+	return ""
+}
+
+// clusterIDFromClusterRoleBinding returns the cluster ID of the given per
+// cluster cluster role binding. Not all of their names carry a common prefix,
+// which is why they are identified by their subjects, which are service
+// accounts in the network or destroyer namespace of a tenant cluster. It
+// returns an empty string for any other cluster role binding.
+func clusterIDFromClusterRoleBinding(b rbacv1.ClusterRoleBinding) string {
+	for _, s := range b.Subjects {
+		if s.Kind != rbacv1.ServiceAccountKind {
+			continue
+		}
+
+		id := clusterIDFromNamespace(s.Namespace)
+		if id != "" {
+			return id
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,424 @@
+package reaper
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/flannel-operator/pkg/ownership"
+	etcdfake "github.com/giantswarm/flannel-operator/service/controller/v3/etcd/fake"
+)
+
+// testStore is an etcd store holding the given network directories. Deleted
+// directories are removed.
+type testStore struct {
+	etcdfake.Fake
+
+	directories []string
+}
+
+func (s *testStore) Delete(ctx context.Context, key string) error {
+	var directories []string
+	for _, d := range s.directories {
+		if "coreos.com/network/"+d != key {
+			directories = append(directories, d)
+		}
+	}
+	s.directories = directories
+
+	return nil
+}
+
+func (s *testStore) ListDirectories(ctx context.Context, key string) ([]string, error) {
+	return s.directories, nil
+}
+
+func newCustomObject(id string) *v1alpha1.FlannelConfig {
+	return &v1alpha1.FlannelConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      id,
+			Namespace: "default",
+			UID:       types.UID(id + "-uid"),
+		},
+		Spec: v1alpha1.FlannelConfigSpec{
+			Cluster: v1alpha1.FlannelConfigSpecCluster{
+				ID: id,
+			},
+		},
+	}
+}
+
+func newNamespace(name string, phase corev1.NamespacePhase) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Status: corev1.NamespaceStatus{
+			Phase: phase,
+		},
+	}
+}
+
+func newClusterRoleBinding(name, subjectNamespace string) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Namespace: subjectNamespace,
+				Name:      "flannel-network",
+			},
+		},
+	}
+}
+
+// withLabels sets the given labels on the given object.
+func withLabels(obj metav1.Object, labels map[string]string) runtime.Object {
+	obj.SetLabels(labels)
+	return obj.(runtime.Object)
+}
+
+func Test_Reaper_Reap(t *testing.T) {
+	existing := newCustomObject("al9qy")
+	deleted := newCustomObject("xa5ly")
+	recreated := newCustomObject("al9qy")
+	recreated.SetUID("al9qy-recreated-uid")
+
+	testCases := []struct {
+		Name                        string
+		FlannelConfigs              []runtime.Object
+		Objects                     []runtime.Object
+		Directories                 []string
+		CleanupEnabled              bool
+		ExpectedNamespaces          []string
+		ExpectedClusterRoleBindings []string
+		ExpectedDirectories         []string
+		ExpectedOrphans             map[string]float64
+	}{
+		{
+			Name: "case 0: artifacts of existing FlannelConfigs are kept",
+			FlannelConfigs: []runtime.Object{
+				newCustomObject("al9qy"),
+			},
+			Objects: []runtime.Object{
+				newNamespace("flannel-network-al9qy", corev1.NamespaceActive),
+				newClusterRoleBinding("flannel-network-al9qy", "flannel-network-al9qy"),
+				newClusterRoleBinding("al9qy-deletion", "flannel-destroyer-al9qy"),
+			},
+			Directories:    []string{"br-al9qy"},
+			CleanupEnabled: true,
+			ExpectedNamespaces: []string{
+				"flannel-network-al9qy",
+			},
+			ExpectedClusterRoleBindings: []string{
+				"al9qy-deletion",
+				"flannel-network-al9qy",
+			},
+			ExpectedDirectories: []string{"br-al9qy"},
+			ExpectedOrphans: map[string]float64{
+				kindClusterRoleBinding: 0,
+				kindEtcdNetwork:        0,
+				kindNamespace:          0,
+			},
+		},
+		{
+			Name: "case 1: orphans are only reported in case the cleanup is disabled",
+			Objects: []runtime.Object{
+				newNamespace("flannel-network-xa5ly", corev1.NamespaceActive),
+				newNamespace("flannel-destroyer-xa5ly", corev1.NamespaceActive),
+				newClusterRoleBinding("xa5ly-deletion", "flannel-destroyer-xa5ly"),
+			},
+			Directories:    []string{"br-xa5ly"},
+			CleanupEnabled: false,
+			ExpectedNamespaces: []string{
+				"flannel-destroyer-xa5ly",
+				"flannel-network-xa5ly",
+			},
+			ExpectedClusterRoleBindings: []string{
+				"xa5ly-deletion",
+			},
+			ExpectedDirectories: []string{"br-xa5ly"},
+			ExpectedOrphans: map[string]float64{
+				kindClusterRoleBinding: 1,
+				kindEtcdNetwork:        1,
+				kindNamespace:          2,
+			},
+		},
+		{
+			Name: "case 2: orphans are deleted in case the cleanup is enabled",
+			FlannelConfigs: []runtime.Object{
+				newCustomObject("al9qy"),
+			},
+			Objects: []runtime.Object{
+				newNamespace("flannel-network-al9qy", corev1.NamespaceActive),
+				newNamespace("flannel-network-xa5ly", corev1.NamespaceActive),
+				newClusterRoleBinding("flannel-network-xa5ly-psp", "flannel-network-xa5ly"),
+				newClusterRoleBinding("xa5ly-deletion", "flannel-destroyer-xa5ly"),
+			},
+			Directories:    []string{"br-al9qy", "br-xa5ly"},
+			CleanupEnabled: true,
+			ExpectedNamespaces: []string{
+				"flannel-network-al9qy",
+			},
+			ExpectedClusterRoleBindings: nil,
+			ExpectedDirectories:         []string{"br-al9qy"},
+			ExpectedOrphans: map[string]float64{
+				kindClusterRoleBinding: 2,
+				kindEtcdNetwork:        1,
+				kindNamespace:          1,
+			},
+		},
+		{
+			Name: "case 3: artifacts not following the naming are kept",
+			Objects: []runtime.Object{
+				newNamespace("kube-system", corev1.NamespaceActive),
+				newClusterRoleBinding("cluster-admin", "kube-system"),
+			},
+			Directories:    []string{"other"},
+			CleanupEnabled: true,
+			ExpectedNamespaces: []string{
+				"kube-system",
+			},
+			ExpectedClusterRoleBindings: []string{
+				"cluster-admin",
+			},
+			ExpectedDirectories: []string{"other"},
+			ExpectedOrphans: map[string]float64{
+				kindClusterRoleBinding: 0,
+				kindEtcdNetwork:        0,
+				kindNamespace:          0,
+			},
+		},
+		{
+			Name: "case 4: terminating namespaces are not deleted again",
+			Objects: []runtime.Object{
+				newNamespace("flannel-destroyer-xa5ly", corev1.NamespaceTerminating),
+			},
+			CleanupEnabled: true,
+			ExpectedNamespaces: []string{
+				"flannel-destroyer-xa5ly",
+			},
+			ExpectedOrphans: map[string]float64{
+				kindClusterRoleBinding: 0,
+				kindEtcdNetwork:        0,
+				kindNamespace:          1,
+			},
+		},
+		{
+			Name: "case 5: managed objects of deleted FlannelConfigs are deleted regardless of their naming",
+			FlannelConfigs: []runtime.Object{
+				existing,
+			},
+			Objects: []runtime.Object{
+				withLabels(newNamespace("flannel-network-al9qy", corev1.NamespaceActive), ownership.Labels(*existing)),
+				withLabels(newNamespace("custom-xa5ly", corev1.NamespaceActive), ownership.Labels(*deleted)),
+				withLabels(newClusterRoleBinding("custom-xa5ly", "kube-system"), ownership.Labels(*deleted)),
+			},
+			CleanupEnabled: true,
+			ExpectedNamespaces: []string{
+				"flannel-network-al9qy",
+			},
+			ExpectedClusterRoleBindings: nil,
+			ExpectedOrphans: map[string]float64{
+				kindClusterRoleBinding: 1,
+				kindEtcdNetwork:        0,
+				kindNamespace:          1,
+			},
+		},
+		{
+			Name: "case 6: managed objects are only reported in case the cleanup is disabled",
+			Objects: []runtime.Object{
+				withLabels(newNamespace("custom-xa5ly", corev1.NamespaceActive), ownership.Labels(*deleted)),
+			},
+			CleanupEnabled: false,
+			ExpectedNamespaces: []string{
+				"custom-xa5ly",
+			},
+			ExpectedOrphans: map[string]float64{
+				kindClusterRoleBinding: 0,
+				kindEtcdNetwork:        0,
+				kindNamespace:          1,
+			},
+		},
+		{
+			Name: "case 7: managed objects of a FlannelConfig created again with the same cluster ID are deleted",
+			FlannelConfigs: []runtime.Object{
+				recreated,
+			},
+			Objects: []runtime.Object{
+				withLabels(newNamespace("flannel-network-al9qy", corev1.NamespaceActive), ownership.Labels(*existing)),
+			},
+			CleanupEnabled:     true,
+			ExpectedNamespaces: nil,
+			ExpectedOrphans: map[string]float64{
+				kindClusterRoleBinding: 0,
+				kindEtcdNetwork:        0,
+				kindNamespace:          1,
+			},
+		},
+		{
+			Name: "case 8: objects managed by others are kept",
+			Objects: []runtime.Object{
+				withLabels(newNamespace("custom-xa5ly", corev1.NamespaceActive), map[string]string{
+					ownership.LabelManagedBy: "someone-else",
+					ownership.LabelOwnerUID:  "xa5ly-uid",
+				}),
+			},
+			CleanupEnabled: true,
+			ExpectedNamespaces: []string{
+				"custom-xa5ly",
+			},
+			ExpectedOrphans: map[string]float64{
+				kindClusterRoleBinding: 0,
+				kindEtcdNetwork:        0,
+				kindNamespace:          0,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			k8sClient := k8sfake.NewSimpleClientset(tc.Objects...)
+			store := &testStore{
+				directories: tc.Directories,
+			}
+
+			c := Config{
+				G8sClient: fake.NewSimpleClientset(tc.FlannelConfigs...),
+				K8sClient: k8sClient,
+				Logger:    microloggertest.New(),
+				Store:     store,
+
+				CleanupEnabled: tc.CleanupEnabled,
+				GraceAge:       0,
+				Interval:       time.Minute,
+			}
+
+			r, err := New(c)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			err = r.Reap(context.Background())
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			namespaces, clusterRoleBindings := listNames(t, k8sClient)
+			if !reflect.DeepEqual(namespaces, tc.ExpectedNamespaces) {
+				t.Fatalf("expected namespaces %v got %v", tc.ExpectedNamespaces, namespaces)
+			}
+			if !reflect.DeepEqual(clusterRoleBindings, tc.ExpectedClusterRoleBindings) {
+				t.Fatalf("expected cluster role bindings %v got %v", tc.ExpectedClusterRoleBindings, clusterRoleBindings)
+			}
+			if !reflect.DeepEqual(store.directories, tc.ExpectedDirectories) {
+				t.Fatalf("expected directories %v got %v", tc.ExpectedDirectories, store.directories)
+			}
+
+			for kind, expected := range tc.ExpectedOrphans {
+				orphans := testutil.ToFloat64(orphansGauge.WithLabelValues(kind))
+				if orphans != expected {
+					t.Fatalf("expected %v orphans of kind %#q got %v", expected, kind, orphans)
+				}
+			}
+		})
+	}
+}
+
+func Test_Reaper_Reap_GraceAge(t *testing.T) {
+	k8sClient := k8sfake.NewSimpleClientset(
+		newNamespace("flannel-network-xa5ly", corev1.NamespaceActive),
+	)
+
+	c := Config{
+		G8sClient: fake.NewSimpleClientset(),
+		K8sClient: k8sClient,
+		Logger:    microloggertest.New(),
+		Store:     &testStore{},
+
+		CleanupEnabled: true,
+		GraceAge:       time.Hour,
+		Interval:       time.Minute,
+	}
+
+	r, err := New(c)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+
+	for i, tc := range []struct {
+		Elapsed            time.Duration
+		ExpectedNamespaces []string
+	}{
+		{
+			Elapsed:            0,
+			ExpectedNamespaces: []string{"flannel-network-xa5ly"},
+		},
+		{
+			Elapsed:            30 * time.Minute,
+			ExpectedNamespaces: []string{"flannel-network-xa5ly"},
+		},
+		{
+			Elapsed:            30 * time.Minute,
+			ExpectedNamespaces: nil,
+		},
+	} {
+		now = now.Add(tc.Elapsed)
+
+		err = r.Reap(context.Background())
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		namespaces, _ := listNames(t, k8sClient)
+		if !reflect.DeepEqual(namespaces, tc.ExpectedNamespaces) {
+			t.Fatalf("run %d: expected namespaces %v got %v", i, tc.ExpectedNamespaces, namespaces)
+		}
+	}
+}
+
+func listNames(t *testing.T, k8sClient *k8sfake.Clientset) ([]string, []string) {
+	var namespaces []string
+	{
+		list, err := k8sClient.CoreV1().Namespaces().List(metav1.ListOptions{})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		for _, n := range list.Items {
+			namespaces = append(namespaces, n.GetName())
+		}
+		sort.Strings(namespaces)
+	}
+
+	var clusterRoleBindings []string
+	{
+		list, err := k8sClient.RbacV1().ClusterRoleBindings().List(metav1.ListOptions{})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		for _, b := range list.Items {
+			clusterRoleBindings = append(clusterRoleBindings, b.GetName())
+		}
+		sort.Strings(clusterRoleBindings)
+	}
+
+	return namespaces, clusterRoleBindings
+}
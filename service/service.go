@@ -4,11 +4,14 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"sync"
 
 	corev1alpha1 "github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
 	"github.com/giantswarm/k8sclient"
 	"github.com/giantswarm/k8sclient/k8srestconfig"
+	healthzservice "github.com/giantswarm/microendpoint/service/healthz"
 	"github.com/giantswarm/microendpoint/service/version"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
@@ -17,7 +20,13 @@ import (
 
 	"github.com/giantswarm/flannel-operator/flag"
 	"github.com/giantswarm/flannel-operator/pkg/project"
+	"github.com/giantswarm/flannel-operator/pkg/shard"
 	"github.com/giantswarm/flannel-operator/service/controller"
+	"github.com/giantswarm/flannel-operator/service/controller/v3/etcd"
+	"github.com/giantswarm/flannel-operator/service/conversion"
+	"github.com/giantswarm/flannel-operator/service/healthcheck"
+	"github.com/giantswarm/flannel-operator/service/leaderelection"
+	"github.com/giantswarm/flannel-operator/service/reaper"
 )
 
 // Config represents the configuration used to create a new service.
@@ -30,10 +39,20 @@ type Config struct {
 }
 
 type Service struct {
-	Version *version.Service
+	LeaderElector *leaderelection.Elector
+	Version       *version.Service
+
+	// LivenessChecks only cover the process itself. Restarting the operator
+	// does not help against unavailable dependencies.
+	LivenessChecks []healthzservice.Service
+	// ReadinessChecks cover the dependencies the operator needs to reconcile
+	// tenant clusters.
+	ReadinessChecks []healthzservice.Service
 
 	bootOnce          sync.Once
+	conversionWebhook *conversion.Webhook
 	networkController *controller.Network
+	reaper            *reaper.Reaper
 }
 
 func New(config Config) (*Service, error) {
@@ -85,17 +104,97 @@ func New(config Config) (*Service, error) {
 		}
 	}
 
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	// Every shard elects its own leader, so that replicas of different shards
+	// do not compete for the same Lease.
+	shardCount := config.Viper.GetInt(config.Flag.Service.CRD.Shard.Count)
+	shardIndex := config.Viper.GetInt(config.Flag.Service.CRD.Shard.Index)
+	leaseName := config.Viper.GetString(config.Flag.Service.LeaderElection.LeaseName)
+	{
+		if config.Viper.GetBool(config.Flag.Service.CRD.Shard.IndexFromHostname) {
+			shardIndex, err = shard.IndexFromHostname(hostname)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+		}
+
+		if shardCount > 1 {
+			leaseName = fmt.Sprintf("%s-shard-%d", leaseName, shardIndex)
+		}
+	}
+
+	// storageService is the operator wide etcd store shared by the v3 resource
+	// set, the reaper and the etcd health check.
+	var storageService etcd.Store
+	{
+		c := etcd.ClientConfig{
+			Endpoints: config.Viper.GetStringSlice(config.Flag.Service.Etcd.Endpoints),
+
+			CAFile:  config.Viper.GetString(config.Flag.Service.Etcd.TLS.CAFile),
+			CrtFile: config.Viper.GetString(config.Flag.Service.Etcd.TLS.CrtFile),
+			KeyFile: config.Viper.GetString(config.Flag.Service.Etcd.TLS.KeyFile),
+		}
+
+		storageService, err = etcd.NewStore(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var networkController *controller.Network
 	{
 		c := controller.NetworkConfig{
 			K8sClient: k8sClient,
 			Logger:    config.Logger,
+			Store:     storageService,
 
 			CAFile:           config.Viper.GetString(config.Flag.Service.Etcd.TLS.CAFile),
 			CrtFile:          config.Viper.GetString(config.Flag.Service.Etcd.TLS.CrtFile),
 			CRDLabelSelector: config.Viper.GetString(config.Flag.Service.CRD.LabelSelector),
+			CRDShardCount:    shardCount,
+			CRDShardIndex:    shardIndex,
 			EtcdEndpoints:    config.Viper.GetStringSlice(config.Flag.Service.Etcd.Endpoints),
 			KeyFile:          config.Viper.GetString(config.Flag.Service.Etcd.TLS.KeyFile),
+
+			FlanneldIfaceRegex:             config.Viper.GetString(config.Flag.Service.Flanneld.IfaceRegex),
+			FlanneldIPMasq:                 config.Viper.GetBool(config.Flag.Service.Flanneld.IPMasq),
+			FlanneldPublicIP:               config.Viper.GetString(config.Flag.Service.Flanneld.PublicIP),
+			FlanneldSubnetLeaseRenewMargin: config.Viper.GetInt(config.Flag.Service.Flanneld.SubnetLeaseRenewMargin),
+			FlanneldVerbosity:              config.Viper.GetInt(config.Flag.Service.Flanneld.Verbosity),
+
+			HealthPortMax:      config.Viper.GetInt(config.Flag.Service.Flanneld.HealthPort.Max),
+			HealthPortMin:      config.Viper.GetInt(config.Flag.Service.Flanneld.HealthPort.Min),
+			HealthPortReserved: config.Viper.GetIntSlice(config.Flag.Service.Flanneld.HealthPort.Reserved),
+
+			LivenessProbeFailureThreshold:    config.Viper.GetInt32(config.Flag.Service.Flanneld.LivenessProbe.FailureThreshold),
+			LivenessProbeInitialDelaySeconds: config.Viper.GetInt32(config.Flag.Service.Flanneld.LivenessProbe.InitialDelaySeconds),
+			LivenessProbePeriodSeconds:       config.Viper.GetInt32(config.Flag.Service.Flanneld.LivenessProbe.PeriodSeconds),
+			LivenessProbeSuccessThreshold:    config.Viper.GetInt32(config.Flag.Service.Flanneld.LivenessProbe.SuccessThreshold),
+			LivenessProbeTimeoutSeconds:      config.Viper.GetInt32(config.Flag.Service.Flanneld.LivenessProbe.TimeoutSeconds),
+
+			ReadinessProbeFailureThreshold:    config.Viper.GetInt32(config.Flag.Service.Flanneld.ReadinessProbe.FailureThreshold),
+			ReadinessProbeInitialDelaySeconds: config.Viper.GetInt32(config.Flag.Service.Flanneld.ReadinessProbe.InitialDelaySeconds),
+			ReadinessProbePeriodSeconds:       config.Viper.GetInt32(config.Flag.Service.Flanneld.ReadinessProbe.PeriodSeconds),
+			ReadinessProbeSuccessThreshold:    config.Viper.GetInt32(config.Flag.Service.Flanneld.ReadinessProbe.SuccessThreshold),
+			ReadinessProbeTimeoutSeconds:      config.Viper.GetInt32(config.Flag.Service.Flanneld.ReadinessProbe.TimeoutSeconds),
+
+			MonitoringEnabled: config.Viper.GetBool(config.Flag.Service.Monitoring.Enabled),
+
+			NetworkNamespaceLimitRangeDefaultCPU:           config.Viper.GetString(config.Flag.Service.NetworkNamespace.LimitRange.DefaultCPU),
+			NetworkNamespaceLimitRangeDefaultMemory:        config.Viper.GetString(config.Flag.Service.NetworkNamespace.LimitRange.DefaultMemory),
+			NetworkNamespaceLimitRangeDefaultRequestCPU:    config.Viper.GetString(config.Flag.Service.NetworkNamespace.LimitRange.DefaultRequestCPU),
+			NetworkNamespaceLimitRangeDefaultRequestMemory: config.Viper.GetString(config.Flag.Service.NetworkNamespace.LimitRange.DefaultRequestMemory),
+			NetworkNamespaceNetworkPolicyEnabled:           config.Viper.GetBool(config.Flag.Service.NetworkNamespace.NetworkPolicy.Enabled),
+			NetworkNamespaceResourceQuotaCPU:               config.Viper.GetString(config.Flag.Service.NetworkNamespace.ResourceQuota.CPU),
+			NetworkNamespaceResourceQuotaMemory:            config.Viper.GetString(config.Flag.Service.NetworkNamespace.ResourceQuota.Memory),
+			NetworkNamespaceResourceQuotaPods:              config.Viper.GetString(config.Flag.Service.NetworkNamespace.ResourceQuota.Pods),
+
+			WorkloadLabelSelector: config.Viper.GetString(config.Flag.Service.Workload.LabelSelector),
+			WorkloadMaxWait:       config.Viper.GetDuration(config.Flag.Service.Workload.MaxWait),
 		}
 
 		networkController, err = controller.NewNetwork(c)
@@ -104,6 +203,150 @@ func New(config Config) (*Service, error) {
 		}
 	}
 
+	var orphanReaper *reaper.Reaper
+	{
+		c := reaper.Config{
+			G8sClient: k8sClient.G8sClient(),
+			K8sClient: k8sClient.K8sClient(),
+			Logger:    config.Logger,
+			Store:     storageService,
+
+			CleanupEnabled: config.Viper.GetBool(config.Flag.Service.Reaper.Cleanup.Enabled),
+			GraceAge:       config.Viper.GetDuration(config.Flag.Service.Reaper.Cleanup.GraceAge),
+			Interval:       config.Viper.GetDuration(config.Flag.Service.Reaper.Interval),
+		}
+
+		orphanReaper, err = reaper.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var conversionWebhook *conversion.Webhook
+	{
+		c := conversion.Config{
+			Logger: config.Logger,
+
+			Enabled:       config.Viper.GetBool(config.Flag.Service.Conversion.Enabled),
+			CrtFile:       config.Viper.GetString(config.Flag.Service.Conversion.TLS.CrtFile),
+			KeyFile:       config.Viper.GetString(config.Flag.Service.Conversion.TLS.KeyFile),
+			ListenAddress: config.Viper.GetString(config.Flag.Service.Conversion.ListenAddress),
+		}
+
+		conversionWebhook, err = conversion.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var leaderElector *leaderelection.Elector
+	{
+		c := leaderelection.Config{
+			K8sClient: k8sClient.K8sClient(),
+			Logger:    config.Logger,
+
+			Enabled:       config.Viper.GetBool(config.Flag.Service.LeaderElection.Enabled),
+			Identity:      hostname,
+			LeaseDuration: config.Viper.GetDuration(config.Flag.Service.LeaderElection.LeaseDuration),
+			LeaseName:     leaseName,
+			Namespace:     config.Viper.GetString(config.Flag.Service.LeaderElection.Namespace),
+			RenewDeadline: config.Viper.GetDuration(config.Flag.Service.LeaderElection.RenewDeadline),
+			RetryPeriod:   config.Viper.GetDuration(config.Flag.Service.LeaderElection.RetryPeriod),
+		}
+
+		leaderElector, err = leaderelection.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var livenessChecks []healthzservice.Service
+	{
+		c := healthzservice.Config{
+			Logger: config.Logger,
+		}
+
+		processCheck, err := healthzservice.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		livenessChecks = append(livenessChecks, processCheck, leaderElector)
+	}
+
+	var readinessChecks []healthzservice.Service
+	{
+		c := healthcheck.EtcdConfig{
+			Store: storageService,
+		}
+
+		etcdCheck, err := healthcheck.NewEtcd(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		readinessChecks = append(readinessChecks, etcdCheck)
+	}
+
+	{
+		c := healthcheck.KubernetesConfig{
+			K8sClient: k8sClient.K8sClient(),
+		}
+
+		kubernetesCheck, err := healthcheck.NewKubernetes(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		readinessChecks = append(readinessChecks, kubernetesCheck)
+	}
+
+	{
+		c := healthcheck.InformerConfig{
+			Leader: leaderElector,
+			Syncer: networkController,
+		}
+
+		informerCheck, err := healthcheck.NewInformer(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		readinessChecks = append(readinessChecks, informerCheck)
+	}
+
+	// The certificate check is skipped when neither etcd nor the conversion
+	// webhook are configured to use TLS.
+	var certificateFiles []string
+	{
+		files := []string{
+			config.Viper.GetString(config.Flag.Service.Etcd.TLS.CAFile),
+			config.Viper.GetString(config.Flag.Service.Etcd.TLS.CrtFile),
+		}
+		if config.Viper.GetBool(config.Flag.Service.Conversion.Enabled) {
+			files = append(files, config.Viper.GetString(config.Flag.Service.Conversion.TLS.CrtFile))
+		}
+
+		for _, f := range files {
+			if f != "" {
+				certificateFiles = append(certificateFiles, f)
+			}
+		}
+	}
+
+	if len(certificateFiles) != 0 {
+		c := healthcheck.CertificateConfig{
+			Files: certificateFiles,
+		}
+
+		certificateCheck, err := healthcheck.NewCertificate(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		readinessChecks = append(readinessChecks, certificateCheck)
+	}
+
 	var versionService *version.Service
 	{
 		c := version.Config{
@@ -122,10 +365,16 @@ func New(config Config) (*Service, error) {
 	}
 
 	s := &Service{
-		Version: versionService,
+		LeaderElector: leaderElector,
+		Version:       versionService,
+
+		LivenessChecks:  livenessChecks,
+		ReadinessChecks: readinessChecks,
 
 		bootOnce:          sync.Once{},
+		conversionWebhook: conversionWebhook,
 		networkController: networkController,
+		reaper:            orphanReaper,
 	}
 
 	return s, nil
@@ -133,6 +382,15 @@ func New(config Config) (*Service, error) {
 
 func (s *Service) Boot() {
 	s.bootOnce.Do(func() {
-		go s.networkController.Boot(context.Background())
+		// The Kubernetes API may call the conversion webhook of any replica,
+		// which is why it is served regardless of the leader election.
+		go s.conversionWebhook.Boot(context.Background())
+
+		// Only the leader reconciles tenant clusters and reaps orphans.
+		// Standby replicas wait here until they acquire the leader lease.
+		go s.LeaderElector.Boot(context.Background(), func(ctx context.Context) {
+			go s.networkController.Boot(ctx)
+			go s.reaper.Boot(ctx)
+		})
 	})
 }
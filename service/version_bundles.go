@@ -4,12 +4,14 @@ import (
 	"github.com/giantswarm/versionbundle"
 
 	v3 "github.com/giantswarm/flannel-operator/service/controller/v3"
+	v4 "github.com/giantswarm/flannel-operator/service/controller/v4"
 )
 
 func NewVersionBundles() []versionbundle.Bundle {
 	var versionBundles []versionbundle.Bundle
 
 	versionBundles = append(versionBundles, v3.VersionBundle())
+	versionBundles = append(versionBundles, v4.VersionBundle())
 
 	return versionBundles
 }